@@ -3,6 +3,8 @@ package client
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
@@ -11,6 +13,7 @@ import (
 	"time"
 
 	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -46,6 +49,101 @@ func (laptopClient *LaptopClient) CreateLaptop(laptop *pb.Laptop) {
 	log.Printf("Created laptop with id: %s", res.Id)
 }
 
+// BatchCreateLaptops creates laptops with a single stream, all or none of them if transactional
+func (laptopClient *LaptopClient) BatchCreateLaptops(laptops []*pb.Laptop, transactional bool) *pb.BatchCreateLaptopsResponse {
+	stream, err := laptopClient.service.BatchCreateLaptops(context.Background())
+	if err != nil {
+		log.Fatal("cannot batch create laptops: ", err)
+	}
+
+	for _, laptop := range laptops {
+		req := &pb.BatchCreateLaptopsRequest{
+			Laptop:        laptop,
+			Transactional: transactional,
+		}
+
+		err := stream.Send(req)
+		if err != nil {
+			log.Fatal("cannot send laptop: ", err, stream.RecvMsg(nil))
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		log.Fatal("cannot receive response: ", err)
+	}
+
+	log.Printf("created %d of %d laptops", res.GetCreatedCount(), len(laptops))
+	return res
+}
+
+func (laptopClient *LaptopClient) GetLaptop(laptopID string) *pb.Laptop {
+	req := &pb.GetLaptopRequest{
+		Id: laptopID,
+	}
+
+	res, err := laptopClient.service.GetLaptop(context.Background(), req)
+	if err != nil {
+		st, ok := status.FromError(err)
+		if ok && st.Code() == codes.NotFound {
+			log.Print("Laptop not found")
+		} else {
+			log.Fatal("can not get laptop", err)
+		}
+
+		return nil
+	}
+
+	log.Printf("Got laptop with id: %s", res.GetLaptop().GetId())
+	return res.GetLaptop()
+}
+
+func (laptopClient *LaptopClient) UpdateLaptop(laptop *pb.Laptop, paths ...string) {
+	req := &pb.UpdateLaptopRequest{
+		Laptop:     laptop,
+		UpdateMask: &field_mask.FieldMask{Paths: paths},
+	}
+
+	res, err := laptopClient.service.UpdateLaptop(context.Background(), req)
+	if err != nil {
+		st, ok := status.FromError(err)
+		if ok && st.Code() == codes.NotFound {
+			log.Print("Laptop not found")
+		} else if ok && st.Code() == codes.Aborted {
+			log.Print("Laptop was modified by someone else")
+		} else {
+			log.Fatal("can not update laptop", err)
+		}
+
+		return
+	}
+
+	log.Printf("Updated laptop with id: %s", res.GetLaptop().GetId())
+}
+
+func (laptopClient *LaptopClient) DeleteLaptop(laptopID string, version uint64) {
+	req := &pb.DeleteLaptopRequest{
+		Id:      laptopID,
+		Version: version,
+	}
+
+	_, err := laptopClient.service.DeleteLaptop(context.Background(), req)
+	if err != nil {
+		st, ok := status.FromError(err)
+		if ok && st.Code() == codes.NotFound {
+			log.Print("Laptop not found")
+		} else if ok && st.Code() == codes.Aborted {
+			log.Print("Laptop was modified by someone else")
+		} else {
+			log.Fatal("can not delete laptop", err)
+		}
+
+		return
+	}
+
+	log.Printf("Deleted laptop with id: %s", laptopID)
+}
+
 func (laptopClient *LaptopClient) SearchLaptop(filter *pb.Filter) {
 	log.Print("search filter: ", filter)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -76,6 +174,20 @@ func (laptopClient *LaptopClient) SearchLaptop(filter *pb.Filter) {
 	}
 }
 
+func (laptopClient *LaptopClient) AggregateLaptops(filter *pb.Filter) *pb.AggregateLaptopsResponse {
+	req := &pb.AggregateLaptopsRequest{
+		Filter: filter,
+	}
+
+	res, err := laptopClient.service.AggregateLaptops(context.Background(), req)
+	if err != nil {
+		log.Fatal("cannot aggregate laptops: ", err)
+	}
+
+	log.Printf("aggregated %d laptops, brands: %v", res.GetTotal(), res.GetBrands())
+	return res
+}
+
 func (laptopClient *LaptopClient) RateLaptop(laptopIds []string, scores []float64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -129,6 +241,10 @@ func (laptopClient *LaptopClient) RateLaptop(laptopIds []string, scores []float6
 	return err
 }
 
+// maxUploadAttempts is the number of streams an image is sent over before giving up
+const maxUploadAttempts = 3
+
+// UploadImage uploads an image, resuming the upload from its committed offset when the stream breaks
 func (laptopClient *LaptopClient) UploadImage(laptopID, imagePath string) {
 	file, err := os.Open(imagePath)
 	if err != nil {
@@ -136,26 +252,79 @@ func (laptopClient *LaptopClient) UploadImage(laptopID, imagePath string) {
 	}
 	defer file.Close()
 
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		log.Fatal("cannot read image file", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.CreateImageUploadRequest{
+		Info: &pb.ImageInfo{
+			LaptopId:  laptopID,
+			ImageType: filepath.Ext(imagePath),
+			Size:      uint32(size),
+			Sha256:    hex.EncodeToString(hash.Sum(nil)),
+		},
+	}
+
+	createRes, err := laptopClient.service.CreateImageUpload(ctx, req)
+	if err != nil {
+		log.Fatal("cannot create image upload: ", err)
+	}
+	upload := createRes.GetUpload()
+
+	for attempt := 1; ; attempt++ {
+		res, err := laptopClient.uploadImageFrom(upload, file)
+		if err == nil {
+			log.Printf("image upload with id %s size %d", res.GetId(), res.GetSize())
+			return
+		}
+
+		code := status.Code(err)
+		if attempt == maxUploadAttempts || (code != codes.Unavailable && code != codes.DeadlineExceeded) {
+			log.Fatal("cannot upload image: ", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		getRes, err := laptopClient.service.GetImageUpload(ctx, &pb.GetImageUploadRequest{UploadId: upload.GetId()})
+		cancel()
+		if err != nil {
+			log.Fatal("cannot get image upload: ", err)
+		}
+
+		upload = getRes.GetUpload()
+		log.Printf("resume image upload %s at offset %d", upload.GetId(), upload.GetOffset())
+	}
+}
+
+// uploadImageFrom sends the image data from the committed offset of the upload
+func (laptopClient *LaptopClient) uploadImageFrom(upload *pb.ImageUpload, file *os.File) (*pb.UploadImageResponse, error) {
+	offset := upload.GetOffset()
+	_, err := file.Seek(int64(offset), io.SeekStart)
+	if err != nil {
+		log.Fatal("cannot seek image file", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	stream, err := laptopClient.service.UploadImage(ctx)
 	if err != nil {
-		log.Fatal("cannot upload image", err)
+		return nil, err
 	}
 
 	req := &pb.UploadImageRequest{
 		Data: &pb.UploadImageRequest_Info{
-			Info: &pb.ImageInfo{
-				LaptopId:  laptopID,
-				ImageType: filepath.Ext(imagePath),
-			},
+			Info: &pb.ImageInfo{UploadId: upload.GetId()},
 		},
 	}
 
 	err = stream.Send(req)
 	if err != nil {
-		log.Fatal("cannot send image info", err)
+		return nil, stream.RecvMsg(nil)
 	}
 
 	render := bufio.NewReader(file)
@@ -170,20 +339,78 @@ func (laptopClient *LaptopClient) UploadImage(laptopID, imagePath string) {
 		}
 
 		req := &pb.UploadImageRequest{
-			Data: &pb.UploadImageRequest_ChunkData{ChunkData: buffer[:n]},
+			Data: &pb.UploadImageRequest_Chunk{
+				Chunk: &pb.ImageChunk{Offset: offset, Data: buffer[:n]},
+			},
 		}
 
 		err = stream.Send(req)
 		if err != nil {
-			err2 := stream.RecvMsg(nil)
-			log.Fatal("cannot send to server", err, err2)
+			return nil, stream.RecvMsg(nil)
 		}
+		offset += uint32(n)
 	}
 
-	res, err := stream.CloseAndRecv()
+	return stream.CloseAndRecv()
+}
+
+// ListImages returns the images of a laptop
+func (laptopClient *LaptopClient) ListImages(laptopID string) []*pb.Image {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := laptopClient.service.ListImages(ctx, &pb.ListImagesRequest{LaptopId: laptopID})
+	if err != nil {
+		log.Fatal("cannot list images: ", err)
+	}
+
+	return res.GetImages()
+}
+
+// DownloadImage saves an image to a file of the folder named by the image ID and type
+func (laptopClient *LaptopClient) DownloadImage(imageID, folder string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := laptopClient.service.DownloadImage(ctx, &pb.DownloadImageRequest{ImageId: imageID})
+	if err != nil {
+		log.Fatal("cannot download image: ", err)
+	}
+
+	res, err := stream.Recv()
+	if err != nil {
+		log.Fatal("cannot receive image: ", err)
+	}
+
+	image := res.GetImage()
+	imagePath := filepath.Join(folder, image.GetId()+image.GetImageType())
+	file, err := os.Create(imagePath)
+	if err != nil {
+		log.Fatal("cannot create image file: ", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal("cannot receive chunk data: ", err)
+		}
+
+		_, err = writer.Write(res.GetChunkData())
+		if err != nil {
+			log.Fatal("cannot write image file: ", err)
+		}
+	}
+
+	err = writer.Flush()
 	if err != nil {
-		log.Fatal("cannot receive response", err)
+		log.Fatal("cannot write image file: ", err)
 	}
 
-	log.Printf("image upload with id %s size %d", res.GetId(), res.GetSize())
+	log.Printf("image %s downloaded to %s", imageID, imagePath)
+	return imagePath
 }
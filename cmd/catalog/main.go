@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/protodiff"
+	"gitlab.techschool.pcbook/serializer"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/protobuf/proto"
+)
+
+const usage = `usage: catalog [flags] export|import|diff FILE
+       catalog [flags] patch ID PATCH
+
+export writes the laptops of a store to FILE and import reads them from FILE.
+diff prints how the laptops of FILE differ from the ones of the store, as text
+or as JSON Patch documents with -json-patch. patch applies the JSON Patch
+document of the file PATCH to the laptop ID of the store.
+
+The format of FILE is guessed from its extension unless -format is set:
+.ndjson/.jsonl, .pb/.bin or .csv. FILE - is the standard input or output.
+
+`
+
+// openLaptopStore opens the laptop store of a server database and returns the function closing it
+func openLaptopStore(storeType, dbPath string) (service.LaptopStore, func() error, error) {
+	switch storeType {
+	case "bolt":
+		laptopStore, err := service.NewBoltLaptopStore(dbPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return laptopStore, laptopStore.Close, nil
+	case "sql":
+		db, err := service.OpenSQLDatabase(dbPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return service.NewSQLLaptopStore(db), db.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown store type %s", storeType)
+	}
+}
+
+func exportLaptops(store service.LaptopStore, format, filename string) error {
+	var file io.Writer = os.Stdout
+	if filename != "-" {
+		f, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("cannot create file: %w", err)
+		}
+		defer f.Close()
+		file = f
+	}
+
+	writer, err := serializer.NewMessageWriter(format, file)
+	if err != nil {
+		return err
+	}
+
+	count, err := service.ExportLaptops(context.Background(), store, writer)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("exported %d laptops", count)
+	return nil
+}
+
+// importLaptops prints the laptops that were not imported with their line, and fails if there are any
+func importLaptops(store service.LaptopStore, format, filename string, options service.ImportOptions) error {
+	var file io.Reader = os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("cannot open file: %w", err)
+		}
+		defer f.Close()
+		file = f
+	}
+
+	reader, err := serializer.NewMessageReader(format, file)
+	if err != nil {
+		return err
+	}
+
+	result, err := service.ImportLaptops(store, reader, options)
+	if err != nil {
+		return err
+	}
+
+	for _, importErr := range result.Errors {
+		fmt.Fprintf(os.Stderr, "%s:%d: %v\n", filename, importErr.Line, importErr.Err)
+	}
+
+	verb := "imported"
+	if options.DryRun {
+		verb = "would import"
+	}
+	log.Printf("%s laptops created: %d updated: %d errors: %d", verb, result.Created, result.Updated, len(result.Errors))
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d laptops were not imported", len(result.Errors))
+	}
+	return nil
+}
+
+// laptopPatch is a line of the JSON output of diffLaptops
+type laptopPatch struct {
+	ID    string          `json:"id"`
+	New   bool            `json:"new,omitempty"`
+	Patch json.RawMessage `json:"patch,omitempty"`
+}
+
+// diffLaptops prints the changes of each laptop of the file that is new or differs from the store
+func diffLaptops(store service.LaptopStore, format, filename string, jsonPatch bool) error {
+	var file io.Reader = os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("cannot open file: %w", err)
+		}
+		defer f.Close()
+		file = f
+	}
+
+	reader, err := serializer.NewMessageReader(format, file)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		laptop := &pb.Laptop{}
+		err := reader.Read(laptop)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		found, err := store.Find(laptop.GetId())
+		if err != nil {
+			return fmt.Errorf("cannot find laptop %s: %w", laptop.GetId(), err)
+		}
+
+		if found == nil {
+			if jsonPatch {
+				err = encoder.Encode(laptopPatch{ID: laptop.GetId(), New: true})
+			} else {
+				_, err = fmt.Printf("laptop %s on line %d is new\n", laptop.GetId(), reader.Line())
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// the version belongs to the store, not to the vendor
+		laptop.Version = found.GetVersion()
+		diff, err := protodiff.Compare(found, laptop)
+		if err != nil {
+			return err
+		}
+		if len(diff) == 0 {
+			continue
+		}
+
+		if jsonPatch {
+			patch, err := diff.JSONPatch()
+			if err != nil {
+				return err
+			}
+			err = encoder.Encode(laptopPatch{ID: laptop.GetId(), Patch: patch})
+		} else {
+			_, err = fmt.Printf("laptop %s:\n%s", laptop.GetId(), diff)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// patchLaptop applies a JSON Patch to a laptop of the store and prints the changes
+func patchLaptop(store service.LaptopStore, id, filename string, dryRun bool) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("cannot read patch: %w", err)
+	}
+
+	patch, err := protodiff.ParseJSONPatch(data)
+	if err != nil {
+		return err
+	}
+
+	found, err := store.Find(id)
+	if err != nil {
+		return fmt.Errorf("cannot find laptop %s: %w", id, err)
+	}
+	if found == nil {
+		return fmt.Errorf("laptop %s doesnt exist", id)
+	}
+
+	laptop := proto.Clone(found).(*pb.Laptop)
+	err = protodiff.Apply(laptop, patch)
+	if err != nil {
+		return err
+	}
+	if laptop.GetId() != id {
+		return errors.New("laptop ID cannot be patched")
+	}
+
+	diff, err := protodiff.Compare(found, laptop)
+	if err != nil {
+		return err
+	}
+	fmt.Print(diff)
+
+	if dryRun {
+		return nil
+	}
+
+	laptop.Version = found.GetVersion()
+	return store.Update(laptop)
+}
+
+func main() {
+	storeType := flag.String("store", "bolt", "type of store bolt/sql")
+	dbPath := flag.String("db", "pcbook.db", "database file of the store")
+	format := flag.String("format", "", "format of the file ndjson/protobuf/csv")
+	dryRun := flag.Bool("dry-run", false, "validate the imported or patched laptops without saving them")
+	upsert := flag.Bool("upsert", false, "replace the imported laptops that already exist")
+	jsonPatch := flag.Bool("json-patch", false, "print the differences as JSON Patch documents")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := flag.Arg(0)
+	argCount := 2
+	if command == "patch" {
+		argCount = 3
+	}
+	if flag.NArg() != argCount {
+		flag.Usage()
+		os.Exit(2)
+	}
+	filename := flag.Arg(1)
+
+	if *format == "" && command != "patch" {
+		var err error
+		*format, err = serializer.FormatFromFilename(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	store, closeStore, err := openLaptopStore(*storeType, *dbPath)
+	if err != nil {
+		log.Fatal("cannot open store: ", err)
+	}
+	defer closeStore()
+
+	switch command {
+	case "export":
+		err = exportLaptops(store, *format, filename)
+	case "import":
+		err = importLaptops(store, *format, filename, service.ImportOptions{DryRun: *dryRun, Upsert: *upsert})
+	case "diff":
+		err = diffLaptops(store, *format, filename, *jsonPatch)
+	case "patch":
+		err = patchLaptop(store, flag.Arg(1), flag.Arg(2), *dryRun)
+	default:
+		flag.Usage()
+		closeStore()
+		os.Exit(2)
+	}
+	if err != nil {
+		closeStore()
+		log.Fatal(err)
+	}
+}
@@ -249,9 +249,14 @@ const (
 func authMethods() map[string]bool {
 	const laptopServicePath = "/techschool.pcbook.LaptopService/"
 	return map[string]bool{
-		laptopServicePath + "CreateLaptop": true,
-		laptopServicePath + "UploadImage":  true,
-		laptopServicePath + "RateLaptop":   true,
+		laptopServicePath + "CreateLaptop":      true,
+		laptopServicePath + "UpdateLaptop":      true,
+		laptopServicePath + "DeleteLaptop":      true,
+		laptopServicePath + "UploadImage":       true,
+		laptopServicePath + "CreateImageUpload": true,
+		laptopServicePath + "GetImageUpload":    true,
+		laptopServicePath + "DeleteImage":       true,
+		laptopServicePath + "RateLaptop":        true,
 	}
 }
 
@@ -304,7 +309,7 @@ func main() {
 	authClient := client.NewAuthClient(cc1, username, password)
 	interceptor, err := client.NewAuthInterceptor(authClient, authMethods(), refreshDuration)
 	if err != nil {
-		log.Fatal("cannot create interceptor %v", err)
+		log.Fatalf("cannot create interceptor %v", err)
 	}
 
 	cc2, err := grpc.Dial(
@@ -4,20 +4,29 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/s3"
 	"gitlab.techschool.pcbook/service"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -25,6 +34,15 @@ const (
 	tokenDuration = 15 * time.Minute
 	serverCert    = "cert/server-cert.pem"
 	serverKey     = "cert/server-key.pem"
+	// number of laptop events a reconnecting watcher can resume from
+	laptopEventHistory = 1000
+	// a webhook delivery is attempted webhookAttempts times, waiting webhookBackoff and then twice as long each time
+	webhookAttempts = 6
+	webhookBackoff  = 2 * time.Second
+	webhookTimeout  = 10 * time.Second
+	// an image upload without any chunk for uploadTTL is deleted by the sweep run every uploadSweepInterval
+	uploadTTL           = 24 * time.Hour
+	uploadSweepInterval = time.Hour
 )
 
 func loadTLDCredentials() (credentials.TransportCredentials, error) {
@@ -61,12 +79,45 @@ func seedUsers(userStore service.UserStore) error {
 	return createUser(userStore, "user1", "secret", "user")
 }
 
+// imageQuotas are the limits of the images uploaded by the users of every role,
+// only admins can upload images
+func imageQuotas(adminQuota service.ImageQuota) map[string]service.ImageQuota {
+	return map[string]service.ImageQuota{
+		"admin": adminQuota,
+	}
+}
+
 func accessibleRoles() map[string][]string {
 	const laptopServicePath = "/techschool.pcbook.LaptopService/"
+	const savedSearchServicePath = "/techschool.pcbook.SavedSearchService/"
+	const webhookServicePath = "/techschool.pcbook.WebhookService/"
+	const catalogServicePath = "/techschool.pcbook.CatalogService/"
 	return map[string][]string{
-		laptopServicePath + "CreateLaptop": {"admin"},
-		laptopServicePath + "UploadImage":  {"admin"},
-		laptopServicePath + "RateLaptop":   {"admin", "user"},
+		laptopServicePath + "CreateLaptop":       {"admin"},
+		laptopServicePath + "BatchCreateLaptops": {"admin"},
+		laptopServicePath + "UpdateLaptop":       {"admin"},
+		laptopServicePath + "DeleteLaptop":       {"admin"},
+		laptopServicePath + "UploadImage":        {"admin"},
+		laptopServicePath + "CreateImageUpload":  {"admin"},
+		laptopServicePath + "GetImageUpload":     {"admin"},
+		laptopServicePath + "DeleteImage":        {"admin"},
+		laptopServicePath + "RateLaptop":         {"admin", "user"},
+
+		savedSearchServicePath + "CreateSavedSearch":      {"admin", "user"},
+		savedSearchServicePath + "GetSavedSearch":         {"admin", "user"},
+		savedSearchServicePath + "ListSavedSearches":      {"admin", "user"},
+		savedSearchServicePath + "UpdateSavedSearch":      {"admin", "user"},
+		savedSearchServicePath + "DeleteSavedSearch":      {"admin", "user"},
+		savedSearchServicePath + "SubscribeSavedSearches": {"admin", "user"},
+
+		webhookServicePath + "CreateWebhook":     {"admin"},
+		webhookServicePath + "ListWebhooks":      {"admin"},
+		webhookServicePath + "DeleteWebhook":     {"admin"},
+		webhookServicePath + "ListDeadLetters":   {"admin"},
+		webhookServicePath + "ReplayDeadLetters": {"admin"},
+
+		catalogServicePath + "ExportLaptops": {"admin"},
+		catalogServicePath + "ImportLaptops": {"admin"},
 	}
 }
 
@@ -76,12 +127,21 @@ func createUser(userStore service.UserStore, username, password, role string) er
 		return err
 	}
 
-	return userStore.Save(user)
+	err = userStore.Save(user)
+	if errors.Is(err, service.ErrAlreadyExists) {
+		// seeded by a previous run of a persistent store
+		return nil
+	}
+
+	return err
 }
 
 func runGRPCServer(
 	authServer pb.AuthServiceServer,
 	laptopServer pb.LaptopServiceServer,
+	savedSearchServer pb.SavedSearchServiceServer,
+	webhookServer pb.WebhookServiceServer,
+	catalogServer pb.CatalogServiceServer,
 	jwtManager *service.JWTManager,
 	enableTLS bool,
 	listener net.Listener,
@@ -106,21 +166,36 @@ func runGRPCServer(
 
 	pb.RegisterAuthServiceServer(grpcServer, authServer)
 	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
+	pb.RegisterSavedSearchServiceServer(grpcServer, savedSearchServer)
+	pb.RegisterWebhookServiceServer(grpcServer, webhookServer)
+	pb.RegisterCatalogServiceServer(grpcServer, catalogServer)
 	reflection.Register(grpcServer)
 
 	return grpcServer.Serve(listener)
 
 }
 
+// incomingHeaderMatcher forwards the If-Match header under the same metadata key gRPC clients use
+func incomingHeaderMatcher(key string) (string, bool) {
+	if textproto.CanonicalMIMEHeaderKey(key) == "If-Match" {
+		return service.IfMatchKey, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
 func runRESETServer(
 	authServer pb.AuthServiceServer,
 	laptopServer pb.LaptopServiceServer,
+	savedSearchServer pb.SavedSearchServiceServer,
+	webhookServer pb.WebhookServiceServer,
+	catalogServer pb.CatalogServiceServer,
 	jwtManager *service.JWTManager,
 	enableTLS bool,
 	listener net.Listener,
 	grpcEndpoint string,
 ) error {
-	mux := runtime.NewServeMux()
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher))
 	dialOptions := []grpc.DialOption{
 		grpc.WithInsecure(),
 	}
@@ -138,32 +213,273 @@ func runRESETServer(
 		return err
 	}
 
+	err = pb.RegisterSavedSearchServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, dialOptions)
+	if err != nil {
+		return err
+	}
+
+	err = pb.RegisterWebhookServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, dialOptions)
+	if err != nil {
+		return err
+	}
+
+	err = pb.RegisterCatalogServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, dialOptions)
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.DialContext(ctx, grpcEndpoint, dialOptions...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = mux.HandlePath("GET", "/v1/image/{image_id}", downloadImageHandler(pb.NewLaptopServiceClient(conn)))
+	if err != nil {
+		return err
+	}
+
 	log.Printf("Starting REST server at %s TLS = %t", listener.Addr().String(), enableTLS)
 	if enableTLS {
 		return http.ServeTLS(listener, mux, serverCert, serverKey)
 	}
 	return http.Serve(listener, mux)
 }
+
+// downloadImageHandler serves the data of an image, or of the thumbnail in the thumbnail query parameter,
+// as it is with its content type instead of a stream of JSON chunks.
+// The Authorization header is forwarded like the gateway does for the other RPCs.
+func downloadImageHandler(laptopClient pb.LaptopServiceClient) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		if authorization := r.Header.Get("Authorization"); authorization != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", authorization)
+		}
+
+		req := &pb.DownloadImageRequest{
+			ImageId:   pathParams["image_id"],
+			Thumbnail: r.URL.Query().Get("thumbnail"),
+		}
+		stream, err := laptopClient.DownloadImage(ctx, req)
+		if err != nil {
+			http.Error(w, status.Convert(err).Message(), runtime.HTTPStatusFromCode(status.Code(err)))
+			return
+		}
+
+		res, err := stream.Recv()
+		if err != nil {
+			http.Error(w, status.Convert(err).Message(), runtime.HTTPStatusFromCode(status.Code(err)))
+			return
+		}
+
+		image := res.GetImage()
+		contentType, size := image.GetContentType(), image.GetSize()
+		for _, thumbnail := range image.GetThumbnails() {
+			if thumbnail.GetName() == req.GetThumbnail() {
+				contentType, size = thumbnail.GetContentType(), thumbnail.GetSize()
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(int(size)))
+
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// the status is already sent, the client sees a short body
+				log.Printf("cannot receive image %s: %v", image.GetId(), err)
+				return
+			}
+
+			_, err = w.Write(res.GetChunkData())
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+type stores struct {
+	laptopStore      service.LaptopStore
+	ratingStore      service.RatingStore
+	userStore        service.UserStore
+	savedSearchStore service.SavedSearchStore
+	webhookStore     service.WebhookStore
+}
+
+func newImageStore(imageStoreType, imageFolder string, s3Config s3.Config) (service.ImageStore, error) {
+	switch imageStoreType {
+	case "disk":
+		imageStore, err := service.NewDiskImageStore(imageFolder)
+		if err != nil {
+			return nil, err
+		}
+
+		// only a crash leaves unused blobs behind
+		removed, err := imageStore.CollectGarbage()
+		if err != nil {
+			return nil, fmt.Errorf("cannot remove unused images: %w", err)
+		}
+		log.Printf("removed %d unused image blobs", removed)
+
+		return imageStore, nil
+	case "s3":
+		client, err := s3.NewClient(s3Config)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.NewS3ImageStore(client), nil
+	default:
+		return nil, fmt.Errorf("unknown image store type %s", imageStoreType)
+	}
+}
+
+func deleteExpiredUploads(uploadStore *service.DiskImageUploadStore) error {
+	deleted, err := uploadStore.DeleteExpired(uploadTTL)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("deleted %d expired image uploads", deleted)
+	return nil
+}
+
+func newStores(storeType, dbPath string) (*stores, error) {
+	switch storeType {
+	case "memory":
+		return &stores{
+			laptopStore:      service.NewInMemoryLaptopStore(),
+			ratingStore:      service.NewInMemoryRatingStore(),
+			userStore:        service.NewInMemoryUserStore(),
+			savedSearchStore: service.NewInMemorySavedSearchStore(),
+			webhookStore:     service.NewInMemoryWebhookStore(),
+		}, nil
+	case "bolt":
+		laptopStore, err := service.NewBoltLaptopStore(dbPath)
+		if err != nil {
+			return nil, err
+		}
+
+		savedSearchStore, err := service.NewBoltSavedSearchStore(laptopStore.DB())
+		if err != nil {
+			return nil, err
+		}
+
+		webhookStore, err := service.NewBoltWebhookStore(laptopStore.DB())
+		if err != nil {
+			return nil, err
+		}
+
+		return &stores{
+			laptopStore:      laptopStore,
+			ratingStore:      service.NewInMemoryRatingStore(),
+			userStore:        service.NewInMemoryUserStore(),
+			savedSearchStore: savedSearchStore,
+			webhookStore:     webhookStore,
+		}, nil
+	case "sql":
+		db, err := service.OpenSQLDatabase(dbPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return &stores{
+			laptopStore:      service.NewSQLLaptopStore(db),
+			ratingStore:      service.NewSQLRatingStore(db),
+			userStore:        service.NewSQLUserStore(db),
+			savedSearchStore: service.NewSQLSavedSearchStore(db),
+			webhookStore:     service.NewSQLWebhookStore(db),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown store type %s", storeType)
+	}
+}
+
 func main() {
 	port := flag.Int("port", 0, "the server port")
 	enableTLS := flag.Bool("tls", false, "enable ssl/tls")
 	serverType := flag.String("type", "grpc", "type of server grpc/rest")
 	endpoint := flag.String("endpoint", "", "grpc endpoint")
+	storeType := flag.String("store", "memory", "type of store memory/bolt/sql")
+	dbPath := flag.String("db", "pcbook.db", "database file of the bolt or sql store")
+	imageStoreType := flag.String("image-store", "disk", "type of image store disk/s3")
+	imageFolder := flag.String("image-folder", "img", "folder of the disk image store and of the uploads")
+	s3Endpoint := flag.String("s3-endpoint", "https://s3.amazonaws.com", "endpoint of the s3 image store, the credentials are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	s3Region := flag.String("s3-region", "us-east-1", "region of the s3 image store")
+	s3Bucket := flag.String("s3-bucket", "", "bucket of the s3 image store")
+	maxImageSize := flag.Int("max-image-size", 8<<20, "largest size in bytes of an image uploaded by an admin, at most 32 MiB")
+	maxLaptopImagesSize := flag.Int("max-laptop-images-size", 64<<20, "largest total size in bytes of the images of a laptop uploaded by admins, 0 for no limit")
+	maxUploaderImagesSize := flag.Int("max-uploader-images-size", 1<<30, "largest total size in bytes of the images uploaded by an admin, 0 for no limit")
 	flag.Parse()
 	log.Printf("start server on port %d TLS = %t", *port, *enableTLS)
 
-	userStore := service.NewInMemoryUserStore()
-	err := seedUsers(userStore)
+	stores, err := newStores(*storeType, *dbPath)
+	if err != nil {
+		log.Fatal("cannot create stores: ", err)
+	}
+
+	err = seedUsers(stores.userStore)
 	if err != nil {
 		log.Fatal("cannot seed users")
 	}
 	jwtManager := service.NewJWTManager(secretKey, tokenDuration)
-	authServer := service.NewAuthServer(userStore, jwtManager)
+	authServer := service.NewAuthServer(stores.userStore, jwtManager)
 
-	laptopStore := service.NewInMemoryLaptopStore()
-	imageStore := service.NewDiskImageStore("img")
-	ratingStore := service.NewInMemoryRatingStore()
-	laptopServer := service.NewLaptopServer(laptopStore, imageStore, ratingStore)
+	webhooks := service.NewWebhookDispatcher(stores.webhookStore, &http.Client{Timeout: webhookTimeout}, webhookAttempts, webhookBackoff)
+	webhookServer := service.NewWebhookServer(stores.webhookStore, webhooks)
+
+	laptopEvents := service.NewLaptopEventBus(laptopEventHistory)
+	laptopStore, err := service.NewIndexedLaptopStore(
+		service.NewEventLaptopStore(service.NewWebhookLaptopStore(stores.laptopStore, webhooks), laptopEvents),
+	)
+	if err != nil {
+		log.Fatal("cannot index laptops: ", err)
+	}
+
+	s3Config := s3.Config{
+		Endpoint:  *s3Endpoint,
+		Region:    *s3Region,
+		Bucket:    *s3Bucket,
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	}
+	baseImageStore, err := newImageStore(*imageStoreType, *imageFolder, s3Config)
+	if err != nil {
+		log.Fatal("cannot open image store: ", err)
+	}
+
+	imageStore := service.NewWebhookImageStore(baseImageStore, webhooks)
+	uploadStore, err := service.NewDiskImageUploadStore(filepath.Join(*imageFolder, "uploads"))
+	if err != nil {
+		log.Fatal("cannot create upload store: ", err)
+	}
+	err = deleteExpiredUploads(uploadStore)
+	if err != nil {
+		log.Fatal("cannot delete expired uploads: ", err)
+	}
+	go func() {
+		for range time.Tick(uploadSweepInterval) {
+			err := deleteExpiredUploads(uploadStore)
+			if err != nil {
+				log.Print("cannot delete expired uploads: ", err)
+			}
+		}
+	}()
+
+	ratingStore := service.NewWebhookRatingStore(stores.ratingStore, webhooks)
+	adminQuota := service.ImageQuota{
+		MaxImageSize:    *maxImageSize,
+		MaxLaptopSize:   *maxLaptopImagesSize,
+		MaxUploaderSize: *maxUploaderImagesSize,
+	}
+	laptopServer := service.NewLaptopServer(laptopStore, imageStore, uploadStore, imageQuotas(adminQuota), ratingStore, laptopEvents)
+	savedSearchServer := service.NewSavedSearchServer(stores.savedSearchStore, laptopEvents)
+	catalogServer := service.NewCatalogServer(laptopStore)
 
 	address := fmt.Sprintf("localhost:%d", *port)
 	listener, err := net.Listen("tcp", address)
@@ -172,9 +488,9 @@ func main() {
 	}
 
 	if *serverType == "grpc" {
-		err = runGRPCServer(authServer, laptopServer, jwtManager, *enableTLS, listener)
+		err = runGRPCServer(authServer, laptopServer, savedSearchServer, webhookServer, catalogServer, jwtManager, *enableTLS, listener)
 	} else {
-		err = runRESETServer(authServer, laptopServer, jwtManager, *enableTLS, listener, *endpoint)
+		err = runRESETServer(authServer, laptopServer, savedSearchServer, webhookServer, catalogServer, jwtManager, *enableTLS, listener, *endpoint)
 	}
 	if err != nil {
 		log.Fatal("cannot start server ", err)
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/grpc"
+)
+
+func TestDownloadImageHandler(t *testing.T) {
+	t.Parallel()
+
+	imageFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(imageFolder) })
+
+	imageStore, err := service.NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+
+	imageData := []byte("not really a png")
+	writer, err := imageStore.Create("laptop1", ".png", "admin1")
+	require.NoError(t, err)
+	_, err = writer.Write(imageData)
+	require.NoError(t, err)
+	info, err := writer.Commit()
+	require.NoError(t, err)
+
+	// downloads are public on the real server, require a role to test the forwarded token
+	jwtManager := service.NewJWTManager(secretKey, time.Minute)
+	interceptor := service.NewAuthInterceptor(jwtManager, map[string][]string{
+		"/techschool.pcbook.LaptopService/DownloadImage": {"admin"},
+	})
+	grpcServer := grpc.NewServer(grpc.StreamInterceptor(interceptor.Stream()))
+	pb.RegisterLaptopServiceServer(grpcServer, service.NewLaptopServer(nil, imageStore, nil, nil, nil, nil))
+
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	mux := runtime.NewServeMux()
+	err = mux.HandlePath("GET", "/v1/image/{image_id}", downloadImageHandler(pb.NewLaptopServiceClient(conn)))
+	require.NoError(t, err)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	user, err := service.NewUser("admin1", "secret", "admin")
+	require.NoError(t, err)
+	token, err := jwtManager.Generate(user)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		imageID     string
+		token       string
+		code        int
+		contentType string
+		body        []byte
+	}{
+		{"ok", info.ID, token, http.StatusOK, "image/png", imageData},
+		{"not found", "unknown", token, http.StatusNotFound, "", nil},
+		{"no token", info.ID, "", http.StatusUnauthorized, "", nil},
+		{"invalid token", info.ID, "invalid", http.StatusUnauthorized, "", nil},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/image/"+tc.imageID, nil)
+		require.NoError(t, err)
+		if tc.token != "" {
+			req.Header.Set("Authorization", tc.token)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, tc.code, res.StatusCode, tc.name)
+		if tc.body != nil {
+			require.Equal(t, tc.contentType, res.Header.Get("Content-Type"), tc.name)
+			require.Equal(t, tc.body, body, tc.name)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package imaging checks the images uploaded for laptops and makes their thumbnails.
+// The format of an image is sniffed from its data, whatever its name says,
+// and an image is only decoded once its dimensions are known to be small enough.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format is the format of an image
+type Format string
+
+// formats of the images that can be uploaded
+const (
+	PNG  Format = "png"
+	JPEG Format = "jpeg"
+	GIF  Format = "gif"
+	WebP Format = "webp"
+)
+
+// ErrUnknownFormat is returned for data that is not an image in a supported format
+var ErrUnknownFormat = errors.New("unknown image format")
+
+// Sniff returns the format of an image from the signature at the start of its data
+func Sniff(data []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return PNG, nil
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return JPEG, nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return GIF, nil
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return WebP, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// FormatFromExtension returns the format of an image type like .jpg
+func FormatFromExtension(extension string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(extension, ".")) {
+	case "png":
+		return PNG, nil
+	case "jpg", "jpeg":
+		return JPEG, nil
+	case "gif":
+		return GIF, nil
+	case "webp":
+		return WebP, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, extension)
+	}
+}
+
+// Extension returns the image type of the format, used to name image files
+func (format Format) Extension() string {
+	if format == JPEG {
+		return ".jpg"
+	}
+
+	return "." + string(format)
+}
+
+// ContentType returns the MIME type of the format
+func (format Format) ContentType() string {
+	return "image/" + string(format)
+}
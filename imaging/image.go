@@ -0,0 +1,215 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the WebP decoder
+)
+
+// thumbnailQuality is the quality of the JPEG thumbnails
+const thumbnailQuality = 85
+
+// ErrTooLarge is returned for an image wider or higher than the limits
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// Limits are the largest dimensions of an image in pixels
+type Limits struct {
+	MaxWidth  int
+	MaxHeight int
+}
+
+// Image is an image checked by Validate, or a thumbnail
+type Image struct {
+	Format Format
+	Width  int
+	Height int
+	// Data is the encoded image without metadata
+	Data []byte
+}
+
+// Validate sniffs the format of data, checks its dimensions and strips its metadata
+func Validate(data []byte, limits Limits) (*Image, error) {
+	img, err := DecodeHeader(bytes.NewReader(data), limits)
+	if err != nil {
+		return nil, err
+	}
+
+	img.Data, err = StripMetadata(img.Format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// DecodeHeader sniffs the format of the image read from r and checks its dimensions,
+// only the header is read and the image returned has no data
+func DecodeHeader(r io.Reader, limits Limits) (*Image, error) {
+	buffered := bufio.NewReader(r)
+	signature, _ := buffered.Peek(12)
+	format, err := Sniff(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the header is decoded, so that a huge image doesn't take all the memory
+	config, name, err := image.DecodeConfig(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if Format(name) != format || config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("%w: invalid %s header", ErrCorrupted, format)
+	}
+
+	if config.Width > limits.MaxWidth || config.Height > limits.MaxHeight {
+		return nil, fmt.Errorf(
+			"%w: %dx%d, the limit is %dx%d",
+			ErrTooLarge, config.Width, config.Height, limits.MaxWidth, limits.MaxHeight,
+		)
+	}
+
+	return &Image{
+		Format: format,
+		Width:  config.Width,
+		Height: config.Height,
+	}, nil
+}
+
+// Thumbnails returns the image scaled down to fit in squares of the sizes in pixels,
+// a smaller image is not scaled up. The thumbnails of a JPEG image are JPEG images turned
+// the way its EXIF orientation says, since they have no metadata themselves. The others are
+// PNG images to keep their transparency, and the first frame of a GIF is used.
+func (img *Image) Thumbnails(sizes ...int) ([]*Image, error) {
+	return DecodeThumbnails(bytes.NewReader(img.Data), img.Format, sizes...)
+}
+
+// DecodeThumbnails decodes the image of this format read from r and returns its thumbnails
+// like Image.Thumbnails, so that the encoded image is not read in memory
+func DecodeThumbnails(r io.Reader, format Format, sizes ...int) ([]*Image, error) {
+	orientation := 1
+	if format == JPEG {
+		// the segments read for the orientation are decoded again with the image
+		var header bytes.Buffer
+		orientation = readJPEGOrientation(io.TeeReader(r, &header))
+		r = io.MultiReader(&header, r)
+	}
+
+	decoded, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	thumbnails := make([]*Image, 0, len(sizes))
+	for _, size := range sizes {
+		thumbnail, err := makeThumbnail(decoded, format, size, orientation)
+		if err != nil {
+			return nil, err
+		}
+		thumbnails = append(thumbnails, thumbnail)
+	}
+
+	return thumbnails, nil
+}
+
+func makeThumbnail(src image.Image, format Format, size int, orientation int) (*Image, error) {
+	bounds := src.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), size)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, bounds, draw.Src, nil)
+
+	// turned once scaled down, so that only the pixels of the thumbnail are moved
+	dst := orient(scaled, orientation)
+	width, height = dst.Bounds().Dx(), dst.Bounds().Dy()
+
+	var buffer bytes.Buffer
+	var err error
+	if format == JPEG {
+		err = jpeg.Encode(&buffer, dst, &jpeg.Options{Quality: thumbnailQuality})
+	} else {
+		format = PNG
+		err = png.Encode(&buffer, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %dx%d thumbnail: %w", width, height, err)
+	}
+
+	return &Image{
+		Format: format,
+		Width:  width,
+		Height: height,
+		Data:   buffer.Bytes(),
+	}, nil
+}
+
+// orient returns the image turned upright from an EXIF orientation,
+// 2 to 4 are flipped or rotated by 180°, 5 to 8 are also transposed
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if orientation >= 5 {
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	}
+
+	bounds := dst.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.SetRGBA(x, y, src.RGBAAt(sx, sy))
+		}
+	}
+
+	return dst
+}
+
+// fit returns the dimensions scaled down to fit in a square of size pixels, keeping the aspect ratio
+func fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+
+	if width >= height {
+		height = height * size / width
+		width = size
+	} else {
+		width = width * size / height
+		height = size
+	}
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return width, height
+}
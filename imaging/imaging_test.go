@@ -0,0 +1,366 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/imaging"
+)
+
+// webpImage is a lossless WebP image of 1x1 pixel
+const webpImage = "RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00\x2f\x00\x00\x00\x10\x07\x10\x11\x11\x88\x88\xfe\x07\x00"
+
+var limits = imaging.Limits{MaxWidth: 1000, MaxHeight: 1000}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		data   []byte
+		format imaging.Format
+		width  int
+		height int
+		err    error
+	}{
+		{
+			name:   "png",
+			data:   encodeTestImage(t, imaging.PNG, 300, 200),
+			format: imaging.PNG,
+			width:  300,
+			height: 200,
+		},
+		{
+			name:   "jpeg",
+			data:   encodeTestImage(t, imaging.JPEG, 20, 1000),
+			format: imaging.JPEG,
+			width:  20,
+			height: 1000,
+		},
+		{
+			name:   "webp",
+			data:   []byte(webpImage),
+			format: imaging.WebP,
+			width:  1,
+			height: 1,
+		},
+		{
+			name: "too large",
+			data: encodeTestImage(t, imaging.PNG, 1001, 10),
+			err:  imaging.ErrTooLarge,
+		},
+		{
+			name: "unknown",
+			data: []byte("<svg></svg>"),
+			err:  imaging.ErrUnknownFormat,
+		},
+		{
+			name: "corrupted",
+			data: encodeTestImage(t, imaging.PNG, 10, 10)[:20],
+			err:  imaging.ErrCorrupted,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			img, err := imaging.Validate(tc.data, limits)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.format, img.Format)
+			require.Equal(t, tc.width, img.Width)
+			require.Equal(t, tc.height, img.Height)
+		})
+	}
+}
+
+func TestStripMetadata(t *testing.T) {
+	t.Parallel()
+
+	exif := "Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08"
+
+	jpegData := encodeTestImage(t, imaging.JPEG, 30, 20)
+	app1 := append([]byte{0xff, 0xe1, 0, byte(2 + len(exif))}, exif...)
+	jpegData = append(jpegData[:2], append(app1, jpegData[2:]...)...)
+
+	pngData := encodeTestImage(t, imaging.PNG, 30, 20)
+	text := pngChunk("tEXt", "Software\x00camera")
+	// after the signature and the IHDR chunk
+	pngData = append(pngData[:33], append(text, pngData[33:]...)...)
+
+	webpData := []byte("RIFF\x00\x00\x00\x00WEBP" + "VP8X\x0a\x00\x00\x00\x08\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	webpData = append(webpData, webpImage[12:]...)
+	webpData = append(webpData, "EXIF\x0e\x00\x00\x00"+exif...)
+	binary.LittleEndian.PutUint32(webpData[4:], uint32(len(webpData)-8))
+
+	orientedData := encodeTestImage(t, imaging.JPEG, 30, 20)
+	orientedData = append(orientedData[:2], append(exifSegment(6), orientedData[2:]...)...)
+
+	var gifImage bytes.Buffer
+	err := gif.Encode(&gifImage, image.NewRGBA(image.Rect(0, 0, 30, 20)), nil)
+	require.NoError(t, err)
+	gifData := gifImage.Bytes()
+	// after the header, the logical screen descriptor and the global color table
+	blocks := 13 + 3<<(gifData[10]&0x07+1)
+	extensions := "\x21\xfe\x06camera\x00" + "\x21\xff\x0bXMP DataXMP\x05<xmp>\x00"
+	gifData = append(gifData[:blocks:blocks], append([]byte(extensions), gifData[blocks:]...)...)
+
+	testCases := []struct {
+		name     string
+		format   imaging.Format
+		data     []byte
+		metadata string
+	}{
+		{"jpeg", imaging.JPEG, jpegData, "Exif"},
+		{"jpeg with orientation", imaging.JPEG, orientedData, "camera"},
+		{"png", imaging.PNG, pngData, "Software"},
+		{"webp", imaging.WebP, webpData, "Exif"},
+		{"gif", imaging.GIF, gifData, "camera"},
+		{"gif xmp", imaging.GIF, gifData, "XMP"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			original, err := imaging.Validate(tc.data, limits)
+			require.NoError(t, err)
+			require.NotContains(t, string(original.Data), tc.metadata)
+			require.Less(t, len(original.Data), len(tc.data))
+
+			_, _, err = image.Decode(bytes.NewReader(original.Data))
+			require.NoError(t, err)
+
+			stripped, err := imaging.StripMetadata(tc.format, original.Data)
+			require.NoError(t, err)
+			require.Equal(t, original.Data, stripped)
+		})
+	}
+
+	// only the orientation is left of the EXIF metadata
+	stripped, err := imaging.StripMetadata(imaging.JPEG, orientedData)
+	require.NoError(t, err)
+	require.Equal(t, len(orientedData)-len(exifSegment(6))+36, len(stripped))
+	require.Contains(t, string(stripped), "Exif\x00\x00MM")
+
+	// the EXIF flag of the VP8X chunk
+	stripped, err = imaging.StripMetadata(imaging.WebP, webpData)
+	require.NoError(t, err)
+	require.Zero(t, stripped[20]&0x08)
+
+	_, err = imaging.StripMetadata(imaging.PNG, jpegData)
+	require.ErrorIs(t, err, imaging.ErrCorrupted)
+}
+
+func TestWriteWithoutMetadata(t *testing.T) {
+	t.Parallel()
+
+	pngData := encodeTestImage(t, imaging.PNG, 30, 20)
+	text := pngChunk("tEXt", "Software\x00camera")
+	pngData = append(pngData[:33], append(text, pngData[33:]...)...)
+
+	var stripped bytes.Buffer
+	n, err := imaging.WriteWithoutMetadata(&stripped, imaging.PNG, bytes.NewReader(pngData))
+	require.NoError(t, err)
+	require.EqualValues(t, len(pngData)-len(text), n)
+	require.EqualValues(t, stripped.Len(), n)
+
+	for _, tc := range []struct {
+		format imaging.Format
+		data   []byte
+	}{
+		{imaging.PNG, pngData[:40]},
+		{imaging.JPEG, encodeTestImage(t, imaging.JPEG, 30, 20)[:10]},
+		{imaging.WebP, []byte(webpImage[:len(webpImage)-4])},
+	} {
+		_, err := imaging.WriteWithoutMetadata(ioutil.Discard, tc.format, bytes.NewReader(tc.data))
+		require.ErrorIs(t, err, imaging.ErrCorrupted, tc.format)
+	}
+}
+
+func TestThumbnails(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		format    imaging.Format
+		width     int
+		height    int
+		sizes     []int
+		expected  [][2]int
+		thumbnail imaging.Format
+	}{
+		{imaging.JPEG, 1000, 500, []int{128, 2000}, [][2]int{{128, 64}, {1000, 500}}, imaging.JPEG},
+		{imaging.PNG, 100, 400, []int{128, 64}, [][2]int{{32, 128}, {16, 64}}, imaging.PNG},
+		{imaging.WebP, 1, 1, []int{128}, [][2]int{{1, 1}}, imaging.PNG},
+	}
+
+	for _, tc := range testCases {
+		data := []byte(webpImage)
+		if tc.format != imaging.WebP {
+			data = encodeTestImage(t, tc.format, tc.width, tc.height)
+		}
+
+		img, err := imaging.Validate(data, limits)
+		require.NoError(t, err)
+
+		thumbnails, err := img.Thumbnails(tc.sizes...)
+		require.NoError(t, err)
+		require.Len(t, thumbnails, len(tc.sizes))
+
+		for i, thumbnail := range thumbnails {
+			require.Equal(t, tc.thumbnail, thumbnail.Format)
+			require.Equal(t, tc.expected[i], [2]int{thumbnail.Width, thumbnail.Height})
+
+			config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail.Data))
+			require.NoError(t, err)
+			require.Equal(t, string(tc.thumbnail), format)
+			require.Equal(t, tc.expected[i], [2]int{config.Width, config.Height})
+		}
+	}
+}
+
+func TestThumbnailsOrientation(t *testing.T) {
+	t.Parallel()
+
+	// red on the left and blue on the right
+	img := image.NewRGBA(image.Rect(0, 0, 30, 20))
+	for x := 0; x < 30; x++ {
+		for y := 0; y < 20; y++ {
+			if x < 15 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	err := jpeg.Encode(&buffer, img, nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		orientation int
+		width       int
+		height      int
+		// the points that are red and blue once turned upright
+		red  image.Point
+		blue image.Point
+	}{
+		{1, 30, 20, image.Pt(5, 10), image.Pt(25, 10)},
+		{3, 30, 20, image.Pt(25, 10), image.Pt(5, 10)},
+		// rotated by 90° clockwise to be upright, the left side is on top
+		{6, 20, 30, image.Pt(10, 5), image.Pt(10, 25)},
+		{8, 20, 30, image.Pt(10, 25), image.Pt(10, 5)},
+	}
+
+	for _, tc := range testCases {
+		data := buffer.Bytes()
+		data = append(data[:2:2], append(exifSegment(tc.orientation), data[2:]...)...)
+
+		original, err := imaging.Validate(data, limits)
+		require.NoError(t, err)
+		require.Equal(t, 30, original.Width)
+
+		// from the original and from the image stripped down to its orientation
+		for _, thumbnailsOf := range []func(...int) ([]*imaging.Image, error){
+			func(sizes ...int) ([]*imaging.Image, error) {
+				return imaging.DecodeThumbnails(bytes.NewReader(data), imaging.JPEG, sizes...)
+			},
+			original.Thumbnails,
+		} {
+			thumbnails, err := thumbnailsOf(128)
+			require.NoError(t, err)
+
+			thumbnail := thumbnails[0]
+			require.Equal(t, [2]int{tc.width, tc.height}, [2]int{thumbnail.Width, thumbnail.Height})
+
+			decoded, err := jpeg.Decode(bytes.NewReader(thumbnail.Data))
+			require.NoError(t, err)
+
+			r, _, b, _ := decoded.At(tc.red.X, tc.red.Y).RGBA()
+			require.Greater(t, r, b, tc.orientation)
+			r, _, b, _ = decoded.At(tc.blue.X, tc.blue.Y).RGBA()
+			require.Greater(t, b, r, tc.orientation)
+		}
+	}
+}
+
+func TestFormatFromExtension(t *testing.T) {
+	t.Parallel()
+
+	for extension, expected := range map[string]imaging.Format{
+		".png":  imaging.PNG,
+		".JPG":  imaging.JPEG,
+		"jpeg":  imaging.JPEG,
+		".gif":  imaging.GIF,
+		".webp": imaging.WebP,
+	} {
+		format, err := imaging.FormatFromExtension(extension)
+		require.NoError(t, err)
+		require.Equal(t, expected, format)
+	}
+
+	_, err := imaging.FormatFromExtension(".svg")
+	require.ErrorIs(t, err, imaging.ErrUnknownFormat)
+
+	require.Equal(t, ".jpg", imaging.JPEG.Extension())
+	require.Equal(t, "image/webp", imaging.WebP.ContentType())
+}
+
+func encodeTestImage(t *testing.T, format imaging.Format, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, x%height, color.RGBA{R: 200, A: 255})
+	}
+
+	var buffer bytes.Buffer
+	var err error
+	if format == imaging.JPEG {
+		err = jpeg.Encode(&buffer, img, nil)
+	} else {
+		err = png.Encode(&buffer, img)
+	}
+	require.NoError(t, err)
+
+	return buffer.Bytes()
+}
+
+// pngChunk returns a PNG chunk with its length and CRC
+func pngChunk(chunkType, data string) []byte {
+	chunk := make([]byte, 8+len(data)+4)
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], chunkType+data)
+	binary.BigEndian.PutUint32(chunk[8+len(data):], crc32.ChecksumIEEE(chunk[4:8+len(data)]))
+	return chunk
+}
+
+// exifSegment returns a JPEG APP1 segment with big endian EXIF metadata
+// made of the camera maker and the orientation
+func exifSegment(orientation int) []byte {
+	tiff := "MM\x00\x2a\x00\x00\x00\x08" +
+		// two entries, the maker is an ASCII string of 7 bytes at offset 38
+		"\x00\x02" +
+		"\x01\x0f\x00\x02\x00\x00\x00\x07\x00\x00\x00\x26" +
+		"\x01\x12\x00\x03\x00\x00\x00\x01\x00" + string(rune(orientation)) + "\x00\x00" +
+		"\x00\x00\x00\x00" +
+		"camera\x00"
+
+	data := "Exif\x00\x00" + tiff
+	return append([]byte{0xff, 0xe1, 0, byte(2 + len(data))}, data...)
+}
@@ -0,0 +1,564 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// ErrCorrupted is returned for data that looks like an image but cannot be parsed
+var ErrCorrupted = errors.New("corrupted image")
+
+// StripMetadata returns the image without the metadata of its camera or editor, like EXIF and XMP.
+// The pixels are left as they are, so the image is not encoded again. The EXIF orientation
+// of a JPEG image is kept, otherwise a photo taken sideways would be displayed sideways.
+func StripMetadata(format Format, data []byte) ([]byte, error) {
+	var stripped bytes.Buffer
+	_, err := WriteWithoutMetadata(&stripped, format, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return stripped.Bytes(), nil
+}
+
+// WriteWithoutMetadata writes the image read from src to dst without its metadata like StripMetadata,
+// and returns the number of bytes written. src is read from its start, the image is streamed rather
+// than read in memory, but a WebP image is read twice since the size of its container comes before its chunks.
+func WriteWithoutMetadata(dst io.Writer, format Format, src io.ReadSeeker) (int64, error) {
+	_, err := src.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	signature := make([]byte, 12)
+	n, err := io.ReadFull(src, signature)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+
+	sniffed, err := Sniff(signature[:n])
+	if err != nil || sniffed != format {
+		return 0, fmt.Errorf("%w: not a %s image", ErrCorrupted, format)
+	}
+
+	_, err = src.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	counter := &countingWriter{writer: dst}
+	switch format {
+	case PNG:
+		err = stripPNG(counter, src)
+	case JPEG:
+		err = stripJPEG(counter, bufio.NewReader(src))
+	case GIF:
+		err = stripGIF(counter, bufio.NewReader(src))
+	case WebP:
+		err = stripWebP(counter, src)
+	default:
+		_, err = io.Copy(counter, src)
+	}
+
+	return counter.count, err
+}
+
+// pngMetadataChunks are the PNG chunks with EXIF, text like the author or the software and the time of the last edit
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNG removes the metadata chunks, every chunk is a length, a type, the data and a CRC
+func stripPNG(dst io.Writer, src io.Reader) error {
+	const signatureSize = 8
+
+	err := copyN(dst, src, signatureSize, "PNG signature")
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(src, header)
+		if err == io.EOF {
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("%w: truncated PNG chunk", ErrCorrupted)
+		}
+		if err != nil {
+			return err
+		}
+
+		// the data and the CRC
+		length := int64(binary.BigEndian.Uint32(header)) + 4
+		chunkType := string(header[4:])
+		if pngMetadataChunks[chunkType] {
+			err = copyN(ioutil.Discard, src, length, "PNG chunk")
+		} else if _, err = dst.Write(header); err == nil {
+			err = copyN(dst, src, length, "PNG chunk")
+		}
+		if err != nil {
+			return err
+		}
+
+		if chunkType == "IEND" {
+			return nil
+		}
+	}
+}
+
+// markers of the JPEG segments read by stripJPEG and readJPEGOrientation
+const (
+	jpegMarkerAPP1  = 0xe1
+	jpegMarkerAPP13 = 0xed
+	jpegMarkerSOS   = 0xda
+)
+
+// exifOrientationTag is the tag of the orientation in the first IFD of EXIF metadata
+const exifOrientationTag = 0x0112
+
+// stripJPEG removes the APP1 segments with EXIF and XMP and the APP13 segments with IPTC
+// before the start of scan, every segment is a marker and a length that includes itself.
+// An EXIF segment with an orientation is replaced by a segment with only the orientation.
+func stripJPEG(dst io.Writer, src *bufio.Reader) error {
+	err := copyN(dst, src, 2, "JPEG marker")
+	if err != nil {
+		return err
+	}
+
+	for {
+		segment, err := src.Peek(4)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(segment) < 4 || segment[0] != 0xff {
+			return fmt.Errorf("%w: invalid JPEG segment", ErrCorrupted)
+		}
+
+		marker := segment[1]
+		if marker == 0xff {
+			// fill byte before a marker
+			src.Discard(1)
+			continue
+		}
+		if marker == jpegMarkerSOS {
+			// the scan and the rest of the image have no metadata
+			_, err := io.Copy(dst, src)
+			return err
+		}
+
+		length := 2 + int64(binary.BigEndian.Uint16(segment[2:]))
+		switch marker {
+		case jpegMarkerAPP1:
+			err = stripJPEGApp1(dst, src, length)
+		case jpegMarkerAPP13:
+			err = copyN(ioutil.Discard, src, length, "JPEG segment")
+		default:
+			err = copyN(dst, src, length, "JPEG segment")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// stripJPEGApp1 reads an APP1 segment of length bytes and writes an EXIF segment
+// with only its orientation, if it has one
+func stripJPEGApp1(dst io.Writer, src io.Reader, length int64) error {
+	var segment bytes.Buffer
+	err := copyN(&segment, src, length, "JPEG segment")
+	if err != nil {
+		return err
+	}
+
+	order, orientation := exifOrientation(segment.Bytes()[4:])
+	if orientation <= 1 {
+		return nil
+	}
+
+	_, err = dst.Write(exifOrientationSegment(order, orientation))
+	return err
+}
+
+// exifOrientation returns the byte order and the orientation of the EXIF metadata in the data
+// of an APP1 segment, the orientation is 0 if the segment has no valid orientation
+func exifOrientation(data []byte) (binary.ByteOrder, int) {
+	const header = "Exif\x00\x00"
+	if !bytes.HasPrefix(data, []byte(header)) {
+		return nil, 0
+	}
+
+	// the TIFF header is the byte order, 42 and the offset of the first IFD
+	tiff := data[len(header):]
+	if len(tiff) < 8 {
+		return nil, 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0
+	}
+
+	// an IFD is a count of entries, every entry is a tag, a type, a count and a value of 4 bytes
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd+2 > int64(len(tiff)) {
+		return nil, 0
+	}
+
+	count := int64(order.Uint16(tiff[ifd:]))
+	for entry := ifd + 2; entry < ifd+2+count*12 && entry+12 <= int64(len(tiff)); entry += 12 {
+		const typeShort = 3
+		if order.Uint16(tiff[entry:]) != exifOrientationTag || order.Uint16(tiff[entry+2:]) != typeShort {
+			continue
+		}
+
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return nil, 0
+		}
+		return order, orientation
+	}
+
+	return nil, 0
+}
+
+// exifOrientationSegment returns an APP1 segment with EXIF metadata made of the orientation only
+func exifOrientationSegment(order binary.ByteOrder, orientation int) []byte {
+	segment := []byte("\xff\xe1\x00\x22Exif\x00\x00")
+
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	// one entry of one short, then no next IFD
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+
+	return append(segment, tiff...)
+}
+
+// readJPEGOrientation reads the segments of a JPEG image up to the start of scan
+// and returns its EXIF orientation, 1 if it has none
+func readJPEGOrientation(r io.Reader) int {
+	src := bufio.NewReader(r)
+	_, err := src.Discard(2)
+	if err != nil {
+		return 1
+	}
+
+	for {
+		segment, err := src.Peek(4)
+		if err != nil || segment[0] != 0xff || segment[1] == jpegMarkerSOS {
+			return 1
+		}
+		if segment[1] == 0xff {
+			src.Discard(1)
+			continue
+		}
+
+		length := 2 + int(binary.BigEndian.Uint16(segment[2:]))
+		if segment[1] != jpegMarkerAPP1 {
+			_, err = src.Discard(length)
+			if err != nil {
+				return 1
+			}
+			continue
+		}
+
+		data := make([]byte, length)
+		_, err = io.ReadFull(src, data)
+		if err != nil {
+			return 1
+		}
+		if _, orientation := exifOrientation(data[4:]); orientation != 0 {
+			return orientation
+		}
+	}
+}
+
+// stripGIF removes the comment extensions and the XMP application extension. After the header,
+// the logical screen descriptor and the global color table, a GIF is a list of blocks ending
+// with a trailer, and the data of every block is a list of sub-blocks ending with an empty one.
+func stripGIF(dst io.Writer, src *bufio.Reader) error {
+	const (
+		extensionBlock   = 0x21
+		imageBlock       = 0x2c
+		trailer          = 0x3b
+		commentLabel     = 0xfe
+		applicationLabel = 0xff
+		xmpApplication   = "XMP DataXMP"
+	)
+
+	header := make([]byte, 13)
+	_, err := io.ReadFull(src, header)
+	if err != nil {
+		return fmt.Errorf("%w: truncated GIF header", ErrCorrupted)
+	}
+	_, err = dst.Write(header)
+	if err != nil {
+		return err
+	}
+
+	err = copyGIFColorTable(dst, src, header[10])
+	if err != nil {
+		return err
+	}
+
+	for {
+		block, err := src.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch block {
+		case trailer:
+			_, err = dst.Write([]byte{block})
+			return err
+
+		case imageBlock:
+			// the position, the size and the flags of the image, then the LZW minimum code size
+			descriptor := make([]byte, 10)
+			descriptor[0] = block
+			err = readGIFBlock(src, descriptor[1:])
+			if err == nil {
+				_, err = dst.Write(descriptor)
+			}
+			if err == nil {
+				err = copyGIFColorTable(dst, src, descriptor[9])
+			}
+			if err == nil {
+				err = copyN(dst, src, 1, "GIF image")
+			}
+			if err == nil {
+				err = copyGIFSubBlocks(dst, src)
+			}
+
+		case extensionBlock:
+			var label byte
+			label, err = src.ReadByte()
+			if err != nil {
+				return fmt.Errorf("%w: truncated GIF extension", ErrCorrupted)
+			}
+
+			if label == commentLabel || (label == applicationLabel && isGIFApplication(src, xmpApplication)) {
+				err = copyGIFSubBlocks(ioutil.Discard, src)
+				break
+			}
+
+			_, err = dst.Write([]byte{block, label})
+			if err == nil {
+				err = copyGIFSubBlocks(dst, src)
+			}
+
+		default:
+			return fmt.Errorf("%w: invalid GIF block", ErrCorrupted)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// copyGIFColorTable copies the color table that follows a descriptor with these flags, if any
+func copyGIFColorTable(dst io.Writer, src io.Reader, flags byte) error {
+	if flags&0x80 == 0 {
+		return nil
+	}
+
+	return copyN(dst, src, 3<<(flags&0x07+1), "GIF color table")
+}
+
+// copyGIFSubBlocks copies the sub-blocks of a block up to and including the empty one
+func copyGIFSubBlocks(dst io.Writer, src *bufio.Reader) error {
+	for {
+		size, err := src.ReadByte()
+		if err == io.EOF {
+			return fmt.Errorf("%w: truncated GIF block", ErrCorrupted)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = dst.Write([]byte{size})
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			return nil
+		}
+
+		err = copyN(dst, src, int64(size), "GIF block")
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// isGIFApplication tells if the application extension read from src is the given one,
+// its first sub-block is the identifier and the authentication code of the application
+func isGIFApplication(src *bufio.Reader, application string) bool {
+	identifier, err := src.Peek(1 + len(application))
+	return err == nil && int(identifier[0]) == len(application) && string(identifier[1:]) == application
+}
+
+func readGIFBlock(src io.Reader, data []byte) error {
+	_, err := io.ReadFull(src, data)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated GIF block", ErrCorrupted)
+	}
+
+	return err
+}
+
+// webpChunk is a chunk of a RIFF container kept by stripWebP, size includes its header and its padding
+type webpChunk struct {
+	offset    int64
+	size      int64
+	chunkType string
+}
+
+// stripWebP removes the EXIF and XMP chunks of a RIFF container, then clears their flags
+// in the VP8X chunk and updates the size of the container
+func stripWebP(dst io.Writer, src io.ReadSeeker) error {
+	const (
+		headerSize = 12
+		flagEXIF   = 0x08
+		flagXMP    = 0x04
+	)
+
+	end, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	// the chunks to keep and the size of the container once stripped
+	var chunks []webpChunk
+	strippedSize := int64(headerSize)
+	header := make([]byte, 8)
+	for offset := int64(headerSize); offset < end; {
+		if end-offset < 8 {
+			return fmt.Errorf("%w: truncated WebP chunk", ErrCorrupted)
+		}
+
+		_, err := src.Seek(offset, io.SeekStart)
+		if err == nil {
+			_, err = io.ReadFull(src, header)
+		}
+		if err != nil {
+			return err
+		}
+
+		size := int64(binary.LittleEndian.Uint32(header[4:]))
+		// chunks are padded to an even size
+		chunkEnd := offset + 8 + size + size%2
+		if chunkEnd > end {
+			return fmt.Errorf("%w: truncated WebP chunk", ErrCorrupted)
+		}
+
+		chunkType := string(header[:4])
+		if chunkType != "EXIF" && chunkType != "XMP " {
+			chunks = append(chunks, webpChunk{offset, chunkEnd - offset, chunkType})
+			strippedSize += chunkEnd - offset
+		}
+
+		offset = chunkEnd
+	}
+
+	riff := make([]byte, headerSize)
+	_, err = src.Seek(0, io.SeekStart)
+	if err == nil {
+		_, err = io.ReadFull(src, riff)
+	}
+	if err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(riff[4:], uint32(strippedSize-8))
+	_, err = dst.Write(riff)
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks {
+		_, err := src.Seek(chunk.offset, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		if chunk.chunkType == "VP8X" {
+			// the header and the flags
+			start := make([]byte, 9)
+			if chunk.size < 9 {
+				start = start[:chunk.size]
+			}
+			_, err = io.ReadFull(src, start)
+			if err != nil {
+				return err
+			}
+
+			if len(start) > 8 {
+				start[8] &^= flagEXIF | flagXMP
+			}
+			_, err = dst.Write(start)
+			if err != nil {
+				return err
+			}
+
+			err = copyN(dst, src, chunk.size-int64(len(start)), "WebP chunk")
+		} else {
+			err = copyN(dst, src, chunk.size, "WebP chunk")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyN copies n bytes of src to dst, src being shorter means that the image is truncated
+func copyN(dst io.Writer, src io.Reader, n int64, part string) error {
+	_, err := io.CopyN(dst, src, n)
+	if err == io.EOF {
+		return fmt.Errorf("%w: truncated %s", ErrCorrupted, part)
+	}
+
+	return err
+}
+
+// countingWriter counts the bytes written to a writer
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (counter *countingWriter) Write(p []byte) (int, error) {
+	n, err := counter.writer.Write(p)
+	counter.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,840 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.13.0
+// source: catalog_service.proto
+
+package pb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type CatalogFormat int32
+
+const (
+	CatalogFormat_UNKNOWN_CATALOG_FORMAT CatalogFormat = 0
+	// one JSON laptop per line
+	CatalogFormat_NDJSON CatalogFormat = 1
+	// binary laptops, each one after its varint length
+	CatalogFormat_PROTOBUF CatalogFormat = 2
+	// one laptop per row and one column per nested field
+	CatalogFormat_CSV CatalogFormat = 3
+)
+
+// Enum value maps for CatalogFormat.
+var (
+	CatalogFormat_name = map[int32]string{
+		0: "UNKNOWN_CATALOG_FORMAT",
+		1: "NDJSON",
+		2: "PROTOBUF",
+		3: "CSV",
+	}
+	CatalogFormat_value = map[string]int32{
+		"UNKNOWN_CATALOG_FORMAT": 0,
+		"NDJSON":                 1,
+		"PROTOBUF":               2,
+		"CSV":                    3,
+	}
+)
+
+func (x CatalogFormat) Enum() *CatalogFormat {
+	p := new(CatalogFormat)
+	*p = x
+	return p
+}
+
+func (x CatalogFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CatalogFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_catalog_service_proto_enumTypes[0].Descriptor()
+}
+
+func (CatalogFormat) Type() protoreflect.EnumType {
+	return &file_catalog_service_proto_enumTypes[0]
+}
+
+func (x CatalogFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CatalogFormat.Descriptor instead.
+func (CatalogFormat) EnumDescriptor() ([]byte, []int) {
+	return file_catalog_service_proto_rawDescGZIP(), []int{0}
+}
+
+type ExportLaptopsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format CatalogFormat `protobuf:"varint,1,opt,name=format,proto3,enum=techschool.pcbook.CatalogFormat" json:"format,omitempty"`
+}
+
+func (x *ExportLaptopsRequest) Reset() {
+	*x = ExportLaptopsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_catalog_service_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportLaptopsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportLaptopsRequest) ProtoMessage() {}
+
+func (x *ExportLaptopsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_service_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportLaptopsRequest.ProtoReflect.Descriptor instead.
+func (*ExportLaptopsRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExportLaptopsRequest) GetFormat() CatalogFormat {
+	if x != nil {
+		return x.Format
+	}
+	return CatalogFormat_UNKNOWN_CATALOG_FORMAT
+}
+
+type ExportLaptopsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the chunks form a file of the requested format
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+}
+
+func (x *ExportLaptopsResponse) Reset() {
+	*x = ExportLaptopsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_catalog_service_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportLaptopsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportLaptopsResponse) ProtoMessage() {}
+
+func (x *ExportLaptopsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_service_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportLaptopsResponse.ProtoReflect.Descriptor instead.
+func (*ExportLaptopsResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExportLaptopsResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type CatalogImportOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format CatalogFormat `protobuf:"varint,1,opt,name=format,proto3,enum=techschool.pcbook.CatalogFormat" json:"format,omitempty"`
+	// validate the laptops without saving them
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// replace the laptops that already exist instead of reporting them
+	Upsert bool `protobuf:"varint,3,opt,name=upsert,proto3" json:"upsert,omitempty"`
+}
+
+func (x *CatalogImportOptions) Reset() {
+	*x = CatalogImportOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_catalog_service_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CatalogImportOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogImportOptions) ProtoMessage() {}
+
+func (x *CatalogImportOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_service_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogImportOptions.ProtoReflect.Descriptor instead.
+func (*CatalogImportOptions) Descriptor() ([]byte, []int) {
+	return file_catalog_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CatalogImportOptions) GetFormat() CatalogFormat {
+	if x != nil {
+		return x.Format
+	}
+	return CatalogFormat_UNKNOWN_CATALOG_FORMAT
+}
+
+func (x *CatalogImportOptions) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *CatalogImportOptions) GetUpsert() bool {
+	if x != nil {
+		return x.Upsert
+	}
+	return false
+}
+
+type ImportLaptopsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the first request has the options, the next ones the chunks of the file
+	//
+	// Types that are assignable to Data:
+	//	*ImportLaptopsRequest_Options
+	//	*ImportLaptopsRequest_Chunk
+	Data isImportLaptopsRequest_Data `protobuf_oneof:"data"`
+}
+
+func (x *ImportLaptopsRequest) Reset() {
+	*x = ImportLaptopsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_catalog_service_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportLaptopsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportLaptopsRequest) ProtoMessage() {}
+
+func (x *ImportLaptopsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_service_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportLaptopsRequest.ProtoReflect.Descriptor instead.
+func (*ImportLaptopsRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (m *ImportLaptopsRequest) GetData() isImportLaptopsRequest_Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (x *ImportLaptopsRequest) GetOptions() *CatalogImportOptions {
+	if x, ok := x.GetData().(*ImportLaptopsRequest_Options); ok {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ImportLaptopsRequest) GetChunk() []byte {
+	if x, ok := x.GetData().(*ImportLaptopsRequest_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+type isImportLaptopsRequest_Data interface {
+	isImportLaptopsRequest_Data()
+}
+
+type ImportLaptopsRequest_Options struct {
+	Options *CatalogImportOptions `protobuf:"bytes,1,opt,name=options,proto3,oneof"`
+}
+
+type ImportLaptopsRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*ImportLaptopsRequest_Options) isImportLaptopsRequest_Data() {}
+
+func (*ImportLaptopsRequest_Chunk) isImportLaptopsRequest_Data() {}
+
+type CatalogImportError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// line, row or record number in the file
+	Line  uint32 `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *CatalogImportError) Reset() {
+	*x = CatalogImportError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_catalog_service_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CatalogImportError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogImportError) ProtoMessage() {}
+
+func (x *CatalogImportError) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_service_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogImportError.ProtoReflect.Descriptor instead.
+func (*CatalogImportError) Descriptor() ([]byte, []int) {
+	return file_catalog_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CatalogImportError) GetLine() uint32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *CatalogImportError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ImportLaptopsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CreatedCount uint32                `protobuf:"varint,1,opt,name=created_count,json=createdCount,proto3" json:"created_count,omitempty"`
+	UpdatedCount uint32                `protobuf:"varint,2,opt,name=updated_count,json=updatedCount,proto3" json:"updated_count,omitempty"`
+	Errors       []*CatalogImportError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+}
+
+func (x *ImportLaptopsResponse) Reset() {
+	*x = ImportLaptopsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_catalog_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportLaptopsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportLaptopsResponse) ProtoMessage() {}
+
+func (x *ImportLaptopsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportLaptopsResponse.ProtoReflect.Descriptor instead.
+func (*ImportLaptopsResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ImportLaptopsResponse) GetCreatedCount() uint32 {
+	if x != nil {
+		return x.CreatedCount
+	}
+	return 0
+}
+
+func (x *ImportLaptopsResponse) GetUpdatedCount() uint32 {
+	if x != nil {
+		return x.UpdatedCount
+	}
+	return 0
+}
+
+func (x *ImportLaptopsResponse) GetErrors() []*CatalogImportError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+var File_catalog_service_proto protoreflect.FileDescriptor
+
+var file_catalog_service_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x11, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63, 0x68,
+	0x6f, 0x6f, 0x6c, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x50, 0x0a, 0x14, 0x45, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x38, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x20, 0x2e, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x70, 0x63,
+	0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x46, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x22, 0x2d, 0x0a, 0x15, 0x45, 0x78,
+	0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x81, 0x01, 0x0a, 0x14, 0x43, 0x61,
+	0x74, 0x61, 0x6c, 0x6f, 0x67, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x38, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x20, 0x2e, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x46, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x17, 0x0a, 0x07,
+	0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64,
+	0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x70, 0x73, 0x65, 0x72, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x75, 0x70, 0x73, 0x65, 0x72, 0x74, 0x22, 0x7b, 0x0a,
+	0x14, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x43, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63, 0x68,
+	0x6f, 0x6f, 0x6c, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x43, 0x61, 0x74, 0x61, 0x6c,
+	0x6f, 0x67, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x48,
+	0x00, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x0a, 0x05, 0x63, 0x68,
+	0x75, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x05, 0x63, 0x68, 0x75,
+	0x6e, 0x6b, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x3e, 0x0a, 0x12, 0x43, 0x61,
+	0x74, 0x61, 0x6c, 0x6f, 0x67, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04,
+	0x6c, 0x69, 0x6e, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xa0, 0x01, 0x0a, 0x15, 0x49,
+	0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x3d,
+	0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25,
+	0x2e, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x70, 0x63, 0x62, 0x6f,
+	0x6f, 0x6b, 0x2e, 0x43, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2a, 0x4e, 0x0a,
+	0x0d, 0x43, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1a,
+	0x0a, 0x16, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x43, 0x41, 0x54, 0x41, 0x4c, 0x4f,
+	0x47, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x44,
+	0x4a, 0x53, 0x4f, 0x4e, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x52, 0x4f, 0x54, 0x4f, 0x42,
+	0x55, 0x46, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x43, 0x53, 0x56, 0x10, 0x03, 0x32, 0xa8, 0x02,
+	0x0a, 0x0e, 0x43, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x89, 0x01, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f,
+	0x70, 0x73, 0x12, 0x27, 0x2e, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70,
+	0x74, 0x6f, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x74, 0x65,
+	0x63, 0x68, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e,
+	0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x18, 0x2f,
+	0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67,
+	0x2f, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x3a, 0x01, 0x2a, 0x30, 0x01, 0x12, 0x89, 0x01, 0x0a,
+	0x0d, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73, 0x12, 0x27,
+	0x2e, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x70, 0x63, 0x62, 0x6f,
+	0x6f, 0x6b, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x74, 0x65, 0x63, 0x68, 0x73, 0x63,
+	0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x70, 0x63, 0x62, 0x6f, 0x6f, 0x6b, 0x2e, 0x49, 0x6d, 0x70, 0x6f,
+	0x72, 0x74, 0x4c, 0x61, 0x70, 0x74, 0x6f, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x18, 0x2f, 0x76, 0x31, 0x2f, 0x61,
+	0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2f, 0x69, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x3a, 0x01, 0x2a, 0x28, 0x01, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x3b, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_catalog_service_proto_rawDescOnce sync.Once
+	file_catalog_service_proto_rawDescData = file_catalog_service_proto_rawDesc
+)
+
+func file_catalog_service_proto_rawDescGZIP() []byte {
+	file_catalog_service_proto_rawDescOnce.Do(func() {
+		file_catalog_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_catalog_service_proto_rawDescData)
+	})
+	return file_catalog_service_proto_rawDescData
+}
+
+var file_catalog_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_catalog_service_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_catalog_service_proto_goTypes = []interface{}{
+	(CatalogFormat)(0),            // 0: techschool.pcbook.CatalogFormat
+	(*ExportLaptopsRequest)(nil),  // 1: techschool.pcbook.ExportLaptopsRequest
+	(*ExportLaptopsResponse)(nil), // 2: techschool.pcbook.ExportLaptopsResponse
+	(*CatalogImportOptions)(nil),  // 3: techschool.pcbook.CatalogImportOptions
+	(*ImportLaptopsRequest)(nil),  // 4: techschool.pcbook.ImportLaptopsRequest
+	(*CatalogImportError)(nil),    // 5: techschool.pcbook.CatalogImportError
+	(*ImportLaptopsResponse)(nil), // 6: techschool.pcbook.ImportLaptopsResponse
+}
+var file_catalog_service_proto_depIdxs = []int32{
+	0, // 0: techschool.pcbook.ExportLaptopsRequest.format:type_name -> techschool.pcbook.CatalogFormat
+	0, // 1: techschool.pcbook.CatalogImportOptions.format:type_name -> techschool.pcbook.CatalogFormat
+	3, // 2: techschool.pcbook.ImportLaptopsRequest.options:type_name -> techschool.pcbook.CatalogImportOptions
+	5, // 3: techschool.pcbook.ImportLaptopsResponse.errors:type_name -> techschool.pcbook.CatalogImportError
+	1, // 4: techschool.pcbook.CatalogService.ExportLaptops:input_type -> techschool.pcbook.ExportLaptopsRequest
+	4, // 5: techschool.pcbook.CatalogService.ImportLaptops:input_type -> techschool.pcbook.ImportLaptopsRequest
+	2, // 6: techschool.pcbook.CatalogService.ExportLaptops:output_type -> techschool.pcbook.ExportLaptopsResponse
+	6, // 7: techschool.pcbook.CatalogService.ImportLaptops:output_type -> techschool.pcbook.ImportLaptopsResponse
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_catalog_service_proto_init() }
+func file_catalog_service_proto_init() {
+	if File_catalog_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_catalog_service_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportLaptopsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_catalog_service_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportLaptopsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_catalog_service_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CatalogImportOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_catalog_service_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportLaptopsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_catalog_service_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CatalogImportError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_catalog_service_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportLaptopsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_catalog_service_proto_msgTypes[3].OneofWrappers = []interface{}{
+		(*ImportLaptopsRequest_Options)(nil),
+		(*ImportLaptopsRequest_Chunk)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_catalog_service_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_catalog_service_proto_goTypes,
+		DependencyIndexes: file_catalog_service_proto_depIdxs,
+		EnumInfos:         file_catalog_service_proto_enumTypes,
+		MessageInfos:      file_catalog_service_proto_msgTypes,
+	}.Build()
+	File_catalog_service_proto = out.File
+	file_catalog_service_proto_rawDesc = nil
+	file_catalog_service_proto_goTypes = nil
+	file_catalog_service_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// CatalogServiceClient is the client API for CatalogService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CatalogServiceClient interface {
+	ExportLaptops(ctx context.Context, in *ExportLaptopsRequest, opts ...grpc.CallOption) (CatalogService_ExportLaptopsClient, error)
+	ImportLaptops(ctx context.Context, opts ...grpc.CallOption) (CatalogService_ImportLaptopsClient, error)
+}
+
+type catalogServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCatalogServiceClient(cc grpc.ClientConnInterface) CatalogServiceClient {
+	return &catalogServiceClient{cc}
+}
+
+func (c *catalogServiceClient) ExportLaptops(ctx context.Context, in *ExportLaptopsRequest, opts ...grpc.CallOption) (CatalogService_ExportLaptopsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CatalogService_serviceDesc.Streams[0], "/techschool.pcbook.CatalogService/ExportLaptops", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &catalogServiceExportLaptopsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CatalogService_ExportLaptopsClient interface {
+	Recv() (*ExportLaptopsResponse, error)
+	grpc.ClientStream
+}
+
+type catalogServiceExportLaptopsClient struct {
+	grpc.ClientStream
+}
+
+func (x *catalogServiceExportLaptopsClient) Recv() (*ExportLaptopsResponse, error) {
+	m := new(ExportLaptopsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *catalogServiceClient) ImportLaptops(ctx context.Context, opts ...grpc.CallOption) (CatalogService_ImportLaptopsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CatalogService_serviceDesc.Streams[1], "/techschool.pcbook.CatalogService/ImportLaptops", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &catalogServiceImportLaptopsClient{stream}
+	return x, nil
+}
+
+type CatalogService_ImportLaptopsClient interface {
+	Send(*ImportLaptopsRequest) error
+	CloseAndRecv() (*ImportLaptopsResponse, error)
+	grpc.ClientStream
+}
+
+type catalogServiceImportLaptopsClient struct {
+	grpc.ClientStream
+}
+
+func (x *catalogServiceImportLaptopsClient) Send(m *ImportLaptopsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *catalogServiceImportLaptopsClient) CloseAndRecv() (*ImportLaptopsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportLaptopsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CatalogServiceServer is the server API for CatalogService service.
+type CatalogServiceServer interface {
+	ExportLaptops(*ExportLaptopsRequest, CatalogService_ExportLaptopsServer) error
+	ImportLaptops(CatalogService_ImportLaptopsServer) error
+}
+
+// UnimplementedCatalogServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedCatalogServiceServer struct {
+}
+
+func (*UnimplementedCatalogServiceServer) ExportLaptops(*ExportLaptopsRequest, CatalogService_ExportLaptopsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportLaptops not implemented")
+}
+func (*UnimplementedCatalogServiceServer) ImportLaptops(CatalogService_ImportLaptopsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportLaptops not implemented")
+}
+
+func RegisterCatalogServiceServer(s *grpc.Server, srv CatalogServiceServer) {
+	s.RegisterService(&_CatalogService_serviceDesc, srv)
+}
+
+func _CatalogService_ExportLaptops_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportLaptopsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CatalogServiceServer).ExportLaptops(m, &catalogServiceExportLaptopsServer{stream})
+}
+
+type CatalogService_ExportLaptopsServer interface {
+	Send(*ExportLaptopsResponse) error
+	grpc.ServerStream
+}
+
+type catalogServiceExportLaptopsServer struct {
+	grpc.ServerStream
+}
+
+func (x *catalogServiceExportLaptopsServer) Send(m *ExportLaptopsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CatalogService_ImportLaptops_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CatalogServiceServer).ImportLaptops(&catalogServiceImportLaptopsServer{stream})
+}
+
+type CatalogService_ImportLaptopsServer interface {
+	SendAndClose(*ImportLaptopsResponse) error
+	Recv() (*ImportLaptopsRequest, error)
+	grpc.ServerStream
+}
+
+type catalogServiceImportLaptopsServer struct {
+	grpc.ServerStream
+}
+
+func (x *catalogServiceImportLaptopsServer) SendAndClose(m *ImportLaptopsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *catalogServiceImportLaptopsServer) Recv() (*ImportLaptopsRequest, error) {
+	m := new(ImportLaptopsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _CatalogService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "techschool.pcbook.CatalogService",
+	HandlerType: (*CatalogServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportLaptops",
+			Handler:       _CatalogService_ExportLaptops_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportLaptops",
+			Handler:       _CatalogService_ImportLaptops_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "catalog_service.proto",
+}
@@ -0,0 +1,251 @@
+// Package protodiff compares protocol buffer messages field by field and patches them.
+// Messages are compared in their JSON mapping with the field names of the proto file,
+// so a diff is also a JSON Patch (RFC 6902) of the JSON of the message:
+// a oneof like the weight of a laptop that switches from weight_kg to weight_lb is
+// the removal of one field and the addition of the other, repeated fields like the
+// GPUs of a laptop are compared index by index, and timestamps are replaced as a whole.
+package protodiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.techschool.pcbook/serializer"
+	"google.golang.org/protobuf/proto"
+)
+
+// operations of a JSON Patch used by a diff
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Change is an operation of a JSON Patch, Old is the value replaced or removed
+type Change struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+	Old   interface{} `json:"-"`
+}
+
+// Diff is the list of changes turning a message into another one
+type Diff []Change
+
+// Compare returns the changes from old to new, which must have the same type
+func Compare(old, new proto.Message) (Diff, error) {
+	oldName := old.ProtoReflect().Descriptor().FullName()
+	newName := new.ProtoReflect().Descriptor().FullName()
+	if oldName != newName {
+		return nil, fmt.Errorf("cannot compare %s to %s", oldName, newName)
+	}
+
+	oldValue, err := toValue(old)
+	if err != nil {
+		return nil, err
+	}
+
+	newValue, err := toValue(new)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff Diff
+	compareValues("", oldValue, newValue, &diff)
+	return diff, nil
+}
+
+func compareValues(path string, old, new interface{}, diff *Diff) {
+	switch old := old.(type) {
+	case map[string]interface{}:
+		if new, ok := new.(map[string]interface{}); ok {
+			compareObjects(path, old, new, diff)
+			return
+		}
+	case []interface{}:
+		if new, ok := new.([]interface{}); ok {
+			compareArrays(path, old, new, diff)
+			return
+		}
+	default:
+		switch new.(type) {
+		case map[string]interface{}, []interface{}:
+		default:
+			if old == new {
+				return
+			}
+		}
+	}
+
+	*diff = append(*diff, Change{Op: OpReplace, Path: path, Value: new, Old: old})
+}
+
+func compareObjects(path string, old, new map[string]interface{}, diff *Diff) {
+	keys := make([]string, 0, len(old)+len(new))
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path + "/" + escapeKey(key)
+		oldValue, inOld := old[key]
+		newValue, inNew := new[key]
+
+		switch {
+		case !inNew:
+			*diff = append(*diff, Change{Op: OpRemove, Path: keyPath, Old: oldValue})
+		case !inOld:
+			*diff = append(*diff, Change{Op: OpAdd, Path: keyPath, Value: newValue})
+		default:
+			compareValues(keyPath, oldValue, newValue, diff)
+		}
+	}
+}
+
+// compareArrays compares the common elements, then adds the new ones
+// or removes the old ones from the last, so that the indexes stay valid
+func compareArrays(path string, old, new []interface{}, diff *Diff) {
+	for i := 0; i < len(old) && i < len(new); i++ {
+		compareValues(path+"/"+strconv.Itoa(i), old[i], new[i], diff)
+	}
+
+	for i := len(old); i < len(new); i++ {
+		*diff = append(*diff, Change{Op: OpAdd, Path: path + "/" + strconv.Itoa(i), Value: new[i]})
+	}
+
+	for i := len(old) - 1; i >= len(new); i-- {
+		*diff = append(*diff, Change{Op: OpRemove, Path: path + "/" + strconv.Itoa(i), Old: old[i]})
+	}
+}
+
+// String renders the changes as text, one line per change like:
+//
+//	~ price_usd: 1500 -> 2000
+//	+ gpus[1]: {"brand":"AMD","name":"RX 590"}
+//	- weight_kg: 1.5
+func (diff Diff) String() string {
+	var builder strings.Builder
+	for _, change := range diff {
+		name := fieldName(change.Path)
+
+		switch change.Op {
+		case OpAdd:
+			fmt.Fprintf(&builder, "+ %s: %s\n", name, formatValue(change.Value))
+		case OpRemove:
+			fmt.Fprintf(&builder, "- %s: %s\n", name, formatValue(change.Old))
+		default:
+			fmt.Fprintf(&builder, "~ %s: %s -> %s\n", name, formatValue(change.Old), formatValue(change.Value))
+		}
+	}
+
+	return builder.String()
+}
+
+// JSONPatch returns the changes as a JSON Patch document
+func (diff Diff) JSONPatch() ([]byte, error) {
+	if diff == nil {
+		diff = Diff{}
+	}
+
+	return json.Marshal(diff)
+}
+
+// ParseJSONPatch reads a JSON Patch document made of add, remove and replace operations
+func ParseJSONPatch(data []byte) (Diff, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var diff Diff
+	err := decoder.Decode(&diff)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse JSON Patch: %w", err)
+	}
+
+	for i, change := range diff {
+		switch change.Op {
+		case OpAdd, OpReplace, OpRemove:
+		default:
+			return nil, fmt.Errorf("operation %d: unsupported op %q", i, change.Op)
+		}
+		if !strings.HasPrefix(change.Path, "/") {
+			return nil, fmt.Errorf("operation %d: invalid path %q", i, change.Path)
+		}
+	}
+
+	return diff, nil
+}
+
+func toValue(message proto.Message) (interface{}, error) {
+	data, err := serializer.JSONOptions{}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// fieldName turns a path like /gpus/1/brand to gpus[1].brand
+func fieldName(path string) string {
+	var builder strings.Builder
+	for _, key := range splitPath(path) {
+		_, err := strconv.Atoi(key)
+		switch {
+		case err == nil:
+			builder.WriteString("[" + key + "]")
+		case builder.Len() > 0:
+			builder.WriteString("." + key)
+		default:
+			builder.WriteString(key)
+		}
+	}
+
+	return builder.String()
+}
+
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+func escapeKey(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func unescapeKey(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+}
+
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	keys := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, key := range keys {
+		keys[i] = unescapeKey(key)
+	}
+	return keys
+}
@@ -0,0 +1,120 @@
+package protodiff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/protodiff"
+	"gitlab.techschool.pcbook/sample"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCompareApply(t *testing.T) {
+	t.Parallel()
+
+	old := sample.NewLaptop()
+	old.Gpus = append(old.Gpus, sample.NewGPU())
+	old.Weight = &pb.Laptop_WeightKg{WeightKg: 1.5}
+	old.PriceUsd = 1500
+
+	testCases := []struct {
+		name    string
+		update  func(laptop *pb.Laptop)
+		changes []string
+	}{
+		{
+			name:   "equal",
+			update: func(laptop *pb.Laptop) {},
+		},
+		{
+			name: "scalars",
+			update: func(laptop *pb.Laptop) {
+				laptop.PriceUsd = 2000
+				laptop.Cpu.Name = "Core i9"
+			},
+			changes: []string{"~ cpu.name: ", "~ price_usd: 1500 -> 2000\n"},
+		},
+		{
+			name: "oneof",
+			update: func(laptop *pb.Laptop) {
+				laptop.Weight = &pb.Laptop_WeightLb{WeightLb: 3.3}
+			},
+			changes: []string{"- weight_kg: 1.5\n", "+ weight_lb: 3.3\n"},
+		},
+		{
+			name: "repeated",
+			update: func(laptop *pb.Laptop) {
+				laptop.Gpus = laptop.Gpus[:1]
+				laptop.Gpus[0].Brand = "Intel"
+				laptop.Storages = append(laptop.Storages, &pb.Storage{Driver: pb.Storage_SSD})
+			},
+			changes: []string{"~ gpus[0].brand: ", "- gpus[1]: ", "+ storages[2]: {\"driver\":\"SSD\"}\n"},
+		},
+		{
+			name: "cleared",
+			update: func(laptop *pb.Laptop) {
+				laptop.Screen = nil
+				laptop.ReleaseYear = 0
+				laptop.UpdatedAt = nil
+			},
+			changes: []string{"- release_year: ", "- screen: ", "- updated_at: "},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			new := proto.Clone(old).(*pb.Laptop)
+			tc.update(new)
+
+			diff, err := protodiff.Compare(old, new)
+			require.NoError(t, err)
+			require.Len(t, diff, len(tc.changes))
+
+			text := diff.String()
+			for _, change := range tc.changes {
+				require.Contains(t, text, change)
+			}
+
+			patch, err := diff.JSONPatch()
+			require.NoError(t, err)
+			parsed, err := protodiff.ParseJSONPatch(patch)
+			require.NoError(t, err)
+
+			patched := proto.Clone(old).(*pb.Laptop)
+			require.NoError(t, protodiff.Apply(patched, parsed))
+			require.True(t, proto.Equal(new, patched), "%v != %v", new, patched)
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Parallel()
+
+	laptop := sample.NewLaptop()
+	laptop.Gpus = nil
+
+	patches := []string{
+		`[{"op": "replace", "path": "/gpus/0/brand", "value": "AMD"}]`,
+		`[{"op": "remove", "path": "/weight_lb"}]`,
+		`[{"op": "add", "path": "/colour", "value": "silver"}]`,
+		`[{"op": "replace", "path": "/price_usd", "value": "cheap"}]`,
+	}
+	for _, data := range patches {
+		patch, err := protodiff.ParseJSONPatch([]byte(data))
+		require.NoError(t, err)
+
+		patched := proto.Clone(laptop).(*pb.Laptop)
+		require.Error(t, protodiff.Apply(patched, patch), data)
+		require.True(t, proto.Equal(laptop, patched))
+	}
+
+	_, err := protodiff.ParseJSONPatch([]byte(`[{"op": "move", "from": "/name", "path": "/brand"}]`))
+	require.Error(t, err)
+
+	_, err = protodiff.Compare(laptop, sample.NewCpu())
+	require.Error(t, err)
+}
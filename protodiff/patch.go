@@ -0,0 +1,107 @@
+package protodiff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Apply applies the changes to message in order.
+// The message is left unchanged if a change cannot be applied or the result is not a valid message.
+func Apply(message proto.Message, diff Diff) error {
+	value, err := toValue(message)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range diff {
+		value, err = applyChange(value, splitPath(change.Path), change)
+		if err != nil {
+			return fmt.Errorf("cannot %s %s: %w", change.Op, change.Path, err)
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	patched := message.ProtoReflect().New().Interface()
+	err = protojson.Unmarshal(data, patched)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal patched message: %w", err)
+	}
+
+	proto.Reset(message)
+	proto.Merge(message, patched)
+	return nil
+}
+
+// applyChange returns value with the change applied at the path made of keys
+func applyChange(value interface{}, keys []string, change Change) (interface{}, error) {
+	if len(keys) == 0 {
+		if change.Op == OpRemove {
+			return nil, errors.New("cannot remove the whole message")
+		}
+		return change.Value, nil
+	}
+
+	key, rest := keys[0], keys[1:]
+	switch value := value.(type) {
+	case map[string]interface{}:
+		element, ok := value[key]
+		if len(rest) > 0 || change.Op != OpAdd {
+			if !ok {
+				return nil, fmt.Errorf("field %s is not set", key)
+			}
+		}
+
+		if len(rest) == 0 && change.Op == OpRemove {
+			delete(value, key)
+			return value, nil
+		}
+
+		element, err := applyChange(element, rest, change)
+		if err != nil {
+			return nil, err
+		}
+		value[key] = element
+		return value, nil
+	case []interface{}:
+		index := len(value)
+		if key != "-" {
+			var err error
+			index, err = strconv.Atoi(key)
+			if err != nil || index < 0 || index > len(value) {
+				return nil, fmt.Errorf("invalid index %s", key)
+			}
+		}
+
+		if len(rest) == 0 && change.Op == OpAdd {
+			value = append(value, nil)
+			copy(value[index+1:], value[index:])
+			value[index] = change.Value
+			return value, nil
+		}
+		if index == len(value) {
+			return nil, fmt.Errorf("index %s is out of range", key)
+		}
+
+		if len(rest) == 0 && change.Op == OpRemove {
+			return append(value[:index], value[index+1:]...), nil
+		}
+
+		element, err := applyChange(value[index], rest, change)
+		if err != nil {
+			return nil, err
+		}
+		value[index] = element
+		return value, nil
+	default:
+		return nil, fmt.Errorf("%s is not a message or a list", key)
+	}
+}
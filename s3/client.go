@@ -0,0 +1,415 @@
+// Package s3 is a client of the object storage API of Amazon S3, which MinIO and the other
+// S3 compatible stores speak too. Only what the image store needs is implemented: objects are
+// put, in parts as they are written if they are large, read, listed, deleted and downloaded from presigned URLs.
+// Requests are signed with Signature Version 4 and buckets are addressed in the path of the URLs.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinPartSize is the smallest size of the parts of a multipart upload but the last one
+const MinPartSize = 5 << 20
+
+// Config is the configuration of a client
+type Config struct {
+	// Endpoint is the URL of the service like https://s3.eu-west-1.amazonaws.com or http://localhost:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PartSize is the size of the parts of a multipart upload,
+	// objects larger than it are uploaded in parts. It defaults to MinPartSize.
+	PartSize   int
+	HTTPClient *http.Client
+}
+
+// Error is an error returned by the service
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("s3: %s (%d): %s", err.Code, err.StatusCode, err.Message)
+}
+
+// IsNotFound tells if err is returned for an object that doesn't exist
+func IsNotFound(err error) bool {
+	var s3Err *Error
+	return errors.As(err, &s3Err) && (s3Err.Code == "NoSuchKey" || s3Err.StatusCode == http.StatusNotFound)
+}
+
+// Client is a client of a bucket
+type Client struct {
+	endpoint   *url.URL
+	bucket     string
+	partSize   int
+	signer     *signer
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the bucket of the configuration
+func NewClient(config Config) (*Client, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	client := &Client{
+		endpoint: endpoint,
+		bucket:   config.Bucket,
+		partSize: config.PartSize,
+		signer: &signer{
+			accessKey: config.AccessKey,
+			secretKey: config.SecretKey,
+			region:    config.Region,
+		},
+		httpClient: config.HTTPClient,
+	}
+	if client.partSize <= 0 {
+		client.partSize = MinPartSize
+	}
+	if client.signer.region == "" {
+		client.signer.region = "us-east-1"
+	}
+	if client.httpClient == nil {
+		client.httpClient = http.DefaultClient
+	}
+
+	return client, nil
+}
+
+// PutObject writes an object, in parts if it is larger than the part size
+func (client *Client) PutObject(ctx context.Context, key string, contentType string, data []byte) error {
+	writer := client.NewObjectWriter(ctx, key, contentType)
+	_, err := writer.Write(data)
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// ObjectWriter writes an object as its data is written: an object larger than the part size
+// is sent in a multipart upload as soon as every part is written, so that only a part is kept
+// in memory. The object is only written once the writer is closed.
+type ObjectWriter struct {
+	client      *Client
+	ctx         context.Context
+	key         string
+	contentType string
+
+	buffer   []byte
+	uploadID string
+	upload   completeMultipartUpload
+	// err fails the writes after a part cannot be uploaded
+	err error
+}
+
+// NewObjectWriter returns a writer of an object, the requests are sent with ctx
+func (client *Client) NewObjectWriter(ctx context.Context, key string, contentType string) *ObjectWriter {
+	return &ObjectWriter{
+		client:      client,
+		ctx:         ctx,
+		key:         key,
+		contentType: contentType,
+	}
+}
+
+// Write uploads the parts filled by p, the upload is aborted if a part cannot be uploaded
+func (writer *ObjectWriter) Write(p []byte) (int, error) {
+	if writer.err != nil {
+		return 0, writer.err
+	}
+
+	writer.buffer = append(writer.buffer, p...)
+
+	// the last part is kept until the writer is closed, since a part cannot be empty
+	partSize := writer.client.partSize
+	for len(writer.buffer) > partSize {
+		err := writer.uploadPart(writer.buffer[:partSize])
+		if err != nil {
+			writer.Abort()
+			writer.err = err
+			return 0, err
+		}
+		writer.buffer = append(writer.buffer[:0], writer.buffer[partSize:]...)
+	}
+
+	return len(p), nil
+}
+
+// Close writes the object, or completes its multipart upload after uploading its last part
+func (writer *ObjectWriter) Close() error {
+	if writer.err != nil {
+		return writer.err
+	}
+	writer.err = errors.New("s3: object writer is closed")
+
+	if writer.uploadID == "" {
+		header := http.Header{}
+		header.Set("Content-Type", writer.contentType)
+		res, err := writer.client.do(writer.ctx, http.MethodPut, writer.key, nil, header, writer.buffer)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	err := writer.uploadPart(writer.buffer)
+	if err == nil {
+		err = writer.complete()
+	}
+	if err != nil {
+		writer.abortUpload()
+		return err
+	}
+
+	writer.uploadID = ""
+	return nil
+}
+
+// Abort discards what was written and lets the service free the parts already uploaded,
+// it does nothing once the object is written
+func (writer *ObjectWriter) Abort() {
+	writer.buffer = nil
+	if writer.err == nil {
+		writer.err = errors.New("s3: object writer is aborted")
+	}
+
+	if writer.uploadID != "" {
+		writer.abortUpload()
+	}
+}
+
+// uploadPart uploads the next part, after creating the multipart upload for the first one
+func (writer *ObjectWriter) uploadPart(data []byte) error {
+	if writer.uploadID == "" {
+		header := http.Header{}
+		header.Set("Content-Type", writer.contentType)
+
+		var initiated initiateMultipartUploadResult
+		err := writer.client.doXML(writer.ctx, http.MethodPost, writer.key, url.Values{"uploads": {""}}, header, nil, &initiated)
+		if err != nil {
+			return fmt.Errorf("cannot create multipart upload: %w", err)
+		}
+		writer.uploadID = initiated.UploadID
+	}
+
+	partNumber := len(writer.upload.Parts) + 1
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {writer.uploadID},
+	}
+
+	res, err := writer.client.do(writer.ctx, http.MethodPut, writer.key, query, nil, data)
+	if err != nil {
+		return fmt.Errorf("cannot upload part %d: %w", partNumber, err)
+	}
+	res.Body.Close()
+
+	writer.upload.Parts = append(writer.upload.Parts, completedPart{partNumber, res.Header.Get("ETag")})
+	return nil
+}
+
+func (writer *ObjectWriter) complete() error {
+	body, err := xml.Marshal(writer.upload)
+	if err != nil {
+		return err
+	}
+
+	// the service can answer OK and fail later, with an error in the body
+	var completed struct {
+		XMLName xml.Name
+		Error
+	}
+	err = writer.client.doXML(writer.ctx, http.MethodPost, writer.key, url.Values{"uploadId": {writer.uploadID}}, nil, body, &completed)
+	if err == nil && completed.XMLName.Local == "Error" {
+		err = &completed.Error
+	}
+	if err != nil {
+		return fmt.Errorf("cannot complete multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+// abortUpload lets the service free the parts of an upload that failed
+func (writer *ObjectWriter) abortUpload() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := writer.client.do(ctx, http.MethodDelete, writer.key, url.Values{"uploadId": {writer.uploadID}}, nil, nil)
+	if err == nil {
+		res.Body.Close()
+	}
+	writer.uploadID = ""
+}
+
+// GetObject returns the data of an object, IsNotFound tells if it doesn't exist
+func (client *Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	res, err := client.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+// DeleteObject removes an object, the object may not exist
+func (client *Client) DeleteObject(ctx context.Context, key string) error {
+	res, err := client.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// ListObjects returns the keys of the objects starting with prefix in lexicographic order
+func (client *Client) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		var result listBucketResult
+		err := client.doXML(ctx, http.MethodGet, "", query, nil, nil, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+
+		if !result.IsTruncated {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// PresignGetObject returns a URL to download an object without credentials until it expires
+func (client *Client) PresignGetObject(key string, expires time.Duration) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, client.objectURL(key), nil)
+	if err != nil {
+		return "", err
+	}
+
+	return client.signer.presign(req, expires, time.Now()), nil
+}
+
+func (client *Client) objectURL(key string) string {
+	path := strings.TrimSuffix(client.endpoint.Path, "/") + "/" + client.bucket
+	if key != "" {
+		path += "/" + key
+	}
+
+	other := *client.endpoint
+	other.Path = path
+	other.RawPath = uriEncode(path, false)
+	return other.String()
+}
+
+// do sends a signed request and returns the response, or an *Error if the service failed
+func (client *Client) do(
+	ctx context.Context,
+	method string,
+	key string,
+	query url.Values,
+	header http.Header,
+	body []byte,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, client.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		req.URL.RawQuery = canonicalQuery(query)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	client.signer.sign(req, hashHex(body), time.Now())
+
+	res, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 300 {
+		defer res.Body.Close()
+
+		s3Err := &Error{StatusCode: res.StatusCode}
+		data, _ := ioutil.ReadAll(io.LimitReader(res.Body, 64<<10))
+		xml.Unmarshal(data, s3Err)
+		if s3Err.Code == "" {
+			s3Err.Code = http.StatusText(res.StatusCode)
+		}
+		return nil, s3Err
+	}
+
+	return res, nil
+}
+
+// doXML sends a signed request and decodes the XML of the response into result
+func (client *Client) doXML(
+	ctx context.Context,
+	method string,
+	key string,
+	query url.Values,
+	header http.Header,
+	body []byte,
+	result interface{},
+) error {
+	res, err := client.do(ctx, method, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = xml.NewDecoder(res.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("cannot decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/s3"
+	"gitlab.techschool.pcbook/s3/s3test"
+)
+
+func newTestClient(t *testing.T, partSize int) (*s3.Client, *s3test.Server) {
+	server := s3test.NewServer("images", "access-key")
+	t.Cleanup(server.Close)
+
+	client, err := s3.NewClient(s3.Config{
+		Endpoint:  server.URL,
+		Bucket:    "images",
+		AccessKey: "access-key",
+		SecretKey: "secret-key",
+		PartSize:  partSize,
+	})
+	require.NoError(t, err)
+
+	return client, server
+}
+
+func TestClientObjects(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, 0)
+	ctx := context.Background()
+
+	for _, key := range []string{"laptops/1/a", "laptops/1/b", "laptops/2/c", "images/a b.png"} {
+		require.NoError(t, client.PutObject(ctx, key, "image/png", []byte(key)))
+	}
+	require.Equal(t, "image/png", server.Object("images/a b.png").ContentType)
+
+	keys, err := client.ListObjects(ctx, "laptops/1/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"laptops/1/a", "laptops/1/b"}, keys)
+
+	data, err := client.GetObject(ctx, "images/a b.png")
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(data)
+	require.NoError(t, err)
+	require.NoError(t, data.Close())
+	require.Equal(t, "images/a b.png", string(content))
+
+	require.NoError(t, client.DeleteObject(ctx, "images/a b.png"))
+	_, err = client.GetObject(ctx, "images/a b.png")
+	require.True(t, s3.IsNotFound(err), err)
+
+	other, err := s3.NewClient(s3.Config{Endpoint: server.URL, Bucket: "images", AccessKey: "other"})
+	require.NoError(t, err)
+	_, err = other.GetObject(ctx, "laptops/1/a")
+	require.False(t, s3.IsNotFound(err))
+	require.Equal(t, "AccessDenied", err.(*s3.Error).Code)
+}
+
+func TestClientMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, 1024)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), 250)
+	require.NoError(t, client.PutObject(ctx, "large", "application/octet-stream", data))
+
+	object := server.Object("large")
+	require.Equal(t, 3, object.Parts)
+	require.Equal(t, data, object.Data)
+	require.Zero(t, server.Uploads())
+
+	require.NoError(t, client.PutObject(ctx, "small", "application/octet-stream", data[:1024]))
+	require.Zero(t, server.Object("small").Parts)
+}
+
+func TestObjectWriter(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, 1024)
+	ctx := context.Background()
+
+	writer := client.NewObjectWriter(ctx, "streamed", "image/png")
+	data := bytes.Repeat([]byte("0123456789"), 250)
+	for offset := 0; offset < len(data); offset += 100 {
+		_, err := writer.Write(data[offset : offset+100])
+		require.NoError(t, err)
+	}
+	require.Nil(t, server.Object("streamed"))
+	require.Equal(t, 1, server.Uploads())
+
+	require.NoError(t, writer.Close())
+	require.Equal(t, 3, server.Object("streamed").Parts)
+	require.Equal(t, data, server.Object("streamed").Data)
+	require.Equal(t, "image/png", server.Object("streamed").ContentType)
+	writer.Abort()
+	require.NotNil(t, server.Object("streamed"))
+
+	writer = client.NewObjectWriter(ctx, "aborted", "image/png")
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	writer.Abort()
+	require.Zero(t, server.Uploads())
+	require.Error(t, writer.Close())
+	require.Nil(t, server.Object("aborted"))
+}
+
+func TestClientPresignGetObject(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t, 0)
+	require.NoError(t, client.PutObject(context.Background(), "images/1.png", "image/png", []byte("png")))
+
+	url, err := client.PresignGetObject("images/1.png", time.Minute)
+	require.NoError(t, err)
+
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "image/png", res.Header.Get("Content-Type"))
+
+	url, err = client.PresignGetObject("images/1.png", -time.Minute)
+	require.NoError(t, err)
+
+	res, err = http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
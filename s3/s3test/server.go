@@ -0,0 +1,279 @@
+// Package s3test provides an in-memory S3 compatible server for tests
+package s3test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Object is an object stored by a server
+type Object struct {
+	ContentType string
+	Data        []byte
+	// Parts is the number of parts the object was uploaded in, 0 if it was put at once
+	Parts int
+}
+
+type multipartUpload struct {
+	key         string
+	contentType string
+	parts       map[int][]byte
+}
+
+// Server is an S3 compatible server with a single bucket. It checks that the requests
+// are signed with the access key, or presigned for the downloads, but not their signatures.
+type Server struct {
+	*httptest.Server
+	Bucket    string
+	AccessKey string
+
+	mutex   sync.Mutex
+	objects map[string]*Object
+	uploads map[string]*multipartUpload
+	nextID  int
+}
+
+// NewServer starts a server, which the caller should close
+func NewServer(bucket, accessKey string) *Server {
+	server := &Server{
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		objects:   make(map[string]*Object),
+		uploads:   make(map[string]*multipartUpload),
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+// Object returns nil if there is no object with this key
+func (server *Server) Object(key string) *Object {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	object := server.objects[key]
+	if object == nil {
+		return nil
+	}
+
+	other := *object
+	return &other
+}
+
+// Keys returns the keys of the objects stored
+func (server *Server) Keys() []string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	keys := make([]string, 0, len(server.objects))
+	for key := range server.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Uploads returns the number of multipart uploads neither completed nor aborted
+func (server *Server) Uploads() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return len(server.uploads)
+}
+
+func (server *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !server.authorized(r) {
+		writeError(w, http.StatusForbidden, "AccessDenied", "Access Denied.")
+		return
+	}
+
+	bucketPrefix := "/" + server.Bucket
+	if r.URL.Path != bucketPrefix && !strings.HasPrefix(r.URL.Path, bucketPrefix+"/") {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, bucketPrefix), "/")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	query := r.URL.Query()
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		server.listObjects(w, query.Get("prefix"))
+	case r.Method == http.MethodPost && query["uploads"] != nil:
+		server.createUpload(w, key, r.Header.Get("Content-Type"))
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		server.uploadPart(w, query.Get("uploadId"), query.Get("partNumber"), body)
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		server.completeUpload(w, query.Get("uploadId"), body)
+	case r.Method == http.MethodDelete && query.Get("uploadId") != "":
+		delete(server.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		server.objects[key] = &Object{ContentType: r.Header.Get("Content-Type"), Data: body}
+		w.Header().Set("ETag", etag(body))
+	case r.Method == http.MethodGet:
+		object := server.objects[key]
+		if object == nil {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", object.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.Data)))
+		w.Write(object.Data)
+	case r.Method == http.MethodDelete:
+		delete(server.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", "A header you provided implies functionality that is not implemented")
+	}
+}
+
+func (server *Server) authorized(r *http.Request) bool {
+	credential := "Credential=" + server.AccessKey + "/"
+	if strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 "+credential) {
+		return r.Header.Get("X-Amz-Content-Sha256") != "" && r.Header.Get("X-Amz-Date") != ""
+	}
+
+	query := r.URL.Query()
+	if r.Method != http.MethodGet || !strings.HasPrefix("Credential="+query.Get("X-Amz-Credential"), credential) {
+		return false
+	}
+
+	date, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+	expires, _ := strconv.Atoi(query.Get("X-Amz-Expires"))
+	return err == nil && query.Get("X-Amz-Signature") != "" && time.Now().Before(date.Add(time.Duration(expires)*time.Second))
+}
+
+func (server *Server) listObjects(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key  string `xml:"Key"`
+		Size int    `xml:"Size"`
+	}
+	result := struct {
+		XMLName     xml.Name  `xml:"ListBucketResult"`
+		Name        string    `xml:"Name"`
+		Prefix      string    `xml:"Prefix"`
+		IsTruncated bool      `xml:"IsTruncated"`
+		Contents    []content `xml:"Contents"`
+	}{Name: server.Bucket, Prefix: prefix}
+
+	for key, object := range server.objects {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{key, len(object.Data)})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool {
+		return result.Contents[i].Key < result.Contents[j].Key
+	})
+
+	writeXML(w, result)
+}
+
+func (server *Server) createUpload(w http.ResponseWriter, key string, contentType string) {
+	server.nextID++
+	uploadID := fmt.Sprintf("upload-%d", server.nextID)
+	server.uploads[uploadID] = &multipartUpload{key: key, contentType: contentType, parts: make(map[int][]byte)}
+
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string   `xml:"Bucket"`
+		Key      string   `xml:"Key"`
+		UploadID string   `xml:"UploadId"`
+	}{Bucket: server.Bucket, Key: key, UploadID: uploadID})
+}
+
+func (server *Server) uploadPart(w http.ResponseWriter, uploadID string, partNumber string, data []byte) {
+	upload := server.uploads[uploadID]
+	if upload == nil {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+
+	number, err := strconv.Atoi(partNumber)
+	if err != nil || number < 1 || number > 10000 {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000")
+		return
+	}
+
+	upload.parts[number] = data
+	w.Header().Set("ETag", etag(data))
+}
+
+func (server *Server) completeUpload(w http.ResponseWriter, uploadID string, body []byte) {
+	upload := server.uploads[uploadID]
+	if upload == nil {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+
+	var request struct {
+		Parts []struct {
+			PartNumber int    `xml:"PartNumber"`
+			ETag       string `xml:"ETag"`
+		} `xml:"Part"`
+	}
+	err := xml.Unmarshal(body, &request)
+	if err != nil || len(request.Parts) == 0 {
+		writeError(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed")
+		return
+	}
+
+	var data []byte
+	for i, part := range request.Parts {
+		partData, ok := upload.parts[part.PartNumber]
+		if part.PartNumber != i+1 || !ok || etag(partData) != part.ETag {
+			writeError(w, http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found.")
+			return
+		}
+		data = append(data, partData...)
+	}
+
+	server.objects[upload.key] = &Object{ContentType: upload.contentType, Data: data, Parts: len(request.Parts)}
+	delete(server.uploads, uploadID)
+
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string   `xml:"Bucket"`
+		Key     string   `xml:"Key"`
+	}{Bucket: server.Bucket, Key: upload.key})
+}
+
+func writeXML(w http.ResponseWriter, value interface{}) {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code string, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	fmt.Fprintf(w, "%s<Error><Code>%s</Code><Message>%s</Message></Error>", xml.Header, code, message)
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
@@ -0,0 +1,252 @@
+package serializer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// formats of a file with many messages
+const (
+	// FormatNDJSON is one JSON message per line
+	FormatNDJSON = "ndjson"
+	// FormatProtobuf is binary messages, each one after its varint length
+	FormatProtobuf = "protobuf"
+	// FormatCSV is one message per row and one column per nested field, see NewCSVWriter
+	FormatCSV = "csv"
+)
+
+// maxMessageSize limits the size of a single message read from a file
+const maxMessageSize = 16 << 20
+
+// MessageWriter writes many protocol buffer messages to a file
+type MessageWriter interface {
+	Write(message proto.Message) error
+	// Close flushes the messages, it does not close the underlying writer
+	Close() error
+}
+
+// MessageReader reads many protocol buffer messages from a file
+type MessageReader interface {
+	// Read fills message with the next message, it returns io.EOF after the last one.
+	// A *ParseError is returned for a malformed message that can be skipped.
+	Read(message proto.Message) error
+	// Line returns the line, row or record number of the last message read, starting at 1
+	Line() int
+}
+
+// ParseError is a malformed message of a file, the following messages can still be read
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", err.Line, err.Err)
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// FormatFromFilename guesses the format of a file from its extension
+func FormatFromFilename(filename string) (string, error) {
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".ndjson"), strings.HasSuffix(lower, ".jsonl"):
+		return FormatNDJSON, nil
+	case strings.HasSuffix(lower, ".pb"), strings.HasSuffix(lower, ".bin"):
+		return FormatProtobuf, nil
+	case strings.HasSuffix(lower, ".csv"):
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("cannot guess the format of %s", filename)
+	}
+}
+
+// NewMessageWriter returns a writer of the given format
+func NewMessageWriter(format string, w io.Writer) (MessageWriter, error) {
+	switch format {
+	case FormatNDJSON:
+		return NewNDJSONWriter(w), nil
+	case FormatProtobuf:
+		return NewDelimitedWriter(w), nil
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// NewMessageReader returns a reader of the given format
+func NewMessageReader(format string, r io.Reader) (MessageReader, error) {
+	switch format {
+	case FormatNDJSON:
+		return NewNDJSONReader(r), nil
+	case FormatProtobuf:
+		return NewDelimitedReader(r), nil
+	case FormatCSV:
+		return NewCSVReader(r), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// NDJSONWriter writes each message as a line of JSON
+type NDJSONWriter struct {
+	writer     *bufio.Writer
+	marshaller protojson.MarshalOptions
+}
+
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{
+		writer:     bufio.NewWriter(w),
+		marshaller: protojson.MarshalOptions{UseProtoNames: true},
+	}
+}
+
+func (writer *NDJSONWriter) Write(message proto.Message) error {
+	data, err := writer.marshaller.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot marshal proto message to JSON %w", err)
+	}
+
+	_, err = writer.writer.Write(append(data, '\n'))
+	return err
+}
+
+func (writer *NDJSONWriter) Close() error {
+	return writer.writer.Flush()
+}
+
+// NDJSONReader reads a message from each line of JSON, skipping blank lines
+type NDJSONReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	return &NDJSONReader{scanner: scanner}
+}
+
+func (reader *NDJSONReader) Read(message proto.Message) error {
+	for reader.scanner.Scan() {
+		reader.line++
+		text := strings.TrimSpace(reader.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		proto.Reset(message)
+		err := protojson.Unmarshal([]byte(text), message)
+		if err != nil {
+			return &ParseError{reader.line, err}
+		}
+		return nil
+	}
+
+	err := reader.scanner.Err()
+	if err != nil {
+		return fmt.Errorf("line %d: %w", reader.line+1, err)
+	}
+	return io.EOF
+}
+
+func (reader *NDJSONReader) Line() int {
+	return reader.line
+}
+
+// DelimitedWriter writes each message in binary after its length as a varint
+type DelimitedWriter struct {
+	writer *bufio.Writer
+}
+
+func NewDelimitedWriter(w io.Writer) *DelimitedWriter {
+	return &DelimitedWriter{bufio.NewWriter(w)}
+}
+
+func (writer *DelimitedWriter) Write(message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot marshal proto message to binary %w", err)
+	}
+
+	return writer.WriteRecord(data)
+}
+
+// WriteRecord writes data after its length as a varint
+func (writer *DelimitedWriter) WriteRecord(data []byte) error {
+	_, err := writer.writer.Write(appendUvarint(nil, uint64(len(data))))
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.writer.Write(data)
+	return err
+}
+
+func (writer *DelimitedWriter) Close() error {
+	return writer.writer.Flush()
+}
+
+// DelimitedReader reads messages written by a DelimitedWriter
+type DelimitedReader struct {
+	reader *bufio.Reader
+	record int
+}
+
+func NewDelimitedReader(r io.Reader) *DelimitedReader {
+	return &DelimitedReader{reader: bufio.NewReader(r)}
+}
+
+func (reader *DelimitedReader) Read(message proto.Message) error {
+	data, err := reader.ReadRecord()
+	if err != nil {
+		return err
+	}
+
+	err = proto.Unmarshal(data, message)
+	if err != nil {
+		return &ParseError{reader.record, err}
+	}
+	return nil
+}
+
+// ReadRecord returns the data of the next record, it returns io.EOF after the last one.
+// The length is checked against maxMessageSize before allocating the data.
+func (reader *DelimitedReader) ReadRecord() ([]byte, error) {
+	size, err := binary.ReadUvarint(reader.reader)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	reader.record++
+	if err != nil {
+		return nil, fmt.Errorf("record %d: cannot read size %w", reader.record, err)
+	}
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("record %d: message of %d bytes is too large", reader.record, size)
+	}
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(reader.reader, data)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("record %d: %w", reader.record, err)
+	}
+
+	return data, nil
+}
+
+func (reader *DelimitedReader) Line() int {
+	return reader.record
+}
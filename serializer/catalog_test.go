@@ -0,0 +1,139 @@
+package serializer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/serializer"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMessageWriterReader(t *testing.T) {
+	t.Parallel()
+
+	laptop1 := sample.NewLaptop()
+	laptop1.Gpus = append(laptop1.Gpus, sample.NewGPU())
+
+	laptop2 := sample.NewLaptop()
+	laptop2.Weight = &pb.Laptop_WeightLb{WeightLb: 4.5}
+	laptop2.Storages = nil
+
+	laptops := []*pb.Laptop{laptop1, laptop2, {Id: "empty"}}
+
+	formats := []string{serializer.FormatNDJSON, serializer.FormatProtobuf, serializer.FormatCSV}
+	for _, format := range formats {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			var buffer bytes.Buffer
+			writer, err := serializer.NewMessageWriter(format, &buffer)
+			require.NoError(t, err)
+			for _, laptop := range laptops {
+				require.NoError(t, writer.Write(laptop))
+			}
+			require.NoError(t, writer.Close())
+
+			reader, err := serializer.NewMessageReader(format, &buffer)
+			require.NoError(t, err)
+			for _, laptop := range laptops {
+				other := &pb.Laptop{}
+				require.NoError(t, reader.Read(other))
+				require.True(t, proto.Equal(laptop, other), "%v != %v", laptop, other)
+			}
+			require.Equal(t, io.EOF, reader.Read(&pb.Laptop{}))
+		})
+	}
+}
+
+func TestMessageReaderParseError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		format string
+		data   string
+		line   int
+	}{
+		{
+			name:   "ndjson",
+			format: serializer.FormatNDJSON,
+			data:   "{\"id\": \"a\"}\n\n{\"id\": 1}\n{\"id\": \"b\"}\n",
+			line:   3,
+		},
+		{
+			name:   "csv",
+			format: serializer.FormatCSV,
+			data:   "id,cpu.brand\na,Intel\nb,\nc,\n",
+			line:   -1,
+		},
+		{
+			name:   "csv unknown enum",
+			format: serializer.FormatCSV,
+			data:   "id,Keyboard.layout\na,QWERTY\nb,DVORAK\nc,AZERTY\n",
+			line:   3,
+		},
+		{
+			name:   "csv unknown column",
+			format: serializer.FormatCSV,
+			data:   "id,gpus.x.brand\na,\nb,Nvidia\nc,\n",
+			line:   3,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader, err := serializer.NewMessageReader(tc.format, strings.NewReader(tc.data))
+			require.NoError(t, err)
+
+			var ids []string
+			var parseErr *serializer.ParseError
+			for {
+				laptop := &pb.Laptop{}
+				err := reader.Read(laptop)
+				if err == io.EOF {
+					break
+				}
+				if errors.As(err, &parseErr) {
+					require.Equal(t, reader.Line(), parseErr.Line)
+					continue
+				}
+				require.NoError(t, err)
+				ids = append(ids, laptop.GetId())
+			}
+
+			if tc.line < 0 {
+				require.Nil(t, parseErr)
+				require.Len(t, ids, 3)
+				return
+			}
+			require.NotNil(t, parseErr)
+			require.Equal(t, tc.line, parseErr.Line)
+			require.Len(t, ids, 2)
+		})
+	}
+}
+
+func TestFormatFromFilename(t *testing.T) {
+	t.Parallel()
+
+	format, err := serializer.FormatFromFilename("laptops.JSONL")
+	require.NoError(t, err)
+	require.Equal(t, serializer.FormatNDJSON, format)
+
+	format, err = serializer.FormatFromFilename("laptops.csv")
+	require.NoError(t, err)
+	require.Equal(t, serializer.FormatCSV, format)
+
+	_, err = serializer.FormatFromFilename("laptops.txt")
+	require.Error(t, err)
+}
@@ -0,0 +1,337 @@
+package serializer
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxCSVListIndex limits the index of a repeated field column read from a file
+const maxCSVListIndex = 1000
+
+const timestampName = "google.protobuf.Timestamp"
+
+// CSVWriter writes each message as a row with one column per nested field,
+// named by the path of the field like cpu.name, and by the index of repeated
+// fields like gpus.0.brand. Enums are written by name and timestamps in RFC 3339.
+// The header needs the columns of all messages, so rows are written on Close.
+type CSVWriter struct {
+	writer  *csv.Writer
+	rows    []map[string]string
+	columns map[string][]int
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{
+		writer:  csv.NewWriter(w),
+		columns: make(map[string][]int),
+	}
+}
+
+func (writer *CSVWriter) Write(message proto.Message) error {
+	row := make(map[string]string)
+	writer.flattenMessage("", nil, message.ProtoReflect(), row)
+	writer.rows = append(writer.rows, row)
+	return nil
+}
+
+func (writer *CSVWriter) Close() error {
+	header := make([]string, 0, len(writer.columns))
+	for column := range writer.columns {
+		header = append(header, column)
+	}
+
+	// order columns like the fields of the message
+	sort.Slice(header, func(i, j int) bool {
+		a, b := writer.columns[header[i]], writer.columns[header[j]]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	err := writer.writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	record := make([]string, len(header))
+	for _, row := range writer.rows {
+		for i, column := range header {
+			record[i] = row[column]
+		}
+
+		err := writer.writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.rows = nil
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+// flattenMessage adds a column to row for every scalar field of message.
+// The sort key of a column is the position of each field and index in its path.
+func (writer *CSVWriter) flattenMessage(prefix string, key []int, message protoreflect.Message, row map[string]string) {
+	if message.Descriptor().FullName() == timestampName {
+		writer.addColumn(prefix, key, formatTimestamp(message), row)
+		return
+	}
+
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := string(field.Name())
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fieldKey := append(append([]int{}, key...), i)
+
+		switch {
+		case field.IsMap():
+			// not supported by the flat format
+			continue
+		case field.IsList():
+			list := message.Get(field).List()
+			for j := 0; j < list.Len(); j++ {
+				elementName := name + "." + strconv.Itoa(j)
+				elementKey := append(append([]int{}, fieldKey...), j)
+				if field.Kind() == protoreflect.MessageKind {
+					writer.flattenMessage(elementName, elementKey, list.Get(j).Message(), row)
+				} else {
+					writer.addColumn(elementName, elementKey, formatValue(field, list.Get(j)), row)
+				}
+			}
+		case field.Kind() == protoreflect.MessageKind:
+			if message.Has(field) {
+				writer.flattenMessage(name, fieldKey, message.Get(field).Message(), row)
+			}
+		case field.ContainingOneof() != nil && !message.Has(field):
+			continue
+		default:
+			writer.addColumn(name, fieldKey, formatValue(field, message.Get(field)), row)
+		}
+	}
+}
+
+func (writer *CSVWriter) addColumn(name string, key []int, value string, row map[string]string) {
+	writer.columns[name] = key
+	row[name] = value
+}
+
+// CSVReader reads messages written by a CSVWriter, empty cells are left unset
+type CSVReader struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+func NewCSVReader(r io.Reader) *CSVReader {
+	return &CSVReader{reader: csv.NewReader(r)}
+}
+
+func (reader *CSVReader) Read(message proto.Message) error {
+	if reader.header == nil {
+		header, err := reader.reader.Read()
+		if err != nil {
+			return err
+		}
+
+		reader.row++
+		reader.header = header
+	}
+
+	record, err := reader.reader.Read()
+	if err == io.EOF {
+		return io.EOF
+	}
+	reader.row++
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &ParseError{reader.row, parseErr.Err}
+	}
+	if err != nil {
+		return fmt.Errorf("row %d: %w", reader.row, err)
+	}
+
+	proto.Reset(message)
+	for i, column := range reader.header {
+		if record[i] == "" {
+			continue
+		}
+
+		err := setField(message.ProtoReflect(), strings.Split(column, "."), record[i])
+		if err != nil {
+			return &ParseError{reader.row, fmt.Errorf("column %s: %w", column, err)}
+		}
+	}
+
+	return nil
+}
+
+// Line returns the row of the last message read, the header is row 1
+func (reader *CSVReader) Line() int {
+	return reader.row
+}
+
+func setField(message protoreflect.Message, path []string, value string) error {
+	field := message.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if field == nil {
+		return fmt.Errorf("unknown field %s", path[0])
+	}
+	rest := path[1:]
+
+	switch {
+	case field.IsMap():
+		return errors.New("map fields are not supported")
+	case field.IsList():
+		if len(rest) == 0 {
+			return errors.New("missing index of repeated field")
+		}
+
+		index, err := strconv.Atoi(rest[0])
+		if err != nil || index < 0 || index > maxCSVListIndex {
+			return fmt.Errorf("invalid index %s", rest[0])
+		}
+
+		list := message.Mutable(field).List()
+		for list.Len() <= index {
+			list.Append(list.NewElement())
+		}
+
+		if field.Kind() == protoreflect.MessageKind {
+			return setMessage(list.Get(index).Message(), rest[1:], value)
+		}
+		if len(rest) != 1 {
+			return errors.New("unexpected path after scalar field")
+		}
+
+		parsed, err := parseValue(field, value)
+		if err != nil {
+			return err
+		}
+		list.Set(index, parsed)
+		return nil
+	case field.Kind() == protoreflect.MessageKind:
+		return setMessage(message.Mutable(field).Message(), rest, value)
+	default:
+		if len(rest) != 0 {
+			return errors.New("unexpected path after scalar field")
+		}
+
+		parsed, err := parseValue(field, value)
+		if err != nil {
+			return err
+		}
+		message.Set(field, parsed)
+		return nil
+	}
+}
+
+func setMessage(message protoreflect.Message, path []string, value string) error {
+	if len(path) > 0 {
+		return setField(message, path, value)
+	}
+	if message.Descriptor().FullName() != timestampName {
+		return errors.New("missing field of message")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return err
+	}
+
+	fields := message.Descriptor().Fields()
+	message.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+	message.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+	return nil
+}
+
+func formatTimestamp(message protoreflect.Message) string {
+	fields := message.Descriptor().Fields()
+	seconds := message.Get(fields.ByName("seconds")).Int()
+	nanos := message.Get(fields.ByName("nanos")).Int()
+	return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+}
+
+func formatValue(field protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(value.Bool())
+	case protoreflect.EnumKind:
+		enumValue := field.Enum().Values().ByNumber(value.Enum())
+		if enumValue == nil {
+			return strconv.Itoa(int(value.Enum()))
+		}
+		return string(enumValue.Name())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(value.Int(), 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(value.Uint(), 10)
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 32)
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 64)
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(value.Bytes())
+	default:
+		return value.String()
+	}
+}
+
+func parseValue(field protoreflect.FieldDescriptor, text string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		value, err := strconv.ParseBool(text)
+		return protoreflect.ValueOfBool(value), err
+	case protoreflect.EnumKind:
+		enumValue := field.Enum().Values().ByName(protoreflect.Name(text))
+		if enumValue != nil {
+			return protoreflect.ValueOfEnum(enumValue.Number()), nil
+		}
+
+		number, err := strconv.ParseInt(text, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown %s %q", field.Enum().Name(), text)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(number)), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		value, err := strconv.ParseInt(text, 10, 32)
+		return protoreflect.ValueOfInt32(int32(value)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		value, err := strconv.ParseInt(text, 10, 64)
+		return protoreflect.ValueOfInt64(value), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		value, err := strconv.ParseUint(text, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(value)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		value, err := strconv.ParseUint(text, 10, 64)
+		return protoreflect.ValueOfUint64(value), err
+	case protoreflect.FloatKind:
+		value, err := strconv.ParseFloat(text, 32)
+		return protoreflect.ValueOfFloat32(float32(value)), err
+	case protoreflect.DoubleKind:
+		value, err := strconv.ParseFloat(text, 64)
+		return protoreflect.ValueOfFloat64(value), err
+	case protoreflect.BytesKind:
+		value, err := base64.StdEncoding.DecodeString(text)
+		return protoreflect.ValueOfBytes(value), err
+	default:
+		return protoreflect.ValueOfString(text), nil
+	}
+}
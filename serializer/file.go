@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/proto"
 )
 
 // WriteProtobufToBinaryFile writes a protocol buffer message to binary file
@@ -49,3 +49,73 @@ func WriteProtobufToJsonFile(message proto.Message, filename string) error {
 	}
 	return nil
 }
+
+// WriteProtobufToYAMLFile writes a protocol buffer message to YAML file
+func WriteProtobufToYAMLFile(message proto.Message, filename string) error {
+	data, err := ProtobufToYAML(message)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, []byte(data), 0644)
+}
+
+// ReadProtobufFromYAMLFile reads a protocol buffer message from YAML file
+func ReadProtobufFromYAMLFile(filename string, message proto.Message) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return YAMLToProtobuf(string(data), message)
+}
+
+// WriteProtobufToTOMLFile writes a protocol buffer message to TOML file
+func WriteProtobufToTOMLFile(message proto.Message, filename string) error {
+	data, err := ProtobufToTOML(message)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, []byte(data), 0644)
+}
+
+// ReadProtobufFromTOMLFile reads a protocol buffer message from TOML file
+func ReadProtobufFromTOMLFile(filename string, message proto.Message) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return TOMLToProtobuf(string(data), message)
+}
+
+// ReadProtobufFromJsonFile reads a protocol buffer message from JSON file
+func ReadProtobufFromJsonFile(filename string, message proto.Message) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return JSONToProtobuf(string(data), message)
+}
+
+// WriteProtobufToTextFile writes a protocol buffer message to a file in the text format of protoc
+func WriteProtobufToTextFile(message proto.Message, filename string) error {
+	data, err := ProtobufToText(message)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, []byte(data), 0644)
+}
+
+// ReadProtobufFromTextFile reads a protocol buffer message from a file in the text format of protoc
+func ReadProtobufFromTextFile(filename string, message proto.Message) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return TextToProtobuf(string(data), message)
+}
@@ -1,6 +1,9 @@
 package serializer_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -12,8 +15,9 @@ import (
 
 func TestFileSerializer(t *testing.T) {
 	t.Parallel()
-	binaryFile := "../tmp/laptop.bin"
-	jsonFile := "../tmp/laptop.json"
+	testFolder := newTestFolder(t)
+	binaryFile := filepath.Join(testFolder, "laptop.bin")
+	jsonFile := filepath.Join(testFolder, "laptop.json")
 	laptop1 := sample.NewLaptop()
 	err := serializer.WriteProtobufToBinaryFile(laptop1, binaryFile)
 	require.NoError(t, err)
@@ -25,4 +29,50 @@ func TestFileSerializer(t *testing.T) {
 
 	err = serializer.WriteProtobufToJsonFile(laptop1, jsonFile)
 	require.NoError(t, err)
+
+	laptop3 := &pb.Laptop{}
+	err = serializer.ReadProtobufFromJsonFile(jsonFile, laptop3)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(laptop1, laptop3))
+}
+
+func TestTextFileSerializer(t *testing.T) {
+	t.Parallel()
+	testFolder := newTestFolder(t)
+	yamlFile := filepath.Join(testFolder, "laptop.yaml")
+	tomlFile := filepath.Join(testFolder, "laptop.toml")
+	textFile := filepath.Join(testFolder, "laptop.txt")
+	laptop1 := sample.NewLaptop()
+
+	err := serializer.WriteProtobufToYAMLFile(laptop1, yamlFile)
+	require.NoError(t, err)
+
+	laptop2 := &pb.Laptop{}
+	err = serializer.ReadProtobufFromYAMLFile(yamlFile, laptop2)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(laptop1, laptop2))
+
+	err = serializer.WriteProtobufToTOMLFile(laptop1, tomlFile)
+	require.NoError(t, err)
+
+	laptop3 := &pb.Laptop{}
+	err = serializer.ReadProtobufFromTOMLFile(tomlFile, laptop3)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(laptop1, laptop3))
+
+	err = serializer.WriteProtobufToTextFile(laptop1, textFile)
+	require.NoError(t, err)
+
+	laptop4 := &pb.Laptop{}
+	err = serializer.ReadProtobufFromTextFile(textFile, laptop4)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(laptop1, laptop4))
+}
+
+func newTestFolder(t *testing.T) string {
+	testFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(testFolder) })
+
+	return testFolder
 }
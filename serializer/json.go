@@ -1,17 +1,128 @@
 package serializer
 
 import (
-	"github.com/golang/protobuf/jsonpb"
-	"github.com/golang/protobuf/proto"
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
+// JSONOptions controls how protocol buffer messages are converted to and from JSON
+type JSONOptions struct {
+	// CamelCase writes the JSON names of the fields like priceUsd instead of their proto names like price_usd.
+	// Both names are always accepted when reading.
+	CamelCase bool
+	// EnumsAsInts writes enums by number instead of by name
+	EnumsAsInts bool
+	// EmitDefaults writes the fields that are not set with their default value
+	EmitDefaults bool
+	// Multiline writes each field on its own line, indented by Indent or two spaces
+	Multiline bool
+	Indent    string
+	// DiscardUnknown ignores unknown fields when reading instead of failing
+	DiscardUnknown bool
+}
+
+// DefaultJSONOptions are used by ProtobufToJSON and JSONToProtobuf
+var DefaultJSONOptions = JSONOptions{
+	EmitDefaults: true,
+	Multiline:    true,
+	Indent:       " ",
+}
+
+// ProtobufToJSON converts a protocol buffer message to JSON with DefaultJSONOptions
 func ProtobufToJSON(message proto.Message) (string, error) {
-	marshaller := jsonpb.Marshaler{
-		EnumsAsInts:  false,
-		EmitDefaults: true,
-		Indent:       " ",
-		OrigName:     true,
+	return DefaultJSONOptions.Marshal(message)
+}
+
+// JSONToProtobuf converts JSON to a protocol buffer message with DefaultJSONOptions
+func JSONToProtobuf(data string, message proto.Message) error {
+	return DefaultJSONOptions.Unmarshal(data, message)
+}
+
+// Marshal converts a protocol buffer message to JSON
+func (options JSONOptions) Marshal(message proto.Message) (string, error) {
+	marshaller := protojson.MarshalOptions{
+		UseProtoNames:   !options.CamelCase,
+		UseEnumNumbers:  options.EnumsAsInts,
+		EmitUnpopulated: options.EmitDefaults,
+		Multiline:       options.Multiline,
+		Indent:          options.Indent,
+	}
+
+	data, err := marshaller.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal proto message to JSON %w", err)
+	}
+
+	return string(data), nil
+}
+
+// Unmarshal converts JSON to a protocol buffer message
+func (options JSONOptions) Unmarshal(data string, message proto.Message) error {
+	unmarshaller := protojson.UnmarshalOptions{DiscardUnknown: options.DiscardUnknown}
+
+	err := unmarshaller.Unmarshal([]byte(data), message)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal JSON to proto message %w", err)
 	}
 
-	return marshaller.MarshalToString(message)
+	return nil
+}
+
+// protobufToValue converts message to the maps, slices and scalars of its JSON mapping,
+// numbers are int64 when they are integers and float64 otherwise
+func protobufToValue(message proto.Message) (interface{}, error) {
+	data, err := JSONOptions{}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertNumbers(value), nil
+}
+
+// valueToProtobuf fills message from a value decoded from a format with the same mapping as JSON
+func valueToProtobuf(value interface{}, message proto.Message) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return protojson.Unmarshal(data, message)
+}
+
+func convertNumbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, element := range value {
+			value[key] = convertNumbers(element)
+		}
+		return value
+	case []interface{}:
+		for i, element := range value {
+			value[i] = convertNumbers(element)
+		}
+		return value
+	case json.Number:
+		integer, err := value.Int64()
+		if err == nil {
+			return integer
+		}
+
+		float, _ := value.Float64()
+		return float
+	default:
+		return value
+	}
 }
@@ -0,0 +1,101 @@
+package serializer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/serializer"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestJSONOptions(t *testing.T) {
+	t.Parallel()
+
+	laptop := sample.NewLaptop()
+	laptop.Keyboard.Layout = pb.Keyboard_AZERTY
+	laptop.Keyboard.Backlit = false
+
+	testCases := []struct {
+		name     string
+		options  serializer.JSONOptions
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "default",
+			options:  serializer.DefaultJSONOptions,
+			contains: []string{`"price_usd":`, `"AZERTY"`, `"backlit":`, "\n"},
+		},
+		{
+			name:     "compact",
+			options:  serializer.JSONOptions{},
+			contains: []string{`"price_usd":`, `"AZERTY"`},
+			excludes: []string{`"backlit":`, "\n"},
+		},
+		{
+			name:     "camel case",
+			options:  serializer.JSONOptions{CamelCase: true},
+			contains: []string{`"priceUsd":`},
+			excludes: []string{`"price_usd":`},
+		},
+		{
+			name:     "enums as ints",
+			options:  serializer.JSONOptions{EnumsAsInts: true},
+			contains: []string{`"layout":3`},
+			excludes: []string{`"AZERTY"`},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := tc.options.Marshal(laptop)
+			require.NoError(t, err)
+
+			// remove the spaces protojson randomly adds after colons
+			compact := strings.ReplaceAll(data, ": ", ":")
+			for _, text := range tc.contains {
+				require.Contains(t, compact, text)
+			}
+			for _, text := range tc.excludes {
+				require.NotContains(t, compact, text)
+			}
+
+			other := &pb.Laptop{}
+			require.NoError(t, tc.options.Unmarshal(data, other))
+			require.True(t, proto.Equal(laptop, other))
+		})
+	}
+}
+
+func TestJSONToProtobufUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	data := `{"brand": "Apple", "colour": "silver"}`
+	require.Error(t, serializer.JSONToProtobuf(data, &pb.Laptop{}))
+
+	laptop := &pb.Laptop{}
+	options := serializer.JSONOptions{DiscardUnknown: true}
+	require.NoError(t, options.Unmarshal(data, laptop))
+	require.Equal(t, "Apple", laptop.GetBrand())
+}
+
+func TestTextToProtobuf(t *testing.T) {
+	t.Parallel()
+
+	laptop := sample.NewLaptop()
+	data, err := serializer.ProtobufToText(laptop)
+	require.NoError(t, err)
+	require.Contains(t, data, "gpus:")
+
+	other := &pb.Laptop{}
+	require.NoError(t, serializer.TextToProtobuf(data, other))
+	require.True(t, proto.Equal(laptop, other))
+
+	require.Error(t, serializer.TextToProtobuf("brand: 1", &pb.Laptop{}))
+}
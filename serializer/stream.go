@@ -0,0 +1,255 @@
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/proto"
+)
+
+// Compression of the records of a stream
+type Compression byte
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZstd
+)
+
+func (compression Compression) String() string {
+	switch compression {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("compression(%d)", byte(compression))
+	}
+}
+
+// streamMagic starts every stream, its last byte is the version of the format
+var streamMagic = []byte("PCBK\x02")
+
+// maxMessageNameSize limits the size of the message type name read from a header
+const maxMessageNameSize = 1024
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrChecksumMismatch is returned for a record whose data does not match its checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Writer writes a stream of messages of a single type.
+// The stream starts with a header made of a magic number, the compression
+// and the full name of the message type. Then each record is written by a
+// DelimitedWriter as a binary message followed by the CRC-32C of the record
+// length and the message, all compressed.
+type Writer struct {
+	buffer      *bufio.Writer
+	compressor  io.WriteCloser
+	records     *DelimitedWriter
+	messageName string
+}
+
+// NewWriter writes the header of a stream of messages of the same type as prototype
+func NewWriter(w io.Writer, prototype proto.Message, compression Compression) (*Writer, error) {
+	messageName := fullName(prototype)
+	if messageName == "" {
+		return nil, errors.New("unknown message type")
+	}
+
+	buffer := bufio.NewWriter(w)
+	header := append([]byte{}, streamMagic...)
+	header = append(header, byte(compression))
+	header = appendUvarint(header, uint64(len(messageName)))
+	header = append(header, messageName...)
+
+	_, err := buffer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &Writer{buffer: buffer, messageName: messageName}
+	switch compression {
+	case CompressionNone:
+	case CompressionGzip:
+		writer.compressor = gzip.NewWriter(buffer)
+	case CompressionZstd:
+		writer.compressor, err = zstd.NewWriter(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create zstd writer %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown compression %s", compression)
+	}
+	if writer.compressor != nil {
+		writer.records = NewDelimitedWriter(writer.compressor)
+	} else {
+		writer.records = NewDelimitedWriter(buffer)
+	}
+
+	return writer, nil
+}
+
+func (writer *Writer) Write(message proto.Message) error {
+	messageName := fullName(message)
+	if messageName != writer.messageName {
+		return fmt.Errorf("cannot write %s to a stream of %s", messageName, writer.messageName)
+	}
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot marshal proto message to binary %w", err)
+	}
+
+	record := append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(record[len(data):], recordChecksum(record))
+	return writer.records.WriteRecord(record)
+}
+
+// Close ends the compressed records and flushes them, it does not close the underlying writer
+func (writer *Writer) Close() error {
+	err := writer.records.Close()
+	if err != nil {
+		return err
+	}
+
+	if writer.compressor != nil {
+		err := writer.compressor.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.buffer.Flush()
+}
+
+// Reader reads the messages of a stream written by a Writer
+type Reader struct {
+	records      *DelimitedReader
+	decompressor func()
+	messageName  string
+}
+
+// NewReader reads the header of a stream
+func NewReader(r io.Reader) (*Reader, error) {
+	buffer := bufio.NewReader(r)
+
+	magic := make([]byte, len(streamMagic))
+	_, err := io.ReadFull(buffer, magic)
+	if err != nil || !bytes.Equal(magic, streamMagic) {
+		return nil, errors.New("not a stream of messages")
+	}
+
+	compression, err := buffer.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read compression %w", err)
+	}
+
+	size, err := binary.ReadUvarint(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read message name %w", err)
+	}
+	if size > maxMessageNameSize {
+		return nil, fmt.Errorf("message name of %d bytes is too large", size)
+	}
+
+	messageName := make([]byte, size)
+	_, err = io.ReadFull(buffer, messageName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read message name %w", err)
+	}
+
+	reader := &Reader{messageName: string(messageName)}
+	switch Compression(compression) {
+	case CompressionNone:
+		reader.records = NewDelimitedReader(buffer)
+	case CompressionGzip:
+		decompressor, err := gzip.NewReader(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create gzip reader %w", err)
+		}
+		reader.records = NewDelimitedReader(decompressor)
+	case CompressionZstd:
+		decompressor, err := zstd.NewReader(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create zstd reader %w", err)
+		}
+		reader.records = NewDelimitedReader(decompressor)
+		reader.decompressor = decompressor.Close
+	default:
+		return nil, fmt.Errorf("unknown compression %s", Compression(compression))
+	}
+
+	return reader, nil
+}
+
+// MessageName returns the full name of the type of the messages of the stream
+func (reader *Reader) MessageName() string {
+	return reader.messageName
+}
+
+// Read fills message with the next record, it returns io.EOF after the last one.
+// A *ParseError wrapping ErrChecksumMismatch is returned for a corrupted record,
+// the following records can still be read.
+func (reader *Reader) Read(message proto.Message) error {
+	messageName := fullName(message)
+	if messageName != reader.messageName {
+		return fmt.Errorf("cannot read %s from a stream of %s", messageName, reader.messageName)
+	}
+
+	record, err := reader.records.ReadRecord()
+	if err != nil {
+		return err
+	}
+
+	size := len(record) - 4
+	if size < 0 || recordChecksum(record) != binary.BigEndian.Uint32(record[size:]) {
+		return &ParseError{reader.Line(), ErrChecksumMismatch}
+	}
+
+	err = proto.Unmarshal(record[:size], message)
+	if err != nil {
+		return &ParseError{reader.Line(), err}
+	}
+	return nil
+}
+
+// Line returns the number of the last record read, starting at 1
+func (reader *Reader) Line() int {
+	return reader.records.Line()
+}
+
+// Close releases the decompressor, it does not close the underlying reader
+func (reader *Reader) Close() error {
+	if reader.decompressor != nil {
+		reader.decompressor()
+	}
+	return nil
+}
+
+func appendUvarint(data []byte, value uint64) []byte {
+	varint := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(varint, value)
+	return append(data, varint[:n]...)
+}
+
+// recordChecksum returns the CRC-32C of the length of a record and of its message,
+// a record ending with the 4 bytes of the checksum.
+// Covering the length detects a corrupted length that still falls on a valid record.
+func recordChecksum(record []byte) uint32 {
+	checksum := crc32.Checksum(appendUvarint(nil, uint64(len(record))), crcTable)
+	return crc32.Update(checksum, crcTable, record[:len(record)-4])
+}
+
+func fullName(message proto.Message) string {
+	return string(message.ProtoReflect().Descriptor().FullName())
+}
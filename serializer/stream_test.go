@@ -0,0 +1,132 @@
+package serializer_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/serializer"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestStreamWriterReader(t *testing.T) {
+	t.Parallel()
+
+	laptops := []*pb.Laptop{sample.NewLaptop(), sample.NewLaptop(), {}, sample.NewLaptop()}
+
+	compressions := []serializer.Compression{
+		serializer.CompressionNone,
+		serializer.CompressionGzip,
+		serializer.CompressionZstd,
+	}
+	for _, compression := range compressions {
+		compression := compression
+		t.Run(compression.String(), func(t *testing.T) {
+			t.Parallel()
+
+			var buffer bytes.Buffer
+			writer, err := serializer.NewWriter(&buffer, &pb.Laptop{}, compression)
+			require.NoError(t, err)
+			for _, laptop := range laptops {
+				require.NoError(t, writer.Write(laptop))
+			}
+			require.Error(t, writer.Write(sample.NewCpu()))
+			require.NoError(t, writer.Close())
+
+			reader, err := serializer.NewReader(&buffer)
+			require.NoError(t, err)
+			defer reader.Close()
+			require.Equal(t, "techschool.pcbook.Laptop", reader.MessageName())
+			require.Error(t, reader.Read(&pb.CPU{}))
+
+			for i, laptop := range laptops {
+				other := &pb.Laptop{}
+				require.NoError(t, reader.Read(other))
+				require.Equal(t, i+1, reader.Line())
+				require.True(t, proto.Equal(laptop, other), "%v != %v", laptop, other)
+			}
+			require.Equal(t, io.EOF, reader.Read(&pb.Laptop{}))
+		})
+	}
+}
+
+func TestStreamReaderCorruption(t *testing.T) {
+	t.Parallel()
+
+	laptop1 := sample.NewLaptop()
+	laptop2 := sample.NewLaptop()
+
+	var buffer bytes.Buffer
+	writer, err := serializer.NewWriter(&buffer, &pb.Laptop{}, serializer.CompressionNone)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(laptop1))
+	require.NoError(t, writer.Write(laptop2))
+	require.NoError(t, writer.Close())
+
+	// flip a byte of the ID of the first laptop
+	data := buffer.Bytes()
+	index := bytes.Index(data, []byte(laptop1.GetId()))
+	require.True(t, index > 0)
+	data[index] ^= 0xff
+
+	reader, err := serializer.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	err = reader.Read(&pb.Laptop{})
+	var parseErr *serializer.ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, 1, parseErr.Line)
+	require.True(t, errors.Is(err, serializer.ErrChecksumMismatch))
+
+	other := &pb.Laptop{}
+	require.NoError(t, reader.Read(other))
+	require.True(t, proto.Equal(laptop2, other))
+
+	// truncate the last record
+	reader, err = serializer.NewReader(bytes.NewReader(data[:len(data)-1]))
+	require.NoError(t, err)
+	require.Error(t, reader.Read(&pb.Laptop{}))
+	err = reader.Read(&pb.Laptop{})
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	_, err = serializer.NewReader(bytes.NewReader([]byte("{\"id\": \"a\"}")))
+	require.Error(t, err)
+}
+
+func TestStreamReaderCorruptedLength(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	writer, err := serializer.NewWriter(&buffer, &pb.Laptop{}, serializer.CompressionNone)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(sample.NewLaptop()))
+	require.NoError(t, writer.Close())
+
+	// the first record starts after the magic number, the compression and the message name
+	data := buffer.Bytes()
+	offset := len("PCBK\x02") + 1 + 1 + len("techschool.pcbook.Laptop")
+	size, n := binary.Uvarint(data[offset:])
+	require.True(t, size > 132)
+
+	// a shorter record still ends with 4 bytes that could be a checksum
+	corrupted := append([]byte{}, data...)
+	require.Equal(t, n, binary.PutUvarint(corrupted[offset:], size-4))
+
+	reader, err := serializer.NewReader(bytes.NewReader(corrupted))
+	require.NoError(t, err)
+	err = reader.Read(&pb.Laptop{})
+	require.True(t, errors.Is(err, serializer.ErrChecksumMismatch))
+
+	// a huge length is rejected before allocating the record
+	corrupted = append(append([]byte{}, data[:offset]...), 0xff, 0xff, 0xff, 0xff, 0x0f)
+	reader, err = serializer.NewReader(bytes.NewReader(corrupted))
+	require.NoError(t, err)
+	err = reader.Read(&pb.Laptop{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "too large")
+}
@@ -0,0 +1,30 @@
+package serializer
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufToText converts a protocol buffer message to the multiline text format of protoc
+func ProtobufToText(message proto.Message) (string, error) {
+	marshaller := prototext.MarshalOptions{Multiline: true, Indent: "  "}
+
+	data, err := marshaller.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal proto message to text %w", err)
+	}
+
+	return string(data), nil
+}
+
+// TextToProtobuf converts the text format of protoc to a protocol buffer message
+func TextToProtobuf(data string, message proto.Message) error {
+	err := prototext.Unmarshal([]byte(data), message)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal text to proto message %w", err)
+	}
+
+	return nil
+}
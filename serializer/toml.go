@@ -0,0 +1,42 @@
+package serializer
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufToTOML converts a protocol buffer message to TOML with the same mapping as ProtobufToYAML.
+// Nested messages are written as tables and repeated messages as arrays of tables.
+func ProtobufToTOML(message proto.Message) (string, error) {
+	value, err := protobufToValue(message)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = toml.NewEncoder(&buffer).Encode(value)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal proto message to TOML %w", err)
+	}
+
+	return buffer.String(), nil
+}
+
+// TOMLToProtobuf converts TOML written by ProtobufToTOML to a protocol buffer message
+func TOMLToProtobuf(data string, message proto.Message) error {
+	var value map[string]interface{}
+	_, err := toml.Decode(data, &value)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal TOML %w", err)
+	}
+
+	err = valueToProtobuf(value, message)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal TOML to proto message %w", err)
+	}
+
+	return nil
+}
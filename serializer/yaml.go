@@ -0,0 +1,40 @@
+package serializer
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// ProtobufToYAML converts a protocol buffer message to YAML with the field names of the proto file.
+// Enums are written by name, timestamps in RFC 3339 and 64-bit integers as strings like in JSON.
+func ProtobufToYAML(message proto.Message) (string, error) {
+	value, err := protobufToValue(message)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal proto message to YAML %w", err)
+	}
+
+	return string(data), nil
+}
+
+// YAMLToProtobuf converts YAML written by ProtobufToYAML to a protocol buffer message
+func YAMLToProtobuf(data string, message proto.Message) error {
+	var value interface{}
+	err := yaml.Unmarshal([]byte(data), &value)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal YAML %w", err)
+	}
+
+	err = valueToProtobuf(value, message)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal YAML to proto message %w", err)
+	}
+
+	return nil
+}
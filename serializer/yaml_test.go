@@ -0,0 +1,94 @@
+package serializer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/serializer"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTextFormatRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	laptop1 := sample.NewLaptop()
+	laptop1.Gpus = append(laptop1.Gpus, sample.NewGPU())
+	laptop1.Version = 7
+
+	laptop2 := sample.NewLaptop()
+	laptop2.Weight = &pb.Laptop_WeightLb{WeightLb: 4.5}
+	laptop2.Storages = nil
+
+	laptops := []*pb.Laptop{laptop1, laptop2, {}}
+
+	testCases := []struct {
+		name      string
+		marshal   func(message *pb.Laptop) (string, error)
+		unmarshal func(data string, message *pb.Laptop) error
+	}{
+		{
+			name:      "yaml",
+			marshal:   func(message *pb.Laptop) (string, error) { return serializer.ProtobufToYAML(message) },
+			unmarshal: func(data string, message *pb.Laptop) error { return serializer.YAMLToProtobuf(data, message) },
+		},
+		{
+			name:      "toml",
+			marshal:   func(message *pb.Laptop) (string, error) { return serializer.ProtobufToTOML(message) },
+			unmarshal: func(data string, message *pb.Laptop) error { return serializer.TOMLToProtobuf(data, message) },
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, laptop := range laptops {
+				data, err := tc.marshal(laptop)
+				require.NoError(t, err)
+
+				other := &pb.Laptop{}
+				require.NoError(t, tc.unmarshal(data, other))
+				require.True(t, proto.Equal(laptop, other), "%v != %v\n%s", laptop, other, data)
+			}
+		})
+	}
+}
+
+func TestYAMLToProtobuf(t *testing.T) {
+	t.Parallel()
+
+	data := `
+brand: Apple
+name: Macbook Pro
+Keyboard:
+  layout: QWERTY
+  backlit: true
+gpus:
+  - brand: Nvidia
+    name: RTX 2060
+  - brand: AMD
+    name: RX 590
+weight_lb: 4.2
+release_year: 2019
+updated_at: "2020-01-02T03:04:05Z"
+`
+	laptop := &pb.Laptop{}
+	require.NoError(t, serializer.YAMLToProtobuf(data, laptop))
+	require.Equal(t, pb.Keyboard_QWERTY, laptop.GetKeyboard().GetLayout())
+	require.Len(t, laptop.GetGpus(), 2)
+	require.Equal(t, "AMD", laptop.GetGpus()[1].GetBrand())
+	require.Equal(t, 4.2, laptop.GetWeightLb())
+	require.EqualValues(t, 2019, laptop.GetReleaseYear())
+	require.EqualValues(t, 1577934245, laptop.GetUpdatedAt().GetSeconds())
+
+	err := serializer.YAMLToProtobuf("Keyboard:\n  layout: DVORAK\n", &pb.Laptop{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid value for enum type: "DVORAK"`)
+
+	err = serializer.YAMLToProtobuf("unknown_field: 1\n", &pb.Laptop{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unknown field "unknown_field"`)
+}
@@ -28,7 +28,7 @@ func (interceptor *AuthInterceptop) Unary() grpc.UnaryServerInterceptor {
 	) (interface{}, error) {
 		log.Println("--> unary interceptor", info.FullMethod)
 
-		err := interceptor.authorize(ctx, info.FullMethod)
+		ctx, err := interceptor.authorize(ctx, info.FullMethod)
 		if err != nil {
 			return nil, err
 		}
@@ -45,44 +45,68 @@ func (interceptor *AuthInterceptop) Stream() grpc.StreamServerInterceptor {
 	) error {
 		log.Println("--> stream intercepter", info.FullMethod)
 
-		err := interceptor.authorize(ss.Context(), info.FullMethod)
+		ctx, err := interceptor.authorize(ss.Context(), info.FullMethod)
 		if err != nil {
 			return err
 		}
 
-		return handler(srv, ss)
+		return handler(srv, &authServerStream{ss, ctx})
 	}
 
 }
 
-func (interceptor *AuthInterceptop) authorize(ctx context.Context, method string) error {
+// authorize returns ctx with the claims of the user for RPCs that need a role
+func (interceptor *AuthInterceptop) authorize(ctx context.Context, method string) (context.Context, error) {
 	accesibleRoles, ok := interceptor.accessibleRoles[method]
 	if !ok {
 		// everyone can access
-		return nil
+		return ctx, nil
 	}
 
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Errorf(codes.Unauthenticated, "metadata is not provided")
+		return nil, status.Errorf(codes.Unauthenticated, "metadata is not provided")
 	}
 
 	values := md["authorization"]
 	if len(values) == 0 {
-		return status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+		return nil, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
 	}
 
 	accessToken := values[0]
 	claims, err := interceptor.jwtManager.Verify(accessToken)
 	if err != nil {
-		return status.Errorf(codes.Unauthenticated, "access token not valid")
+		return nil, status.Errorf(codes.Unauthenticated, "access token not valid")
 	}
 
 	for _, role := range accesibleRoles {
 		if role == claims.Role {
-			return nil
+			return NewContextWithUserClaims(ctx, claims), nil
 		}
 	}
 
-	return status.Error(codes.PermissionDenied, "no permission for access this RPC")
+	return nil, status.Error(codes.PermissionDenied, "no permission for access this RPC")
+}
+
+// authServerStream replaces the context of a stream with one that holds the user claims
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+type userClaimsKey struct{}
+
+// NewContextWithUserClaims returns a copy of ctx that holds the claims of the authenticated user
+func NewContextWithUserClaims(ctx context.Context, claims *UserClaims) context.Context {
+	return context.WithValue(ctx, userClaimsKey{}, claims)
+}
+
+// UserClaimsFromContext returns the claims of the authenticated user, if any
+func UserClaimsFromContext(ctx context.Context) (*UserClaims, bool) {
+	claims, ok := ctx.Value(userClaimsKey{}).(*UserClaims)
+	return claims, ok
 }
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"gitlab.techschool.pcbook/pb"
+	bolt "go.etcd.io/bbolt"
+)
+
+var laptopBucket = []byte("laptops")
+
+// BoltLaptopStore stores laptops in an embedded bbolt database file
+type BoltLaptopStore struct {
+	db *bolt.DB
+}
+
+// NewBoltLaptopStore opens the database file at path, creating it if needed
+func NewBoltLaptopStore(path string) (*BoltLaptopStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(laptopBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create laptop bucket %w", err)
+	}
+
+	return &BoltLaptopStore{db}, nil
+}
+
+// DB returns the database, so other bolt stores can keep their buckets in the same file
+func (store *BoltLaptopStore) DB() *bolt.DB {
+	return store.db
+}
+
+// Close releases the database file
+func (store *BoltLaptopStore) Close() error {
+	return store.db.Close()
+}
+
+func (store *BoltLaptopStore) Save(laptop *pb.Laptop) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(laptopBucket)
+		if bucket.Get([]byte(laptop.Id)) != nil {
+			return ErrAlreadyExists
+		}
+
+		other := proto.Clone(laptop).(*pb.Laptop)
+		other.Version = 1
+		return putLaptop(bucket, other)
+	})
+}
+
+func (store *BoltLaptopStore) SaveAll(laptops []*pb.Laptop) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(laptopBucket)
+		for i, laptop := range laptops {
+			if bucket.Get([]byte(laptop.Id)) != nil {
+				return &BatchError{i, ErrAlreadyExists}
+			}
+
+			other := proto.Clone(laptop).(*pb.Laptop)
+			other.Version = 1
+			err := putLaptop(bucket, other)
+			if err != nil {
+				return &BatchError{i, err}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (store *BoltLaptopStore) Find(id string) (*pb.Laptop, error) {
+	var laptop *pb.Laptop
+	err := store.db.View(func(tx *bolt.Tx) error {
+		var err error
+		laptop, err = getLaptop(tx.Bucket(laptopBucket), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return laptop, nil
+}
+
+func (store *BoltLaptopStore) Update(laptop *pb.Laptop) error {
+	var version uint64
+	err := store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(laptopBucket)
+		current, err := getLaptop(bucket, laptop.Id)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrNotFound
+		}
+		if current.Version != laptop.Version {
+			return ErrVersionMismatch
+		}
+
+		other := proto.Clone(laptop).(*pb.Laptop)
+		other.Version++
+		version = other.Version
+		return putLaptop(bucket, other)
+	})
+	if err != nil {
+		return err
+	}
+
+	laptop.Version = version
+	return nil
+}
+
+func (store *BoltLaptopStore) Delete(id string, version uint64) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(laptopBucket)
+		current, err := getLaptop(bucket, id)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrNotFound
+		}
+		if version != 0 && current.Version != version {
+			return ErrVersionMismatch
+		}
+
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// boltSearchBatchSize is the number of laptops Search reads in one transaction
+const boltSearchBatchSize = 100
+
+// Search reads the laptops in batches of short read transactions and calls found once
+// a transaction is closed, so that a slow client doesn't keep a transaction open, which
+// would keep the database from growing its file
+func (store *BoltLaptopStore) Search(
+	ctx context.Context,
+	filter *pb.Filter,
+	found func(laptop *pb.Laptop) error,
+) error {
+	var after []byte
+	for {
+		var laptops []*pb.Laptop
+		done := false
+		err := store.db.View(func(tx *bolt.Tx) error {
+			cursor := tx.Bucket(laptopBucket).Cursor()
+			key, value := cursor.First()
+			if after != nil {
+				key, value = cursor.Seek(after)
+				if bytes.Equal(key, after) {
+					key, value = cursor.Next()
+				}
+			}
+
+			for n := 0; key != nil && n < boltSearchBatchSize; key, value = cursor.Next() {
+				n++
+				// keys are only valid during the transaction
+				after = append(after[:0], key...)
+
+				laptop := &pb.Laptop{}
+				err := proto.Unmarshal(value, laptop)
+				if err != nil {
+					return fmt.Errorf("cannot unmarshal laptop %s %w", key, err)
+				}
+
+				if isQualified(filter, laptop) {
+					laptops = append(laptops, laptop)
+				}
+			}
+
+			done = key == nil
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, laptop := range laptops {
+			if ctx.Err() == context.Canceled || ctx.Err() == context.DeadlineExceeded {
+				log.Print("context is canceled")
+				return errors.New("Context canceled")
+			}
+
+			err := found(laptop)
+			if err != nil {
+				return err
+			}
+		}
+
+		if done {
+			return nil
+		}
+	}
+}
+
+func getLaptop(bucket *bolt.Bucket, id string) (*pb.Laptop, error) {
+	value := bucket.Get([]byte(id))
+	if value == nil {
+		return nil, nil
+	}
+
+	laptop := &pb.Laptop{}
+	err := proto.Unmarshal(value, laptop)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal laptop %s %w", id, err)
+	}
+
+	return laptop, nil
+}
+
+func putLaptop(bucket *bolt.Bucket, laptop *pb.Laptop) error {
+	value, err := proto.Marshal(laptop)
+	if err != nil {
+		return fmt.Errorf("cannot marshal laptop %w", err)
+	}
+
+	return bucket.Put([]byte(laptop.Id), value)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+
+	"gitlab.techschool.pcbook/pb"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+var savedSearchBucket = []byte("saved_searches")
+
+// BoltSavedSearchStore stores saved searches as protobuf in a bucket per user
+type BoltSavedSearchStore struct {
+	db *bolt.DB
+}
+
+// NewBoltSavedSearchStore returns a saved search store on top of an open bolt database
+func NewBoltSavedSearchStore(db *bolt.DB) (*BoltSavedSearchStore, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(savedSearchBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create saved search bucket %w", err)
+	}
+
+	return &BoltSavedSearchStore{db}, nil
+}
+
+func (store *BoltSavedSearchStore) Save(username string, search *pb.SavedSearch) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(savedSearchBucket).CreateBucketIfNotExists([]byte(username))
+		if err != nil {
+			return fmt.Errorf("cannot create saved search bucket %w", err)
+		}
+		if bucket.Get([]byte(search.GetId())) != nil {
+			return ErrAlreadyExists
+		}
+
+		return putSavedSearch(bucket, search)
+	})
+}
+
+func (store *BoltSavedSearchStore) Find(username, id string) (*pb.SavedSearch, error) {
+	var search *pb.SavedSearch
+	err := store.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(savedSearchBucket).Bucket([]byte(username))
+		if bucket == nil {
+			return nil
+		}
+
+		value := bucket.Get([]byte(id))
+		if value == nil {
+			return nil
+		}
+
+		var err error
+		search, err = unmarshalSavedSearch(value)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
+
+func (store *BoltSavedSearchStore) List(username string) ([]*pb.SavedSearch, error) {
+	searches := []*pb.SavedSearch{}
+	err := store.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(savedSearchBucket).Bucket([]byte(username))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(key, value []byte) error {
+			search, err := unmarshalSavedSearch(value)
+			if err != nil {
+				return err
+			}
+
+			searches = append(searches, search)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortSavedSearches(searches)
+	return searches, nil
+}
+
+func (store *BoltSavedSearchStore) Update(username string, search *pb.SavedSearch) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(savedSearchBucket).Bucket([]byte(username))
+		if bucket == nil || bucket.Get([]byte(search.GetId())) == nil {
+			return ErrNotFound
+		}
+
+		return putSavedSearch(bucket, search)
+	})
+}
+
+func (store *BoltSavedSearchStore) Delete(username, id string) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(savedSearchBucket).Bucket([]byte(username))
+		if bucket == nil || bucket.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+
+		return bucket.Delete([]byte(id))
+	})
+}
+
+func putSavedSearch(bucket *bolt.Bucket, search *pb.SavedSearch) error {
+	value, err := proto.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("cannot marshal saved search %w", err)
+	}
+
+	return bucket.Put([]byte(search.GetId()), value)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.techschool.pcbook/pb"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	webhookBucket    = []byte("webhooks")
+	deadLetterBucket = []byte("webhook_dead_letters")
+)
+
+// BoltWebhookStore stores webhooks and their dead letters as protobuf in a bolt database.
+// Dead letters are keyed by a sequence number to keep them in the order they were saved.
+type BoltWebhookStore struct {
+	db *bolt.DB
+}
+
+// NewBoltWebhookStore returns a webhook store on top of an open bolt database
+func NewBoltWebhookStore(db *bolt.DB) (*BoltWebhookStore, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(webhookBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucketIfNotExists(deadLetterBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create webhook buckets %w", err)
+	}
+
+	return &BoltWebhookStore{db}, nil
+}
+
+func (store *BoltWebhookStore) Save(webhook *pb.Webhook) error {
+	value, err := proto.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("cannot marshal webhook %w", err)
+	}
+
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(webhookBucket)
+		if bucket.Get([]byte(webhook.GetId())) != nil {
+			return ErrAlreadyExists
+		}
+
+		return bucket.Put([]byte(webhook.GetId()), value)
+	})
+}
+
+func (store *BoltWebhookStore) Find(id string) (*pb.Webhook, error) {
+	var webhook *pb.Webhook
+	err := store.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(webhookBucket).Get([]byte(id))
+		if value == nil {
+			return nil
+		}
+
+		var err error
+		webhook, err = unmarshalWebhook(value)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (store *BoltWebhookStore) List() ([]*pb.Webhook, error) {
+	webhooks := []*pb.Webhook{}
+	err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(webhookBucket).ForEach(func(key, value []byte) error {
+			webhook, err := unmarshalWebhook(value)
+			if err != nil {
+				return err
+			}
+
+			webhooks = append(webhooks, webhook)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortWebhooks(webhooks)
+	return webhooks, nil
+}
+
+func (store *BoltWebhookStore) Delete(id string) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(webhookBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+
+		return bucket.Delete([]byte(id))
+	})
+}
+
+func (store *BoltWebhookStore) SaveDeadLetter(delivery *pb.WebhookDelivery) error {
+	value, err := proto.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("cannot marshal webhook delivery %w", err)
+	}
+
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(deadLetterBucket)
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, sequence)
+		return bucket.Put(key, value)
+	})
+}
+
+func (store *BoltWebhookStore) ListDeadLetters(webhookID string) ([]*pb.WebhookDelivery, error) {
+	deliveries := []*pb.WebhookDelivery{}
+	err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).ForEach(func(key, value []byte) error {
+			delivery, err := unmarshalWebhookDelivery(value)
+			if err != nil {
+				return err
+			}
+
+			if webhookID == "" || delivery.GetWebhookId() == webhookID {
+				deliveries = append(deliveries, delivery)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (store *BoltWebhookStore) TakeDeadLetters(ids []string) ([]*pb.WebhookDelivery, error) {
+	take := make(map[string]bool)
+	for _, id := range ids {
+		take[id] = true
+	}
+
+	var deliveries []*pb.WebhookDelivery
+	err := store.db.Update(func(tx *bolt.Tx) error {
+		var keys [][]byte
+		bucket := tx.Bucket(deadLetterBucket)
+		err := bucket.ForEach(func(key, value []byte) error {
+			delivery, err := unmarshalWebhookDelivery(value)
+			if err != nil {
+				return err
+			}
+
+			if len(ids) == 0 || take[delivery.GetId()] {
+				deliveries = append(deliveries, delivery)
+				keys = append(keys, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// a bucket must not be changed while iterating over it
+		for _, key := range keys {
+			err := bucket.Delete(key)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func unmarshalWebhook(data []byte) (*pb.Webhook, error) {
+	webhook := &pb.Webhook{}
+	err := proto.Unmarshal(data, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal webhook %w", err)
+	}
+
+	return webhook, nil
+}
+
+func unmarshalWebhookDelivery(data []byte) (*pb.WebhookDelivery, error) {
+	delivery := &pb.WebhookDelivery{}
+	err := proto.Unmarshal(data, delivery)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal webhook delivery %w", err)
+	}
+
+	return delivery, nil
+}
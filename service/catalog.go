@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/serializer"
+)
+
+// ImportOptions controls how laptops are imported to a store
+type ImportOptions struct {
+	// DryRun validates the laptops without saving them
+	DryRun bool
+	// Upsert replaces the laptops that already exist instead of reporting them
+	Upsert bool
+}
+
+// ImportError is a laptop of an imported file that was not saved
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (err *ImportError) Error() string {
+	return fmt.Sprintf("line %d: %v", err.Line, err.Err)
+}
+
+func (err *ImportError) Unwrap() error {
+	return err.Err
+}
+
+// ImportResult counts the imported laptops, or the ones a dry run would import
+type ImportResult struct {
+	Created int
+	Updated int
+	Errors  []*ImportError
+}
+
+// ExportLaptops writes all laptops of the store ordered by ID, then closes the writer.
+// It returns the number of laptops written.
+func ExportLaptops(ctx context.Context, store LaptopStore, writer serializer.MessageWriter) (int, error) {
+	var laptops []*pb.Laptop
+	err := store.Search(ctx, &pb.Filter{}, func(laptop *pb.Laptop) error {
+		laptops = append(laptops, laptop)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot search laptops: %w", err)
+	}
+
+	sort.Slice(laptops, func(i, j int) bool {
+		return laptops[i].GetId() < laptops[j].GetId()
+	})
+
+	for _, laptop := range laptops {
+		err := writer.Write(laptop)
+		if err != nil {
+			return 0, fmt.Errorf("cannot write laptop %s: %w", laptop.GetId(), err)
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return 0, fmt.Errorf("cannot write laptops: %w", err)
+	}
+
+	return len(laptops), nil
+}
+
+// ImportLaptops saves the laptops read from reader to the store.
+// Invalid laptops are reported in the result and skipped, and so is the rest of the
+// file if it cannot be read anymore. Only errors of the store stop the import.
+func ImportLaptops(store LaptopStore, reader serializer.MessageReader, options ImportOptions) (*ImportResult, error) {
+	result := &ImportResult{}
+	lines := make(map[string]int)
+
+	for {
+		laptop := &pb.Laptop{}
+		err := reader.Read(laptop)
+		if err == io.EOF {
+			return result, nil
+		}
+
+		var parseErr *serializer.ParseError
+		if errors.As(err, &parseErr) {
+			result.Errors = append(result.Errors, &ImportError{parseErr.Line, parseErr.Err})
+			continue
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, &ImportError{reader.Line(), err})
+			return result, nil
+		}
+
+		line := reader.Line()
+		err = prepareBatchLaptop(laptop)
+		if err != nil {
+			result.Errors = append(result.Errors, &ImportError{line, err})
+			continue
+		}
+
+		if first, ok := lines[laptop.Id]; ok {
+			err := fmt.Errorf("laptop %s is already on line %d", laptop.Id, first)
+			result.Errors = append(result.Errors, &ImportError{line, err})
+			continue
+		}
+		lines[laptop.Id] = line
+
+		err = importLaptop(store, laptop, options, result)
+		if errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrVersionMismatch) {
+			result.Errors = append(result.Errors, &ImportError{line, err})
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("cannot import laptop on line %d: %w", line, err)
+		}
+	}
+}
+
+func importLaptop(store LaptopStore, laptop *pb.Laptop, options ImportOptions, result *ImportResult) error {
+	existing, err := store.Find(laptop.Id)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if !options.DryRun {
+			err := store.Save(laptop)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Created++
+		return nil
+	}
+
+	if !options.Upsert {
+		return ErrAlreadyExists
+	}
+
+	if !options.DryRun {
+		laptop.Version = existing.GetVersion()
+		err := store.Update(laptop)
+		if err != nil {
+			return err
+		}
+	}
+
+	result.Updated++
+	return nil
+}
@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestClientExportImportLaptops(t *testing.T) {
+	t.Parallel()
+
+	source := service.NewInMemoryLaptopStore()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, source.Save(sample.NewLaptop()))
+	}
+	sourceClient := newTestCatalogClient(t, startTestCatalogServer(t, service.NewCatalogServer(source)))
+
+	formats := []pb.CatalogFormat{pb.CatalogFormat_NDJSON, pb.CatalogFormat_PROTOBUF, pb.CatalogFormat_CSV}
+	for _, format := range formats {
+		data := exportTestLaptops(t, sourceClient, format)
+
+		target := service.NewInMemoryLaptopStore()
+		targetClient := newTestCatalogClient(t, startTestCatalogServer(t, service.NewCatalogServer(target)))
+
+		options := &pb.CatalogImportOptions{Format: format, DryRun: true}
+		res, err := importTestLaptops(t, targetClient, options, data)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, res.GetCreatedCount(), format)
+		require.Empty(t, res.GetErrors())
+		require.Empty(t, exportTestLaptops(t, targetClient, pb.CatalogFormat_NDJSON))
+
+		options.DryRun = false
+		res, err = importTestLaptops(t, targetClient, options, data)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, res.GetCreatedCount(), format)
+
+		err = source.Search(context.Background(), &pb.Filter{}, func(laptop *pb.Laptop) error {
+			other, err := target.Find(laptop.GetId())
+			require.NoError(t, err)
+			require.True(t, proto.Equal(laptop, other), "%s: %v != %v", format, laptop, other)
+			return nil
+		})
+		require.NoError(t, err)
+
+		res, err = importTestLaptops(t, targetClient, options, data)
+		require.NoError(t, err)
+		require.Zero(t, res.GetCreatedCount())
+		require.Len(t, res.GetErrors(), 5)
+
+		options.Upsert = true
+		res, err = importTestLaptops(t, targetClient, options, data)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, res.GetUpdatedCount(), format)
+		require.Empty(t, res.GetErrors())
+	}
+}
+
+func TestClientImportLaptopsErrors(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	catalogClient := newTestCatalogClient(t, startTestCatalogServer(t, service.NewCatalogServer(laptopStore)))
+
+	valid := sample.NewLaptop()
+	data := "id,brand,name,price_usd\n" +
+		valid.GetId() + ",Apple,Macbook,1500\n" +
+		",,Macbook,1500\n" +
+		"invalid-uuid,Apple,Macbook,1500\n" +
+		valid.GetId() + ",Apple,Macbook Pro,2500\n" +
+		",Apple,Macbook,not a price\n" +
+		",Apple,Macbook Air,1000\n"
+
+	options := &pb.CatalogImportOptions{Format: pb.CatalogFormat_CSV}
+	res, err := importTestLaptops(t, catalogClient, options, []byte(data))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, res.GetCreatedCount())
+
+	var lines []uint32
+	for _, importErr := range res.GetErrors() {
+		lines = append(lines, importErr.GetLine())
+	}
+	require.Equal(t, []uint32{3, 4, 5, 6}, lines)
+
+	_, err = importTestLaptops(t, catalogClient, &pb.CatalogImportOptions{}, []byte(data))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func exportTestLaptops(t *testing.T, catalogClient pb.CatalogServiceClient, format pb.CatalogFormat) []byte {
+	stream, err := catalogClient.ExportLaptops(context.Background(), &pb.ExportLaptopsRequest{Format: format})
+	require.NoError(t, err)
+
+	var data bytes.Buffer
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return data.Bytes()
+		}
+		require.NoError(t, err)
+		data.Write(res.GetChunk())
+	}
+}
+
+// importTestLaptops sends data in small chunks so that messages are split across them
+func importTestLaptops(
+	t *testing.T,
+	catalogClient pb.CatalogServiceClient,
+	options *pb.CatalogImportOptions,
+	data []byte,
+) (*pb.ImportLaptopsResponse, error) {
+	stream, err := catalogClient.ImportLaptops(context.Background())
+	require.NoError(t, err)
+
+	err = stream.Send(&pb.ImportLaptopsRequest{Data: &pb.ImportLaptopsRequest_Options{Options: options}})
+	require.NoError(t, err)
+
+	const chunkSize = 100
+	for len(data) > 0 {
+		size := chunkSize
+		if len(data) < size {
+			size = len(data)
+		}
+
+		err := stream.Send(&pb.ImportLaptopsRequest{Data: &pb.ImportLaptopsRequest_Chunk{Chunk: data[:size]}})
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data = data[size:]
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func startTestCatalogServer(t *testing.T, catalogServer *service.CatalogServer) string {
+	grpcServer := grpc.NewServer()
+	pb.RegisterCatalogServiceServer(grpcServer, catalogServer)
+
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	return listener.Addr().String()
+}
+
+func newTestCatalogClient(t *testing.T, serverAddress string) pb.CatalogServiceClient {
+	conn, err := grpc.Dial(serverAddress, grpc.WithInsecure())
+	require.NoError(t, err)
+	return pb.NewCatalogServiceClient(conn)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/serializer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CatalogServer exports and imports the whole laptop store
+type CatalogServer struct {
+	laptopStore LaptopStore
+}
+
+func NewCatalogServer(laptopStore LaptopStore) *CatalogServer {
+	return &CatalogServer{laptopStore}
+}
+
+func (server *CatalogServer) ExportLaptops(
+	req *pb.ExportLaptopsRequest,
+	stream pb.CatalogService_ExportLaptopsServer,
+) error {
+	log.Printf("receive an export laptops request with format: %s", req.GetFormat())
+
+	format, err := catalogFormat(req.GetFormat())
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "%v", err))
+	}
+
+	writer, err := serializer.NewMessageWriter(format, &exportChunkWriter{stream})
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "%v", err))
+	}
+
+	count, err := ExportLaptops(stream.Context(), server.laptopStore, writer)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot export laptops: %v", err))
+	}
+
+	log.Printf("exported %d laptops", count)
+	return nil
+}
+
+func (server *CatalogServer) ImportLaptops(stream pb.CatalogService_ImportLaptopsServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot receive import options %v", err))
+	}
+
+	options := req.GetOptions()
+	if options == nil {
+		return logError(status.Error(codes.InvalidArgument, "the first request must have the import options"))
+	}
+	log.Printf("receive an import laptops request with format: %s dry run: %t upsert: %t",
+		options.GetFormat(), options.GetDryRun(), options.GetUpsert())
+
+	format, err := catalogFormat(options.GetFormat())
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "%v", err))
+	}
+
+	reader, err := serializer.NewMessageReader(format, &importChunkReader{stream: stream})
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "%v", err))
+	}
+
+	result, err := ImportLaptops(server.laptopStore, reader, ImportOptions{
+		DryRun: options.GetDryRun(),
+		Upsert: options.GetUpsert(),
+	})
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "%v", err))
+	}
+
+	res := &pb.ImportLaptopsResponse{
+		CreatedCount: uint32(result.Created),
+		UpdatedCount: uint32(result.Updated),
+	}
+	for _, importErr := range result.Errors {
+		res.Errors = append(res.Errors, &pb.CatalogImportError{
+			Line:  uint32(importErr.Line),
+			Error: importErr.Err.Error(),
+		})
+	}
+
+	err = stream.SendAndClose(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send response %v", err))
+	}
+
+	log.Printf("imported laptops created: %d updated: %d errors: %d", res.CreatedCount, res.UpdatedCount, len(res.Errors))
+	return nil
+}
+
+func catalogFormat(format pb.CatalogFormat) (string, error) {
+	switch format {
+	case pb.CatalogFormat_NDJSON:
+		return serializer.FormatNDJSON, nil
+	case pb.CatalogFormat_PROTOBUF:
+		return serializer.FormatProtobuf, nil
+	case pb.CatalogFormat_CSV:
+		return serializer.FormatCSV, nil
+	default:
+		return "", fmt.Errorf("unknown catalog format %s", format)
+	}
+}
+
+// exportChunkWriter sends each write as a chunk of the exported file
+type exportChunkWriter struct {
+	stream pb.CatalogService_ExportLaptopsServer
+}
+
+func (writer *exportChunkWriter) Write(data []byte) (int, error) {
+	chunk := make([]byte, len(data))
+	copy(chunk, data)
+
+	err := writer.stream.Send(&pb.ExportLaptopsResponse{Chunk: chunk})
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// importChunkReader reads the chunks of the imported file until the client closes the stream
+type importChunkReader struct {
+	stream pb.CatalogService_ImportLaptopsServer
+	chunk  []byte
+}
+
+func (reader *importChunkReader) Read(data []byte) (int, error) {
+	for len(reader.chunk) == 0 {
+		req, err := reader.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		reader.chunk = req.GetChunk()
+	}
+
+	n := copy(data, reader.chunk)
+	reader.chunk = reader.chunk[n:]
+	return n, nil
+}
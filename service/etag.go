@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// IfMatchKey is the metadata key carrying the expected laptop version.
+// The REST gateway maps the If-Match HTTP header to this key.
+const IfMatchKey = "if-match"
+
+// expectedVersion returns the laptop version a request is conditional on.
+// The version in the request body wins over the if-match metadata,
+// and zero means the request is unconditional.
+func expectedVersion(ctx context.Context, version uint64) (uint64, error) {
+	if version != 0 {
+		return version, nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+
+	values := md[IfMatchKey]
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	return parseETag(values[0])
+}
+
+// parseETag converts an ETag such as "3", W/"3" or * to a laptop version
+func parseETag(etag string) (uint64, error) {
+	etag = strings.TrimSpace(etag)
+	if etag == "*" {
+		return 0, nil
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	etag = strings.Trim(etag, `"`)
+	return strconv.ParseUint(etag, 10, 64)
+}
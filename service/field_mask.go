@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// applyFieldMask copies the fields listed in paths from src to dst.
+// A path may point to a nested field, for example "cpu.min_ghz".
+func applyFieldMask(dst, src proto.Message, paths []string) error {
+	for _, path := range paths {
+		err := applyFieldPath(dst.ProtoReflect(), src.ProtoReflect(), path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyFieldPath(dst, src protoreflect.Message, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		field := dst.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			return fmt.Errorf("unknown field %q in path %q", name, path)
+		}
+
+		if i == len(names)-1 {
+			if src.Has(field) {
+				dst.Set(field, src.Get(field))
+			} else {
+				dst.Clear(field)
+			}
+			return nil
+		}
+
+		if field.Message() == nil || field.IsList() || field.IsMap() {
+			return fmt.Errorf("field %q in path %q is not a message", name, path)
+		}
+
+		dst = dst.Mutable(field).Message()
+		src = src.Get(field).Message()
+	}
+
+	return nil
+}
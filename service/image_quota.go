@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxImageSize is the largest size of an image in bytes, whatever the quota of its uploader
+const maxImageSize = 32 << 20
+
+// ImageQuota limits the images that the users of a role can upload, a zero limit is no limit
+// but an image is never larger than maxImageSize
+type ImageQuota struct {
+	// MaxImageSize is the largest size of an image in bytes
+	MaxImageSize int
+	// MaxLaptopSize is the largest total size of the images of a laptop
+	MaxLaptopSize int
+	// MaxUploaderSize is the largest total size of the images uploaded by a user
+	MaxUploaderSize int
+}
+
+// imageQuota returns the quota of the role of the user authenticated by the AuthInterceptor
+// and the name of the user, which is empty if the user is not authenticated. The users of
+// a role without a quota cannot upload images.
+func (server *LaptopServer) imageQuota(ctx context.Context) (ImageQuota, string, error) {
+	role, username := "", ""
+	if claims, ok := UserClaimsFromContext(ctx); ok {
+		role, username = claims.Role, claims.Username
+	}
+
+	quota, ok := server.imageQuotas[role]
+	if !ok {
+		quota, ok = server.imageQuotas[""]
+	}
+	if !ok {
+		return ImageQuota{}, "", status.Errorf(codes.ResourceExhausted, "role %q has no image quota", role)
+	}
+
+	if quota.MaxImageSize <= 0 || quota.MaxImageSize > maxImageSize {
+		quota.MaxImageSize = maxImageSize
+	}
+
+	return quota, username, nil
+}
+
+// checkImageQuota returns a ResourceExhausted error if an image of this size is too large
+// or if it doesn't fit in the quota of its laptop or of its uploader
+func (server *LaptopServer) checkImageQuota(quota ImageQuota, laptopID string, uploader string, size int) error {
+	if size > quota.MaxImageSize {
+		return status.Errorf(codes.ResourceExhausted, "image is too large: %d > %d", size, quota.MaxImageSize)
+	}
+
+	if quota.MaxLaptopSize <= 0 && (quota.MaxUploaderSize <= 0 || uploader == "") {
+		return nil
+	}
+
+	usage, err := server.imageStore.Usage(laptopID, uploader)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot get image usage %v", err)
+	}
+
+	if quota.MaxLaptopSize > 0 && usage.LaptopSize+size > quota.MaxLaptopSize {
+		return status.Errorf(codes.ResourceExhausted,
+			"laptop %s has %d bytes of images, the quota is %d", laptopID, usage.LaptopSize, quota.MaxLaptopSize)
+	}
+
+	if quota.MaxUploaderSize > 0 && uploader != "" && usage.UploaderSize+size > quota.MaxUploaderSize {
+		return status.Errorf(codes.ResourceExhausted,
+			"user %s has uploaded %d bytes of images, the quota is %d", uploader, usage.UploaderSize, quota.MaxUploaderSize)
+	}
+
+	return nil
+}
+
+// commitImage commits an image once it is known to fit in the quota, or aborts it.
+// The quota is checked again since other images of the laptop or of the uploader
+// may have been saved during the upload.
+func (server *LaptopServer) commitImage(
+	writer ImageWriter,
+	quota ImageQuota,
+	laptopID string,
+	uploader string,
+	size int,
+) (*ImageInfo, error) {
+	// the laptop is always locked before the uploader, so that two commits cannot wait for each other
+	unlockLaptop := server.quotaLocks.Lock("laptop/" + laptopID)
+	defer unlockLaptop()
+	if uploader != "" {
+		unlockUploader := server.quotaLocks.Lock("uploader/" + uploader)
+		defer unlockUploader()
+	}
+
+	err := server.checkImageQuota(quota, laptopID, uploader, size)
+	if err != nil {
+		writer.Abort()
+		return nil, err
+	}
+
+	info, err := writer.Commit()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot save image to the store %v", err)
+	}
+
+	return info, nil
+}
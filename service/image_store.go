@@ -2,66 +2,824 @@ package service
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
 )
 
 // IMageStore is an interface to store laptop images
 type ImageStore interface {
-	Save(laptopID string, imageType string, imgData bytes.Buffer) (string, error)
+	// Create starts a new image of a laptop uploaded by a user, whose data is written
+	// to the store as it is received and which is saved once the writer is committed
+	Create(laptopID string, imageType string, uploader string) (ImageWriter, error)
+	// Find returns nil if there is no image with this ID
+	Find(imageID string) (*ImageInfo, error)
+	// List returns the images of a laptop from the oldest to the newest
+	List(laptopID string) ([]*ImageInfo, error)
+	// ListByUploader returns the images uploaded by a user from the oldest to the newest
+	ListByUploader(uploader string) ([]*ImageInfo, error)
+	// Usage returns the total size of the images of a laptop and of the images uploaded by a user,
+	// which is kept up to date as images are committed and deleted rather than summed up
+	Usage(laptopID string, uploader string) (ImageUsage, error)
+	// Open returns the data of an image, or ErrNotFound
+	Open(imageID string) (io.ReadCloser, error)
+	// Delete removes an image, or returns ErrNotFound
+	Delete(imageID string) error
+	DeleteByLaptop(laptopID string) error
+	// SaveThumbnail adds a thumbnail to an image, or replaces the one with the same name
+	SaveThumbnail(imageID string, thumbnail ThumbnailInfo, thumbnailData io.Reader) error
+	// OpenThumbnail returns the data of a thumbnail, or ErrNotFound
+	OpenThumbnail(imageID string, name string) (io.ReadCloser, error)
+	// ImageURL returns a URL to download an image, or its thumbnail if thumbnail is not empty,
+	// until it expires, or ErrNotFound or ErrImageURLNotSupported
+	ImageURL(imageID string, thumbnail string, expires time.Duration) (string, error)
 }
 
+// ImageWriter writes the data of a new image to an image store
+type ImageWriter interface {
+	io.Writer
+	// Commit saves the image once all of its data is written
+	Commit() (*ImageInfo, error)
+	// Abort discards the data written, it does nothing once the image is committed
+	Abort() error
+}
+
+// ErrImageURLNotSupported is returned by the image stores that cannot give URLs to download images
+var ErrImageURLNotSupported = errors.New("image store cannot give download URLs")
+
+type ImageInfo struct {
+	ID         string
+	LaptopID   string
+	Type       string
+	Path       string `json:"-"`
+	Hash       string
+	Size       int
+	Uploader   string
+	CreatedAt  time.Time
+	Thumbnails []ThumbnailInfo
+}
+
+// ImageUsage is the total size in bytes of images, without their thumbnails
+type ImageUsage struct {
+	// LaptopSize is the size of the images of a laptop
+	LaptopSize int
+	// UploaderSize is the size of the images uploaded by a user
+	UploaderSize int
+}
+
+// ThumbnailInfo is a smaller version of an image
+type ThumbnailInfo struct {
+	Name   string
+	Type   string
+	Path   string `json:"-"`
+	Hash   string
+	Width  int
+	Height int
+	Size   int
+}
+
+// ContentType returns the MIME type of the image guessed from its type
+func (info *ImageInfo) ContentType() string {
+	return contentType(info.Type)
+}
+
+// Thumbnail returns nil if the image has no thumbnail with this name
+func (info *ImageInfo) Thumbnail(name string) *ThumbnailInfo {
+	for i := range info.Thumbnails {
+		if info.Thumbnails[i].Name == name {
+			return &info.Thumbnails[i]
+		}
+	}
+
+	return nil
+}
+
+// ContentType returns the MIME type of the thumbnail guessed from its type
+func (thumbnail *ThumbnailInfo) ContentType() string {
+	return contentType(thumbnail.Type)
+}
+
+func contentType(imageType string) string {
+	contentType := mime.TypeByExtension(imageType)
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+
+	return contentType
+}
+
+var (
+	imageBucket         = []byte("images")
+	laptopImageBucket   = []byte("laptop_images")
+	uploaderImageBucket = []byte("uploader_images")
+	blobBucket          = []byte("blobs")
+	imageUsageBucket    = []byte("image_usage")
+)
+
+// DiskImageStore stores the data of images as blobs named by their SHA-256 in the blobs folder,
+// sharded by the first bytes of the hash like blobs/3f/a2/3fa2..., so that images with the same
+// data share a blob. The images, the images of every laptop and of every uploader, their total size
+// and the number of references to every blob are indexed in a bbolt database file in the image folder. A blob is
+// written to a temporary file renamed once it is complete, and removed with its last reference,
+// CollectGarbage removes the files left by a crash.
 type DiskImageStore struct {
 	mutex       sync.Mutex
 	imageFolder string
-	images      map[string]*ImageInfo
+	db          *bolt.DB
+	// writing are the temporary files of the blobs being written
+	writing map[string]bool
 }
 
-type ImageInfo struct {
-	LaptopID string
-	Type     string
-	Path     string
-}
+// NewDiskImageStore opens the image folder, creating it if needed
+func NewDiskImageStore(imageFolder string) (*DiskImageStore, error) {
+	err := os.MkdirAll(filepath.Join(imageFolder, "blobs"), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create image folder %w", err)
+	}
+
+	db, err := bolt.Open(filepath.Join(imageFolder, "images.db"), 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open image database %w", err)
+	}
 
-func NewDiskImageStore(imageFolder string) *DiskImageStore {
-	return &DiskImageStore{
-		imageFolder: imageFolder,
-		images:      make(map[string]*ImageInfo),
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{imageBucket, laptopImageBucket, uploaderImageBucket, blobBucket} {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+		return createImageUsageBucket(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create image buckets %w", err)
 	}
+
+	return &DiskImageStore{imageFolder: imageFolder, db: db, writing: make(map[string]bool)}, nil
 }
 
-func (store *DiskImageStore) Save(
-	laptopID string,
-	imageType string,
-	imageData bytes.Buffer,
-) (string, error) {
+// Close releases the database file
+func (store *DiskImageStore) Close() error {
+	return store.db.Close()
+}
+
+func (store *DiskImageStore) Create(laptopID string, imageType string, uploader string) (ImageWriter, error) {
 	imageID, err := uuid.NewRandom()
 	if err != nil {
-		return "", fmt.Errorf("cannot generate image id %w", err)
+		return nil, fmt.Errorf("cannot generate image id %w", err)
+	}
+
+	blob, err := store.newBlobWriter()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ImageInfo{
+		ID:       imageID.String(),
+		LaptopID: laptopID,
+		Type:     imageType,
+		Uploader: uploader,
+	}
+
+	return &diskImageWriter{store: store, info: info, blob: blob}, nil
+}
+
+// diskImageWriter writes an image to a blob, which is indexed once committed
+type diskImageWriter struct {
+	store *DiskImageStore
+	info  *ImageInfo
+	blob  *blobWriter
+	done  bool
+}
+
+func (writer *diskImageWriter) Write(p []byte) (int, error) {
+	if writer.done {
+		return 0, errors.New("image is already committed or aborted")
+	}
+
+	return writer.blob.Write(p)
+}
+
+func (writer *diskImageWriter) Commit() (*ImageInfo, error) {
+	if writer.done {
+		return nil, errors.New("image is already committed or aborted")
 	}
+	writer.done = true
+
+	store := writer.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
 
-	imagePath := fmt.Sprintf("%s/%s%s", store.imageFolder, imageID, imageType)
-	file, err := os.Create(imagePath)
+	hash, err := store.commitBlob(writer.blob)
 	if err != nil {
-		return "", fmt.Errorf("erorr creating file %w", err)
+		return nil, err
 	}
 
-	_, err = imageData.WriteTo(file)
+	info := writer.info
+	info.Hash = hash
+	info.Size = writer.blob.size
+	info.CreatedAt = time.Now()
+
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		err := putImage(tx, info)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Bucket(laptopImageBucket).Put(imageKey(info.LaptopID, info.ID), []byte{})
+		if err != nil {
+			return err
+		}
+
+		if info.Uploader != "" {
+			err = tx.Bucket(uploaderImageBucket).Put(imageKey(info.Uploader, info.ID), []byte{})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = addImageUsage(tx, info, info.Size)
+		if err != nil {
+			return err
+		}
+
+		_, err = addBlobReference(tx, hash, 1)
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("erorr writingto file %w", err)
+		// the blob is removed by the next garbage collection if it is not used
+		return nil, fmt.Errorf("cannot save image %w", err)
+	}
+
+	info.Path = store.blobPath(hash)
+	other := *info
+	return &other, nil
+}
+
+func (writer *diskImageWriter) Abort() error {
+	if writer.done {
+		return nil
 	}
+	writer.done = true
+
+	writer.store.mutex.Lock()
+	defer writer.store.mutex.Unlock()
 
+	return writer.store.abortBlob(writer.blob)
+}
+
+func (store *DiskImageStore) Find(imageID string) (*ImageInfo, error) {
+	var info *ImageInfo
+	err := store.db.View(func(tx *bolt.Tx) error {
+		var err error
+		info, err = store.getImage(tx, imageID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (store *DiskImageStore) List(laptopID string) ([]*ImageInfo, error) {
+	return store.listImages(laptopImageBucket, laptopID)
+}
+
+func (store *DiskImageStore) ListByUploader(uploader string) ([]*ImageInfo, error) {
+	return store.listImages(uploaderImageBucket, uploader)
+}
+
+func (store *DiskImageStore) Usage(laptopID string, uploader string) (ImageUsage, error) {
+	var usage ImageUsage
+	err := store.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(imageUsageBucket)
+		usage.LaptopSize = getImageUsage(bucket, laptopUsageKey(laptopID))
+		if uploader != "" {
+			usage.UploaderSize = getImageUsage(bucket, uploaderUsageKey(uploader))
+		}
+		return nil
+	})
+
+	return usage, err
+}
+
+// listImages returns the images of a laptop or of an uploader indexed in bucket from the oldest to the newest
+func (store *DiskImageStore) listImages(bucket []byte, owner string) ([]*ImageInfo, error) {
+	var images []*ImageInfo
+	err := store.db.View(func(tx *bolt.Tx) error {
+		imageIDs := ownerImageIDs(tx, bucket, owner)
+		for _, imageID := range imageIDs {
+			info, err := store.getImage(tx, imageID)
+			if err != nil {
+				return err
+			}
+			if info != nil {
+				images = append(images, info)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortImages(images)
+	return images, nil
+}
+
+func (store *DiskImageStore) Open(imageID string) (io.ReadCloser, error) {
+	info, err := store.Find(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(info.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open image file %w", err)
+	}
+
+	return file, nil
+}
+
+func (store *DiskImageStore) Delete(imageID string) error {
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
 
-	store.images[imageID.String()] = &ImageInfo{
-		LaptopID: laptopID,
-		Type:     imageType,
-		Path:     imagePath,
+	var unused []string
+	err := store.db.Update(func(tx *bolt.Tx) error {
+		info, err := store.getImage(tx, imageID)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return ErrNotFound
+		}
+
+		unused, err = deleteImage(tx, info)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	store.removeBlobs(unused)
+	return nil
+}
+
+// DeleteByLaptop removes all images of a laptop
+func (store *DiskImageStore) DeleteByLaptop(laptopID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var unused []string
+	err := store.db.Update(func(tx *bolt.Tx) error {
+		unused = nil
+		for _, imageID := range ownerImageIDs(tx, laptopImageBucket, laptopID) {
+			info, err := store.getImage(tx, imageID)
+			if err != nil {
+				return err
+			}
+			if info == nil {
+				continue
+			}
+
+			hashes, err := deleteImage(tx, info)
+			if err != nil {
+				return err
+			}
+			unused = append(unused, hashes...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	store.removeBlobs(unused)
+	return nil
+}
+
+func (store *DiskImageStore) SaveThumbnail(
+	imageID string,
+	thumbnail ThumbnailInfo,
+	thumbnailData io.Reader,
+) error {
+	blob, err := store.newBlobWriter()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(blob, thumbnailData)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err != nil {
+		store.abortBlob(blob)
+		return fmt.Errorf("cannot write thumbnail %w", err)
+	}
+
+	hash, err := store.commitBlob(blob)
+	if err != nil {
+		return err
+	}
+	thumbnail.Hash = hash
+	thumbnail.Size = blob.size
+
+	var unused []string
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		info, err := store.getImage(tx, imageID)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return ErrNotFound
+		}
+
+		_, err = addBlobReference(tx, hash, 1)
+		if err != nil {
+			return err
+		}
+
+		if other := info.Thumbnail(thumbnail.Name); other != nil {
+			unused, err = removeBlobReferences(tx, other.Hash)
+			if err != nil {
+				return err
+			}
+			*other = thumbnail
+		} else {
+			info.Thumbnails = append(info.Thumbnails, thumbnail)
+		}
+
+		return putImage(tx, info)
+	})
+	if err != nil {
+		// the blob is removed by the next garbage collection if it is not used
+		return err
+	}
+
+	store.removeBlobs(unused)
+	return nil
+}
+
+func (store *DiskImageStore) OpenThumbnail(imageID string, name string) (io.ReadCloser, error) {
+	info, err := store.Find(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil || info.Thumbnail(name) == nil {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(info.Thumbnail(name).Path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open thumbnail file %w", err)
+	}
+
+	return file, nil
+}
+
+// ImageURL returns ErrImageURLNotSupported, the images are only on the disk of the server
+func (store *DiskImageStore) ImageURL(imageID string, thumbnail string, expires time.Duration) (string, error) {
+	return "", ErrImageURLNotSupported
+}
+
+// CollectGarbage removes the blobs that no image references, which are left when the server
+// stops between writing a blob and indexing it or between unindexing a blob and removing it,
+// and the temporary files of the blobs no longer written, and returns the number of files removed
+func (store *DiskImageStore) CollectGarbage() (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	removed := 0
+	err := store.db.View(func(tx *bolt.Tx) error {
+		blobs := tx.Bucket(blobBucket)
+		return filepath.Walk(filepath.Join(store.imageFolder, "blobs"), func(path string, file os.FileInfo, err error) error {
+			if err != nil || file.IsDir() {
+				return err
+			}
+
+			name := file.Name()
+			if store.writing[path] || !strings.HasSuffix(name, ".tmp") && blobs.Get([]byte(name)) != nil {
+				return nil
+			}
+
+			err = os.Remove(path)
+			if err != nil {
+				return fmt.Errorf("cannot remove blob %w", err)
+			}
+
+			removed++
+			return nil
+		})
+	})
+	if err != nil {
+		return removed, err
+	}
+
+	return removed, nil
+}
+
+// blobWriter writes a blob to a temporary file and hashes it, the blob is renamed once written,
+// so that a blob is never partly written
+type blobWriter struct {
+	file *os.File
+	hash hash.Hash
+	size int
+}
+
+func (writer *blobWriter) Write(p []byte) (int, error) {
+	n, err := writer.file.Write(p)
+	writer.hash.Write(p[:n])
+	writer.size += n
+	return n, err
+}
+
+// newBlobWriter creates the temporary file of a blob, which CollectGarbage keeps until
+// the blob is committed or aborted
+func (store *DiskImageStore) newBlobWriter() (*blobWriter, error) {
+	file, err := ioutil.TempFile(filepath.Join(store.imageFolder, "blobs"), "blob.*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create blob file %w", err)
+	}
+
+	store.mutex.Lock()
+	store.writing[file.Name()] = true
+	store.mutex.Unlock()
+
+	return &blobWriter{file: file, hash: sha256.New()}, nil
+}
+
+// commitBlob moves the temporary file of a blob to the blob named by its hash, unless it
+// exists already, and returns the hash. The store must be locked.
+func (store *DiskImageStore) commitBlob(writer *blobWriter) (string, error) {
+	defer store.abortBlob(writer)
+
+	err := writer.file.Sync()
+	if closeErr := writer.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot write blob file %w", err)
+	}
+
+	hash := hex.EncodeToString(writer.hash.Sum(nil))
+	path := store.blobPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return "", fmt.Errorf("cannot create blob folder %w", err)
+	}
+
+	err = os.Rename(writer.file.Name(), path)
+	if err != nil {
+		return "", fmt.Errorf("cannot write blob file %w", err)
+	}
+
+	return hash, nil
+}
+
+// abortBlob removes the temporary file of a blob if it is still there. The store must be locked.
+func (store *DiskImageStore) abortBlob(writer *blobWriter) error {
+	delete(store.writing, writer.file.Name())
+	writer.file.Close()
+
+	err := os.Remove(writer.file.Name())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot remove blob file %w", err)
+	}
+
+	return nil
+}
+
+// removeBlobs removes the blobs no longer referenced, once the references are committed
+func (store *DiskImageStore) removeBlobs(hashes []string) {
+	for _, hash := range hashes {
+		err := os.Remove(store.blobPath(hash))
+		if err != nil && !os.IsNotExist(err) {
+			// the blob is removed by the next garbage collection
+			log.Printf("cannot remove blob %s: %v", hash, err)
+		}
+	}
+}
+
+func (store *DiskImageStore) blobPath(hash string) string {
+	return filepath.Join(store.imageFolder, "blobs", hash[:2], hash[2:4], hash)
+}
+
+// getImage returns nil if there is no image with this ID
+func (store *DiskImageStore) getImage(tx *bolt.Tx, imageID string) (*ImageInfo, error) {
+	data := tx.Bucket(imageBucket).Get([]byte(imageID))
+	if data == nil {
+		return nil, nil
+	}
+
+	info := &ImageInfo{}
+	err := json.Unmarshal(data, info)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal image %s: %w", imageID, err)
+	}
+
+	info.Path = store.blobPath(info.Hash)
+	for i := range info.Thumbnails {
+		info.Thumbnails[i].Path = store.blobPath(info.Thumbnails[i].Hash)
 	}
 
-	return imageID.String(), nil
+	return info, nil
+}
+
+func putImage(tx *bolt.Tx, info *ImageInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("cannot marshal image %w", err)
+	}
+
+	return tx.Bucket(imageBucket).Put([]byte(info.ID), data)
+}
+
+// deleteImage removes an image from the index and returns the hashes of the blobs no longer referenced
+func deleteImage(tx *bolt.Tx, info *ImageInfo) ([]string, error) {
+	err := tx.Bucket(imageBucket).Delete([]byte(info.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Bucket(laptopImageBucket).Delete(imageKey(info.LaptopID, info.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Bucket(uploaderImageBucket).Delete(imageKey(info.Uploader, info.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = addImageUsage(tx, info, -info.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := []string{info.Hash}
+	for _, thumbnail := range info.Thumbnails {
+		hashes = append(hashes, thumbnail.Hash)
+	}
+
+	return removeBlobReferences(tx, hashes...)
+}
+
+// removeBlobReferences removes a reference to every blob and returns the hashes of the blobs no longer referenced
+func removeBlobReferences(tx *bolt.Tx, hashes ...string) ([]string, error) {
+	var unused []string
+	for _, hash := range hashes {
+		count, err := addBlobReference(tx, hash, -1)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			unused = append(unused, hash)
+		}
+	}
+
+	return unused, nil
+}
+
+// addBlobReference adds delta to the number of references to a blob and returns it,
+// the blob is unindexed when it is no longer referenced
+func addBlobReference(tx *bolt.Tx, hash string, delta int64) (int64, error) {
+	bucket := tx.Bucket(blobBucket)
+
+	count := delta
+	if data := bucket.Get([]byte(hash)); data != nil {
+		count += int64(binary.BigEndian.Uint64(data))
+	}
+
+	if count <= 0 {
+		return 0, bucket.Delete([]byte(hash))
+	}
+
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(count))
+	return count, bucket.Put([]byte(hash), data)
+}
+
+// createImageUsageBucket creates the bucket of the total size of the images of every laptop
+// and of every uploader, adding up the images indexed before it existed
+func createImageUsageBucket(tx *bolt.Tx) error {
+	if tx.Bucket(imageUsageBucket) != nil {
+		return nil
+	}
+
+	_, err := tx.CreateBucket(imageUsageBucket)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(imageBucket).ForEach(func(key, data []byte) error {
+		info := &ImageInfo{}
+		err := json.Unmarshal(data, info)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal image %s: %w", key, err)
+		}
+
+		return addImageUsage(tx, info, info.Size)
+	})
+}
+
+// addImageUsage adds delta to the total size of the images of the laptop and of the uploader of an image
+func addImageUsage(tx *bolt.Tx, info *ImageInfo, delta int) error {
+	keys := [][]byte{laptopUsageKey(info.LaptopID)}
+	if info.Uploader != "" {
+		keys = append(keys, uploaderUsageKey(info.Uploader))
+	}
+
+	bucket := tx.Bucket(imageUsageBucket)
+	for _, key := range keys {
+		size := getImageUsage(bucket, key) + delta
+		if size <= 0 {
+			err := bucket.Delete(key)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, uint64(size))
+		err := bucket.Put(key, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getImageUsage(bucket *bolt.Bucket, key []byte) int {
+	data := bucket.Get(key)
+	if data == nil {
+		return 0
+	}
+
+	return int(binary.BigEndian.Uint64(data))
+}
+
+func laptopUsageKey(laptopID string) []byte {
+	return []byte("laptop/" + laptopID)
+}
+
+func uploaderUsageKey(uploader string) []byte {
+	return []byte("uploader/" + uploader)
+}
+
+// ownerImageIDs returns the IDs of the images of a laptop or of an uploader indexed in bucket
+func ownerImageIDs(tx *bolt.Tx, bucket []byte, owner string) []string {
+	prefix := imageKey(owner, "")
+
+	var imageIDs []string
+	cursor := tx.Bucket(bucket).Cursor()
+	for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+		imageIDs = append(imageIDs, string(key[len(prefix):]))
+	}
+
+	return imageIDs
+}
+
+// imageKey is the key of an image of a laptop or of an uploader
+func imageKey(owner, imageID string) []byte {
+	return []byte(owner + "/" + imageID)
+}
+
+// sortImages sorts images from the oldest to the newest
+func sortImages(images []*ImageInfo) {
+	sort.Slice(images, func(i, j int) bool {
+		if !images[i].CreatedAt.Equal(images[j].CreatedAt) {
+			return images[i].CreatedAt.Before(images[j].CreatedAt)
+		}
+		return images[i].ID < images[j].ID
+	})
 }
@@ -0,0 +1,200 @@
+package service_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/service"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestDiskImageStoreDeduplication(t *testing.T) {
+	t.Parallel()
+
+	imageFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(imageFolder) })
+
+	store, err := service.NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+
+	imageData := []byte("the same product shot")
+	thumbnailData := []byte("the same thumbnail")
+
+	var imageIDs []string
+	for _, laptopID := range []string{"laptop1", "laptop2", "laptop2"} {
+		imageID := saveTestImage(t, store, laptopID, ".png", "admin1", imageData)
+		imageIDs = append(imageIDs, imageID)
+
+		thumbnail := service.ThumbnailInfo{Name: "small", Type: ".png", Width: 1, Height: 1}
+		require.NoError(t, store.SaveThumbnail(imageID, thumbnail, bytes.NewReader(thumbnailData)))
+	}
+	require.Equal(t, 2, countTestBlobs(t, imageFolder))
+
+	first, err := store.Find(imageIDs[0])
+	require.NoError(t, err)
+	second, err := store.Find(imageIDs[1])
+	require.NoError(t, err)
+	require.Equal(t, first.Hash, second.Hash)
+	require.Equal(t, first.Path, second.Path)
+	require.Len(t, second.Thumbnails, 1)
+	require.Equal(t, len(thumbnailData), second.Thumbnails[0].Size)
+
+	// the index outlives the store
+	require.NoError(t, store.Close())
+	store, err = service.NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+	defer store.Close()
+
+	images, err := store.List("laptop2")
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	require.Equal(t, imageIDs[1:], []string{images[0].ID, images[1].ID})
+
+	require.NoError(t, store.Delete(imageIDs[0]))
+	require.Equal(t, service.ErrNotFound, store.Delete(imageIDs[0]))
+
+	uploaded, err := store.ListByUploader("admin1")
+	require.NoError(t, err)
+	require.Equal(t, imageIDs[1:], []string{uploaded[0].ID, uploaded[1].ID})
+	require.Equal(t, 2, countTestBlobs(t, imageFolder))
+
+	data, err := store.Open(imageIDs[1])
+	require.NoError(t, err)
+	saved, err := ioutil.ReadAll(data)
+	require.NoError(t, err)
+	require.NoError(t, data.Close())
+	require.Equal(t, imageData, saved)
+
+	require.NoError(t, store.DeleteByLaptop("laptop2"))
+	require.Equal(t, 0, countTestBlobs(t, imageFolder))
+
+	images, err = store.List("laptop2")
+	require.NoError(t, err)
+	require.Empty(t, images)
+}
+
+func TestDiskImageStoreUsage(t *testing.T) {
+	t.Parallel()
+
+	imageFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(imageFolder) })
+
+	store, err := service.NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+
+	imageID := saveTestImage(t, store, "laptop1", ".png", "admin1", []byte("image"))
+	saveTestImage(t, store, "laptop1", ".png", "admin2", []byte("other image"))
+	saveTestImage(t, store, "laptop2", ".png", "admin1", []byte("the same image"))
+
+	usage, err := store.Usage("laptop1", "admin1")
+	require.NoError(t, err)
+	require.Equal(t, service.ImageUsage{LaptopSize: 16, UploaderSize: 19}, usage)
+
+	require.NoError(t, store.Delete(imageID))
+	usage, err = store.Usage("laptop1", "admin1")
+	require.NoError(t, err)
+	require.Equal(t, service.ImageUsage{LaptopSize: 11, UploaderSize: 14}, usage)
+
+	// the usage of the images indexed before it was kept is added up when the store is opened
+	require.NoError(t, store.Close())
+	db, err := bolt.Open(filepath.Join(imageFolder, "images.db"), 0600, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket([]byte("image_usage"))
+	}))
+	require.NoError(t, db.Close())
+
+	store, err = service.NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+	defer store.Close()
+
+	usage, err = store.Usage("laptop1", "admin1")
+	require.NoError(t, err)
+	require.Equal(t, service.ImageUsage{LaptopSize: 11, UploaderSize: 14}, usage)
+
+	require.NoError(t, store.DeleteByLaptop("laptop1"))
+	usage, err = store.Usage("laptop1", "admin2")
+	require.NoError(t, err)
+	require.Equal(t, service.ImageUsage{}, usage)
+}
+
+func TestDiskImageStoreCollectGarbage(t *testing.T) {
+	t.Parallel()
+
+	imageFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(imageFolder) })
+
+	store, err := service.NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+	defer store.Close()
+
+	imageID := saveTestImage(t, store, "laptop1", ".png", "", []byte("image"))
+
+	// a thumbnail of an image deleted meanwhile leaves its blob behind
+	err = store.SaveThumbnail("unknown", service.ThumbnailInfo{Name: "small"}, strings.NewReader("thumbnail"))
+	require.Equal(t, service.ErrNotFound, err)
+
+	orphan := filepath.Join(imageFolder, "blobs", "ab", "cd", "abcd.1234.tmp")
+	require.NoError(t, os.MkdirAll(filepath.Dir(orphan), 0755))
+	require.NoError(t, ioutil.WriteFile(orphan, []byte("partly written"), 0644))
+	require.Equal(t, 3, countTestBlobs(t, imageFolder))
+
+	removed, err := store.CollectGarbage()
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+	require.Equal(t, 1, countTestBlobs(t, imageFolder))
+
+	info, err := store.Find(imageID)
+	require.NoError(t, err)
+	require.FileExists(t, info.Path)
+}
+
+func saveTestImage(
+	t *testing.T,
+	store service.ImageStore,
+	laptopID string,
+	imageType string,
+	uploader string,
+	imageData []byte,
+) string {
+	writer, err := store.Create(laptopID, imageType, uploader)
+	require.NoError(t, err)
+
+	// the data is written in chunks like an upload
+	for offset := 0; offset < len(imageData); offset += 1000 {
+		end := offset + 1000
+		if end > len(imageData) {
+			end = len(imageData)
+		}
+		_, err := writer.Write(imageData[offset:end])
+		require.NoError(t, err)
+	}
+
+	info, err := writer.Commit()
+	require.NoError(t, err)
+	require.Equal(t, len(imageData), info.Size)
+	require.NoError(t, writer.Abort())
+
+	return info.ID
+}
+
+func countTestBlobs(t *testing.T, imageFolder string) int {
+	count := 0
+	err := filepath.Walk(filepath.Join(imageFolder, "blobs"), func(path string, file os.FileInfo, err error) error {
+		if err == nil && !file.IsDir() {
+			count++
+		}
+		return err
+	})
+	require.NoError(t, err)
+
+	return count
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidOffset is returned when a chunk doesn't start at the committed offset of an upload
+var ErrInvalidOffset = errors.New("chunk offset is not the committed offset")
+
+// ImageUpload is an image being uploaded, Offset is the number of bytes committed so far
+type ImageUpload struct {
+	ID        string
+	LaptopID  string
+	ImageType string
+	Size      int
+	SHA256    string
+	Uploader  string
+	Offset    int
+	CreatedAt time.Time
+	// UpdatedAt is the time of the last append, expired uploads are deleted from it
+	UpdatedAt time.Time
+}
+
+// ImageUploadReader reads the committed data of an upload
+type ImageUploadReader interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// ImageUploadStore is an interface to store the images being uploaded
+type ImageUploadStore interface {
+	// Create saves a new upload and sets its ID
+	Create(upload *ImageUpload) error
+	// Find returns nil if there is no upload with this ID
+	Find(uploadID string) (*ImageUpload, error)
+	// Append commits data at the committed offset of the upload and returns the new offset,
+	// or returns ErrInvalidOffset
+	Append(uploadID string, offset int, data []byte) (int, error)
+	// Open returns the committed data of an upload
+	Open(uploadID string) (ImageUploadReader, error)
+	// Delete removes an upload, or returns ErrNotFound
+	Delete(uploadID string) error
+}
+
+// uploadCheckpointSize is how much data is appended to an upload between two checkpoints
+const uploadCheckpointSize = 4 << 20
+
+// DiskImageUploadStore keeps each upload in the folder as a data file and a JSON file with the upload.
+// Appends only sync the data file and rewrite the JSON file at checkpoints, every uploadCheckpointSize
+// bytes and once the upload is complete. After a restart the committed offset of an upload is the one
+// of its last checkpoint, and the client resumes the upload from there.
+type DiskImageUploadStore struct {
+	uploadFolder string
+	// locks serializes the calls on the same upload
+	locks keyMutex
+
+	mutex sync.Mutex
+	// active holds the uploads appended to since their last checkpoint, with offsets ahead of their JSON files
+	active map[string]*activeUpload
+}
+
+type activeUpload struct {
+	upload ImageUpload
+	// checkpoint is the offset saved in the JSON file
+	checkpoint int
+}
+
+func NewDiskImageUploadStore(uploadFolder string) (*DiskImageUploadStore, error) {
+	err := os.MkdirAll(uploadFolder, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create upload folder %w", err)
+	}
+
+	return &DiskImageUploadStore{
+		uploadFolder: uploadFolder,
+		active:       make(map[string]*activeUpload),
+	}, nil
+}
+
+func (store *DiskImageUploadStore) Create(upload *ImageUpload) error {
+	uploadID, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("cannot generate upload id %w", err)
+	}
+
+	other := *upload
+	other.ID = uploadID.String()
+	other.Offset = 0
+	other.UpdatedAt = other.CreatedAt
+
+	file, err := os.Create(store.dataPath(other.ID))
+	if err != nil {
+		return fmt.Errorf("cannot create upload file %w", err)
+	}
+	file.Close()
+
+	err = store.save(&other)
+	if err != nil {
+		return err
+	}
+
+	*upload = other
+	return nil
+}
+
+func (store *DiskImageUploadStore) Find(uploadID string) (*ImageUpload, error) {
+	unlock := store.locks.Lock(uploadID)
+	defer unlock()
+
+	return store.find(uploadID)
+}
+
+func (store *DiskImageUploadStore) Append(uploadID string, offset int, data []byte) (int, error) {
+	unlock := store.locks.Lock(uploadID)
+	defer unlock()
+
+	active, err := store.activeUpload(uploadID)
+	if err != nil {
+		return 0, err
+	}
+	if active == nil {
+		return 0, ErrNotFound
+	}
+
+	upload := &active.upload
+	if offset != upload.Offset {
+		return upload.Offset, fmt.Errorf("%w: %d, expected %d", ErrInvalidOffset, offset, upload.Offset)
+	}
+
+	file, err := os.OpenFile(store.dataPath(uploadID), os.O_WRONLY, 0644)
+	if err != nil {
+		return upload.Offset, fmt.Errorf("cannot open upload file %w", err)
+	}
+	defer file.Close()
+
+	if offset == active.checkpoint {
+		// drop what an interrupted append may have written after the checkpoint
+		err = file.Truncate(int64(offset))
+		if err != nil {
+			return upload.Offset, fmt.Errorf("cannot write upload file %w", err)
+		}
+	}
+
+	_, err = file.WriteAt(data, int64(offset))
+	if err != nil {
+		return upload.Offset, fmt.Errorf("cannot write upload file %w", err)
+	}
+
+	upload.Offset += len(data)
+	upload.UpdatedAt = time.Now()
+	if upload.Offset-active.checkpoint < uploadCheckpointSize && upload.Offset < upload.Size {
+		return upload.Offset, nil
+	}
+
+	err = file.Sync()
+	if err == nil {
+		err = store.save(upload)
+	}
+	if err != nil {
+		// resume from the last checkpoint, which is all that would survive a restart
+		upload.Offset = active.checkpoint
+		return upload.Offset, fmt.Errorf("cannot save upload checkpoint %w", err)
+	}
+
+	// the JSON file is up to date again
+	store.mutex.Lock()
+	delete(store.active, uploadID)
+	store.mutex.Unlock()
+
+	return upload.Offset, nil
+}
+
+func (store *DiskImageUploadStore) Open(uploadID string) (ImageUploadReader, error) {
+	unlock := store.locks.Lock(uploadID)
+	defer unlock()
+
+	upload, err := store.find(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload == nil {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(store.dataPath(uploadID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot open upload file %w", err)
+	}
+
+	return struct {
+		*io.SectionReader
+		io.Closer
+	}{io.NewSectionReader(file, 0, int64(upload.Offset)), file}, nil
+}
+
+func (store *DiskImageUploadStore) Delete(uploadID string) error {
+	unlock := store.locks.Lock(uploadID)
+	defer unlock()
+
+	upload, err := store.find(uploadID)
+	if err != nil {
+		return err
+	}
+	if upload == nil {
+		return ErrNotFound
+	}
+
+	return store.delete(uploadID)
+}
+
+// DeleteExpired deletes the uploads without any append for longer than ttl,
+// and the data files left without an upload by a crash, it returns the number of uploads deleted
+func (store *DiskImageUploadStore) DeleteExpired(ttl time.Duration) (int, error) {
+	files, err := ioutil.ReadDir(store.uploadFolder)
+	if err != nil {
+		return 0, fmt.Errorf("cannot list uploads %w", err)
+	}
+
+	expiry := time.Now().Add(-ttl)
+	deleted := 0
+	for _, file := range files {
+		uploadID := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		if _, err := uuid.Parse(uploadID); err != nil || filepath.Ext(file.Name()) != ".part" {
+			continue
+		}
+
+		expired, err := store.deleteIfExpired(uploadID, expiry)
+		if err != nil {
+			return deleted, err
+		}
+		if expired {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (store *DiskImageUploadStore) deleteIfExpired(uploadID string, expiry time.Time) (bool, error) {
+	unlock := store.locks.Lock(uploadID)
+	defer unlock()
+
+	upload, err := store.find(uploadID)
+	if err != nil {
+		return false, err
+	}
+
+	if upload == nil {
+		// Create crashed before saving the upload
+		info, err := os.Stat(store.dataPath(uploadID))
+		if err != nil || info.ModTime().After(expiry) {
+			return false, nil
+		}
+	} else if upload.UpdatedAt.After(expiry) {
+		return false, nil
+	}
+
+	return true, store.delete(uploadID)
+}
+
+// find returns the upload with the offset of its last append
+func (store *DiskImageUploadStore) find(uploadID string) (*ImageUpload, error) {
+	store.mutex.Lock()
+	active := store.active[uploadID]
+	store.mutex.Unlock()
+
+	if active != nil {
+		upload := active.upload
+		return &upload, nil
+	}
+
+	return store.load(uploadID)
+}
+
+// activeUpload returns the upload being appended to, loading it from its last checkpoint
+func (store *DiskImageUploadStore) activeUpload(uploadID string) (*activeUpload, error) {
+	store.mutex.Lock()
+	active := store.active[uploadID]
+	store.mutex.Unlock()
+
+	if active != nil {
+		return active, nil
+	}
+
+	upload, err := store.load(uploadID)
+	if err != nil || upload == nil {
+		return nil, err
+	}
+
+	active = &activeUpload{upload: *upload, checkpoint: upload.Offset}
+	store.mutex.Lock()
+	store.active[uploadID] = active
+	store.mutex.Unlock()
+
+	return active, nil
+}
+
+func (store *DiskImageUploadStore) delete(uploadID string) error {
+	store.mutex.Lock()
+	delete(store.active, uploadID)
+	store.mutex.Unlock()
+
+	err := os.Remove(store.infoPath(uploadID))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot remove upload %w", err)
+	}
+
+	err = os.Remove(store.dataPath(uploadID))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot remove upload file %w", err)
+	}
+
+	return nil
+}
+
+// load reads the upload as of its last checkpoint
+func (store *DiskImageUploadStore) load(uploadID string) (*ImageUpload, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(store.infoPath(uploadID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read upload %w", err)
+	}
+
+	upload := &ImageUpload{}
+	err = json.Unmarshal(data, upload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse upload %s: %w", uploadID, err)
+	}
+	if upload.UpdatedAt.IsZero() {
+		upload.UpdatedAt = upload.CreatedAt
+	}
+
+	return upload, nil
+}
+
+// save writes the upload to a temporary file renamed over the old one,
+// so that a crash leaves either the old or the new upload
+func (store *DiskImageUploadStore) save(upload *ImageUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal upload %w", err)
+	}
+
+	path := store.infoPath(upload.ID)
+	err = ioutil.WriteFile(path+".tmp", data, 0644)
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot save upload %w", err)
+	}
+
+	return nil
+}
+
+func (store *DiskImageUploadStore) dataPath(uploadID string) string {
+	return filepath.Join(store.uploadFolder, uploadID+".part")
+}
+
+func (store *DiskImageUploadStore) infoPath(uploadID string) string {
+	return filepath.Join(store.uploadFolder, uploadID+".json")
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/service"
+)
+
+func TestDiskImageUploadStoreCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	uploadFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(uploadFolder) })
+
+	store, err := service.NewDiskImageUploadStore(uploadFolder)
+	require.NoError(t, err)
+
+	const chunkSize = 1 << 20
+	data := bytes.Repeat([]byte("laptop"), (5*chunkSize+100)/6)
+	upload := &service.ImageUpload{LaptopID: "laptop1", ImageType: ".png", Size: len(data), CreatedAt: time.Now()}
+	require.NoError(t, store.Create(upload))
+
+	offset := 0
+	for i := 0; i < 3; i++ {
+		offset, err = store.Append(upload.ID, offset, data[offset:offset+chunkSize])
+		require.NoError(t, err)
+	}
+
+	_, err = store.Append(upload.ID, 0, data[:chunkSize])
+	require.True(t, errors.Is(err, service.ErrInvalidOffset))
+
+	found, err := store.Find(upload.ID)
+	require.NoError(t, err)
+	require.Equal(t, offset, found.Offset)
+
+	// a restarted server only knows the last checkpoint
+	restarted, err := service.NewDiskImageUploadStore(uploadFolder)
+	require.NoError(t, err)
+	found, err = restarted.Find(upload.ID)
+	require.NoError(t, err)
+	require.Zero(t, found.Offset)
+
+	offset, err = store.Append(upload.ID, offset, data[offset:offset+chunkSize])
+	require.NoError(t, err)
+	restarted, err = service.NewDiskImageUploadStore(uploadFolder)
+	require.NoError(t, err)
+	checkpoint, err := restarted.Find(upload.ID)
+	require.NoError(t, err)
+	require.Equal(t, 4*chunkSize, checkpoint.Offset)
+
+	// resuming from the checkpoint drops the data appended after it
+	offset, err = store.Append(upload.ID, offset, []byte("not the image"))
+	require.NoError(t, err)
+	offset, err = restarted.Append(upload.ID, checkpoint.Offset, data[checkpoint.Offset:])
+	require.NoError(t, err)
+	require.Equal(t, len(data), offset)
+
+	reader, err := service.NewDiskImageUploadStore(uploadFolder)
+	require.NoError(t, err)
+	file, err := reader.Open(upload.ID)
+	require.NoError(t, err)
+	saved, err := ioutil.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.Equal(t, data, saved)
+}
+
+func TestDiskImageUploadStoreDeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	uploadFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(uploadFolder) })
+
+	store, err := service.NewDiskImageUploadStore(uploadFolder)
+	require.NoError(t, err)
+
+	expired := &service.ImageUpload{LaptopID: "laptop1", ImageType: ".png", Size: 10, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	require.NoError(t, store.Create(expired))
+	resumed := &service.ImageUpload{LaptopID: "laptop1", ImageType: ".png", Size: 10, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	require.NoError(t, store.Create(resumed))
+	_, err = store.Append(resumed.ID, 0, []byte("lap"))
+	require.NoError(t, err)
+	recent := &service.ImageUpload{LaptopID: "laptop1", ImageType: ".png", Size: 10, CreatedAt: time.Now()}
+	require.NoError(t, store.Create(recent))
+
+	deleted, err := store.DeleteExpired(time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	found, err := store.Find(expired.ID)
+	require.NoError(t, err)
+	require.Nil(t, found)
+	for _, id := range []string{resumed.ID, recent.ID} {
+		found, err = store.Find(id)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+	}
+
+	files, err := ioutil.ReadDir(uploadFolder)
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+}
@@ -0,0 +1,43 @@
+package service
+
+import "sync"
+
+// keyMutex locks keys independently of each other.
+// The lock of a key is dropped once nobody holds or waits for it, the zero value is ready to use.
+type keyMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	// number of goroutines holding or waiting for the lock
+	count int
+}
+
+// Lock locks key and returns the function unlocking it
+func (m *keyMutex) Lock(key string) func() {
+	m.mutex.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyLock)
+	}
+	lock := m.locks[key]
+	if lock == nil {
+		lock = &keyLock{}
+		m.locks[key] = lock
+	}
+	lock.count++
+	m.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		m.mutex.Lock()
+		lock.count--
+		if lock.count == 0 {
+			delete(m.locks, key)
+		}
+		m.mutex.Unlock()
+	}
+}
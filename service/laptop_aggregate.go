@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gitlab.techschool.pcbook/pb"
+)
+
+// default histogram bucket widths
+const (
+	defaultPriceBucketUsd = 500
+	defaultYearBucket     = 1
+)
+
+// memoryUnitSuffixes are the short names of memory units, as used in the query language
+var memoryUnitSuffixes = map[pb.Memory_Unit]string{
+	pb.Memory_BIT:      "BIT",
+	pb.Memory_BYTE:     "B",
+	pb.Memory_KILOBYTE: "KB",
+	pb.Memory_MEGABYTE: "MB",
+	pb.Memory_GIGABYTE: "GB",
+	pb.Memory_TERABYTE: "TB",
+}
+
+// laptopAggregator counts facets and histogram buckets of laptops
+type laptopAggregator struct {
+	priceBucket float64
+	yearBucket  float64
+
+	total          uint32
+	brands         map[string]uint32
+	cpuBrands      map[string]uint32
+	gpuBrands      map[string]uint32
+	ramSizes       map[string]uint32
+	storageDrivers map[string]uint32
+	screenPanels   map[string]uint32
+	prices         map[float64]uint32
+	years          map[float64]uint32
+}
+
+func newLaptopAggregator(req *pb.AggregateLaptopsRequest) (*laptopAggregator, error) {
+	priceBucket := req.GetPriceBucketUsd()
+	if priceBucket < 0 || math.IsNaN(priceBucket) || math.IsInf(priceBucket, 0) {
+		return nil, fmt.Errorf("invalid price bucket %v", priceBucket)
+	}
+	if priceBucket == 0 {
+		priceBucket = defaultPriceBucketUsd
+	}
+
+	yearBucket := req.GetYearBucket()
+	if yearBucket == 0 {
+		yearBucket = defaultYearBucket
+	}
+
+	return &laptopAggregator{
+		priceBucket:    priceBucket,
+		yearBucket:     float64(yearBucket),
+		brands:         make(map[string]uint32),
+		cpuBrands:      make(map[string]uint32),
+		gpuBrands:      make(map[string]uint32),
+		ramSizes:       make(map[string]uint32),
+		storageDrivers: make(map[string]uint32),
+		screenPanels:   make(map[string]uint32),
+		prices:         make(map[float64]uint32),
+		years:          make(map[float64]uint32),
+	}, nil
+}
+
+func (aggregator *laptopAggregator) add(laptop *pb.Laptop) {
+	aggregator.total++
+	aggregator.brands[laptop.GetBrand()]++
+	aggregator.cpuBrands[laptop.GetCpu().GetBrand()]++
+	aggregator.ramSizes[formatMemory(normalizeMemory(laptop.GetRam()))]++
+	aggregator.screenPanels[laptop.GetScreen().GetPanel().String()]++
+
+	gpuBrands := make(map[string]bool)
+	for _, gpu := range laptop.GetGpus() {
+		gpuBrands[gpu.GetBrand()] = true
+	}
+	for brand := range gpuBrands {
+		aggregator.gpuBrands[brand]++
+	}
+
+	drivers := make(map[string]bool)
+	for _, storage := range laptop.GetStorages() {
+		drivers[storage.GetDriver().String()] = true
+	}
+	for driver := range drivers {
+		aggregator.storageDrivers[driver]++
+	}
+
+	aggregator.prices[bucketMin(laptop.GetPriceUsd(), aggregator.priceBucket)]++
+	aggregator.years[bucketMin(float64(laptop.GetReleaseYear()), aggregator.yearBucket)]++
+}
+
+func (aggregator *laptopAggregator) response() *pb.AggregateLaptopsResponse {
+	return &pb.AggregateLaptopsResponse{
+		Total:          aggregator.total,
+		Brands:         facetCounts(aggregator.brands),
+		CpuBrands:      facetCounts(aggregator.cpuBrands),
+		GpuBrands:      facetCounts(aggregator.gpuBrands),
+		RamSizes:       facetCounts(aggregator.ramSizes),
+		StorageDrivers: facetCounts(aggregator.storageDrivers),
+		ScreenPanels:   facetCounts(aggregator.screenPanels),
+		PriceHistogram: histogram(aggregator.prices, aggregator.priceBucket),
+		YearHistogram:  histogram(aggregator.years, aggregator.yearBucket),
+	}
+}
+
+// bucketMin returns the lower bound of the bucket containing value
+func bucketMin(value, width float64) float64 {
+	return math.Floor(value/width) * width
+}
+
+func facetCounts(counts map[string]uint32) []*pb.FacetCount {
+	facets := make([]*pb.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, &pb.FacetCount{Value: value, Count: count})
+	}
+
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return strings.Compare(facets[i].Value, facets[j].Value) < 0
+	})
+
+	return facets
+}
+
+func histogram(counts map[float64]uint32, width float64) []*pb.HistogramBucket {
+	buckets := make([]*pb.HistogramBucket, 0, len(counts))
+	for min, count := range counts {
+		buckets = append(buckets, &pb.HistogramBucket{Min: min, Max: min + width, Count: count})
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Min < buckets[j].Min
+	})
+
+	return buckets
+}
+
+// formatMemory returns memory in short form, for example 16GB
+func formatMemory(memory *pb.Memory) string {
+	return fmt.Sprintf("%d%s", memory.GetValue(), memoryUnitSuffixes[memory.GetUnit()])
+}
+
+// normalizeMemory returns memory in the largest unit holding it exactly, so that 16384MB becomes 16GB
+func normalizeMemory(memory *pb.Memory) *pb.Memory {
+	bits := toBit(memory)
+	if bits == 0 {
+		return memory
+	}
+
+	for unit := pb.Memory_TERABYTE; unit > pb.Memory_BIT; unit-- {
+		shift := memoryUnitShifts[memoryUnitSuffixes[unit]]
+		if bits%(1<<shift) == 0 {
+			return &pb.Memory{Value: bits >> shift, Unit: unit}
+		}
+	}
+
+	return &pb.Memory{Value: bits, Unit: pb.Memory_BIT}
+}
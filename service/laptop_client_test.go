@@ -2,20 +2,33 @@ package service_test
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/stretchr/testify/require"
 	"gitlab.techschool.pcbook/pb"
 	"gitlab.techschool.pcbook/sample"
 	"gitlab.techschool.pcbook/serializer"
 	"gitlab.techschool.pcbook/service"
+	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestClientCreateLaptop(t *testing.T) {
@@ -42,6 +55,9 @@ func TestClientCreateLaptop(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, other)
 
+	require.EqualValues(t, 1, other.Version)
+	laptop.Version = other.Version
+
 	json1, err := serializer.ProtobufToJSON(laptop)
 	require.NoError(t, err)
 
@@ -54,7 +70,7 @@ func TestClientCreateLaptop(t *testing.T) {
 func TestClientSearchLaptop(t *testing.T) {
 	t.Parallel()
 	filter := &pb.Filter{
-		MaxPriceUsd: 3000,
+		MaxPriceUsd: 2100,
 		MinCpuGhz:   2.2,
 		MinRam:      &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE},
 	}
@@ -68,6 +84,7 @@ func TestClientSearchLaptop(t *testing.T) {
 		case 0:
 			laptop.PriceUsd = 2500
 		case 1:
+			laptop.PriceUsd = 2200
 			laptop.Cpu.NumberCores = 2
 		case 2:
 			laptop.Cpu.MinGhz = 2.0
@@ -114,12 +131,791 @@ func TestClientSearchLaptop(t *testing.T) {
 		require.Contains(t, expectedIDs, res.GetLaptop().GetId())
 		found += 1
 	}
-	require.Equal(t, len(expectedIDs), 2)
+	require.Equal(t, len(expectedIDs), found)
+}
+
+func TestClientSearchLaptopFilter(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+
+	gaming := sample.NewLaptop()
+	gaming.Brand = "Dell"
+	gaming.PriceUsd = 2500
+	gaming.Cpu.NumberCores = 8
+	gaming.Cpu.NumberThreads = 16
+	gaming.Gpus = []*pb.GPU{{Brand: "NVIDIA", Memory: &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE}}}
+	gaming.Keyboard.Backlit = true
+
+	office := sample.NewLaptop()
+	office.Brand = "Lenovo"
+	office.PriceUsd = 900
+	office.Cpu.NumberCores = 2
+	office.Cpu.NumberThreads = 4
+	office.Gpus = []*pb.GPU{{Brand: "Intel", Memory: &pb.Memory{Value: 1, Unit: pb.Memory_GIGABYTE}}}
+	office.Keyboard.Backlit = false
+
+	for _, laptop := range []*pb.Laptop{gaming, office} {
+		require.NoError(t, laptopStore.Save(laptop))
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	testCases := []struct {
+		name   string
+		filter *pb.Filter
+		ids    []string
+	}{
+		{"price range", &pb.Filter{MinPriceUsd: 1000, MaxPriceUsd: 3000}, []string{gaming.Id}},
+		{"brands", &pb.Filter{Brands: []string{"lenovo", "apple"}}, []string{office.Id}},
+		{"cpu cores", &pb.Filter{MinCpuCores: 4}, []string{gaming.Id}},
+		{"cpu threads", &pb.Filter{MinCpuThreads: 4}, []string{gaming.Id, office.Id}},
+		{"gpu", &pb.Filter{
+			GpuBrands:    []string{"nvidia"},
+			MinGpuMemory: &pb.Memory{Value: 4096, Unit: pb.Memory_MEGABYTE},
+		}, []string{gaming.Id}},
+		{"keyboard backlit", &pb.Filter{KeyboardBacklit: &wrappers.BoolValue{Value: false}}, []string{office.Id}},
+		{"no match", &pb.Filter{Brands: []string{"dell"}, MaxPriceUsd: 1000}, nil},
+	}
+
+	for _, tc := range testCases {
+		req := &pb.SearchLaptopRequest{Filter: tc.filter}
+		laptops, _ := searchTestLaptopPage(t, laptopClient, req)
+
+		var ids []string
+		for _, laptop := range laptops {
+			ids = append(ids, laptop.Id)
+		}
+		require.ElementsMatch(t, tc.ids, ids, tc.name)
+	}
+}
+
+func TestClientSearchLaptopPage(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	ratingStore := service.NewInMemoryRatingStore()
+	prices := []float64{1000, 1500, 1500, 2000, 2500, 3000}
+	for _, price := range prices {
+		laptop := sample.NewLaptop()
+		laptop.PriceUsd = price
+		err := laptopStore.Save(laptop)
+		require.NoError(t, err)
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, ratingStore)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	req := &pb.SearchLaptopRequest{
+		Filter:   &pb.Filter{MaxPriceUsd: 5000},
+		OrderBy:  []*pb.SortOrder{{Key: pb.SortOrder_PRICE_USD, Descending: true}},
+		PageSize: 4,
+	}
+
+	laptops, token := searchTestLaptopPage(t, laptopClient, req)
+	require.Len(t, laptops, 4)
+	require.NotEmpty(t, token)
+	require.Equal(t, 3000.0, laptops[0].GetPriceUsd())
+	require.Equal(t, 1500.0, laptops[3].GetPriceUsd())
+
+	// laptops saved before the cursor must not shift the next page
+	for _, price := range []float64{1800, 1200} {
+		laptop := sample.NewLaptop()
+		laptop.PriceUsd = price
+		err := laptopStore.Save(laptop)
+		require.NoError(t, err)
+	}
+
+	req.PageToken = token
+	next, token := searchTestLaptopPage(t, laptopClient, req)
+	require.Empty(t, token)
+	require.Len(t, next, 3)
+	require.Equal(t, 1500.0, next[0].GetPriceUsd())
+	require.Equal(t, 1200.0, next[1].GetPriceUsd())
+	require.Equal(t, 1000.0, next[2].GetPriceUsd())
+	require.NotEqual(t, laptops[3].GetId(), next[0].GetId())
+
+	req.OrderBy[0].Descending = false
+	stream, err := laptopClient.SearchLaptop(context.Background(), req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func searchTestLaptopPage(
+	t *testing.T,
+	laptopClient pb.LaptopServiceClient,
+	req *pb.SearchLaptopRequest,
+) ([]*pb.Laptop, string) {
+	stream, err := laptopClient.SearchLaptop(context.Background(), req)
+	require.NoError(t, err)
+
+	var laptops []*pb.Laptop
+	token := ""
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return laptops, token
+		}
+
+		require.NoError(t, err)
+		laptops = append(laptops, res.GetLaptop())
+		token = res.GetNextPageToken()
+	}
+}
+
+func TestClientSearchLaptopQuery(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	laptop.Brand = "Dell"
+	laptop.PriceUsd = 1800
+	laptop.Ram = &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE}
+	laptop.Gpus = []*pb.GPU{{Brand: "AMD"}, {Brand: "NVIDIA"}}
+	laptop.Screen.Panel = pb.Screen_OLED
+	laptop.Keyboard.Backlit = true
+	laptop.Weight = &pb.Laptop_WeightLb{WeightLb: 4.4}
+	err := laptopStore.Save(laptop)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	testCases := []struct {
+		query string
+		found bool
+	}{
+		{`brand in ("Apple", "Dell") and ram >= 16GB and price_usd < 2000 and screen.panel = OLED`, true},
+		{`brand = apple or ram > 16384MB`, false},
+		{`not (brand = "Apple") and gpus.brand = NVIDIA`, true},
+		{`gpus.brand = intel`, false},
+		{`keyboard.backlit = true and weight_kg >= 1.9 and weight_lb <= 4.4`, true},
+		{`storages.driver = SSD and storages.memory >= 128GB`, true},
+	}
+
+	for _, tc := range testCases {
+		req := &pb.SearchLaptopRequest{Query: tc.query}
+		laptops, _ := searchTestLaptopPage(t, laptopClient, req)
+		require.Equal(t, tc.found, len(laptops) == 1, tc.query)
+	}
+
+	invalidQueries := map[string]string{
+		`ram >= 16`:                      `position 8 near "16"`,
+		`brand == "Dell" and colour = 1`: `position 21 near "colour"`,
+		`screen.panel < OLED`:            `position 14 near "<"`,
+		`brand in ("Dell"`:               `end of query`,
+	}
+
+	for query, message := range invalidQueries {
+		stream, err := laptopClient.SearchLaptop(context.Background(), &pb.SearchLaptopRequest{Query: query})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err), query)
+		require.Contains(t, status.Convert(err).Message(), message)
+	}
+}
+
+func TestClientSearchLaptopText(t *testing.T) {
+	t.Parallel()
+
+	laptopStore, err := service.NewIndexedLaptopStore(service.NewInMemoryLaptopStore())
+	require.NoError(t, err)
+
+	thinkpad := sample.NewLaptop()
+	thinkpad.Brand = "Lenovo"
+	thinkpad.Name = "Thinkpad P1"
+	thinkpad.Cpu.Brand = "AMD"
+	thinkpad.Cpu.Name = "Ryzen 7 PRO 2700U"
+	thinkpad.PriceUsd = 2000
+
+	macbook := sample.NewLaptop()
+	macbook.Brand = "Apple"
+	macbook.Name = "Macbook Pro"
+	macbook.Cpu.Brand = "Intel"
+	macbook.Cpu.Name = "Core i7-8550U"
+	macbook.PriceUsd = 2500
+
+	ideapad := sample.NewLaptop()
+	ideapad.Brand = "Lenovo"
+	ideapad.Name = "Ideapad 5"
+	ideapad.Cpu.Brand = "AMD"
+	ideapad.Cpu.Name = "Ryzen 5 PRO 3500U"
+	ideapad.PriceUsd = 800
+
+	for _, laptop := range []*pb.Laptop{thinkpad, macbook, ideapad} {
+		require.NoError(t, laptopStore.Save(laptop))
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	testCases := []struct {
+		name   string
+		text   string
+		filter *pb.Filter
+		ids    []string
+	}{
+		{"exact", "thinkpad ryzen 7", nil, []string{thinkpad.Id}},
+		{"case insensitive", "LENOVO ideapad", nil, []string{ideapad.Id}},
+		{"prefix", "mac", nil, []string{macbook.Id}},
+		{"typo", "thinkpda", nil, []string{thinkpad.Id}},
+		{"name before cpu", "pro", nil, []string{macbook.Id, ideapad.Id, thinkpad.Id}},
+		{"with filter", "lenovo", &pb.Filter{MaxPriceUsd: 1000}, []string{ideapad.Id}},
+		{"no match", "dell", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		req := &pb.SearchLaptopRequest{Text: tc.text, Filter: tc.filter}
+		laptops, _ := searchTestLaptopPage(t, laptopClient, req)
+
+		var ids []string
+		for _, laptop := range laptops {
+			ids = append(ids, laptop.Id)
+		}
+		require.ElementsMatch(t, tc.ids, ids, tc.name)
+		if len(ids) > 0 {
+			require.Equal(t, tc.ids[0], ids[0], tc.name)
+		}
+	}
+
+	require.NoError(t, laptopStore.Delete(macbook.Id, 0))
+	laptops, _ := searchTestLaptopPage(t, laptopClient, &pb.SearchLaptopRequest{Text: "macbook"})
+	require.Empty(t, laptops)
+
+	other := service.NewInMemoryLaptopStore()
+	serverAddress = startTestLaptopServer(t, other, nil, nil)
+	laptopClient = newTestLaptopClient(t, serverAddress)
+
+	stream, err := laptopClient.SearchLaptop(context.Background(), &pb.SearchLaptopRequest{Text: "lenovo"})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestClientSearchLaptopTextPage(t *testing.T) {
+	t.Parallel()
+
+	laptopStore, err := service.NewIndexedLaptopStore(service.NewInMemoryLaptopStore())
+	require.NoError(t, err)
+
+	newLaptop := func(brand, name string) *pb.Laptop {
+		laptop := sample.NewLaptop()
+		laptop.Brand = brand
+		laptop.Name = name
+		laptop.Cpu.Brand = "Intel"
+		laptop.Cpu.Name = "Core i7"
+		laptop.Gpus = nil
+		require.NoError(t, laptopStore.Save(laptop))
+		return laptop
+	}
+
+	var ids []string
+	for _, name := range []string{"Pro Pro Pro", "Pro Pro", "Pro", "Pro Book"} {
+		ids = append(ids, newLaptop("Acme", name).Id)
+	}
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	req := &pb.SearchLaptopRequest{Text: "pro", PageSize: 2}
+	laptops, token := searchTestLaptopPage(t, laptopClient, req)
+	require.Len(t, laptops, 2)
+	require.Equal(t, ids[0], laptops[0].Id)
+	require.Equal(t, ids[1], laptops[1].Id)
+	require.NotEmpty(t, token)
+
+	// new laptops change how rare "pro" is, the next page must still be
+	// scored like the first one so no laptop is skipped or repeated
+	for i := 0; i < 20; i++ {
+		newLaptop("Dell", "XPS")
+	}
+	newLaptop("Acme", "Pro Pro Pro Pro")
+
+	req.PageToken = token
+	next, token := searchTestLaptopPage(t, laptopClient, req)
+	require.Empty(t, token)
+	require.Len(t, next, 2)
+	require.ElementsMatch(t, ids[2:], []string{next[0].Id, next[1].Id})
+}
+
+func TestClientAggregateLaptops(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	newLaptop := func(brand string, priceUsd float64, releaseYear uint32, ram *pb.Memory) *pb.Laptop {
+		laptop := sample.NewLaptop()
+		laptop.Brand = brand
+		laptop.PriceUsd = priceUsd
+		laptop.ReleaseYear = releaseYear
+		laptop.Ram = ram
+		laptop.Cpu.Brand = "Intel"
+		laptop.Gpus = []*pb.GPU{{Brand: "NVIDIA"}, {Brand: "NVIDIA"}}
+		laptop.Storages = []*pb.Storage{{Driver: pb.Storage_SSD}}
+		laptop.Screen.Panel = pb.Screen_IPS
+		require.NoError(t, laptopStore.Save(laptop))
+		return laptop
+	}
+
+	newLaptop("Dell", 900, 2018, &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE})
+	newLaptop("Dell", 1200, 2019, &pb.Memory{Value: 16, Unit: pb.Memory_GIGABYTE})
+	// the same size as 16GB
+	newLaptop("Lenovo", 1400, 2019, &pb.Memory{Value: 16384, Unit: pb.Memory_MEGABYTE})
+	newLaptop("Apple", 3000, 2020, &pb.Memory{Value: 32, Unit: pb.Memory_GIGABYTE})
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	req := &pb.AggregateLaptopsRequest{
+		Filter:         &pb.Filter{MaxPriceUsd: 2000},
+		PriceBucketUsd: 1000,
+		YearBucket:     2,
+	}
+	res, err := laptopClient.AggregateLaptops(context.Background(), req)
+	require.NoError(t, err)
+
+	require.EqualValues(t, 3, res.Total)
+	require.Equal(t, []*pb.FacetCount{{Value: "Dell", Count: 2}, {Value: "Lenovo", Count: 1}}, res.Brands)
+	require.Equal(t, []*pb.FacetCount{{Value: "Intel", Count: 3}}, res.CpuBrands)
+	require.Equal(t, []*pb.FacetCount{{Value: "NVIDIA", Count: 3}}, res.GpuBrands)
+	require.Equal(t, []*pb.FacetCount{{Value: "16GB", Count: 2}, {Value: "8GB", Count: 1}}, res.RamSizes)
+	require.Equal(t, []*pb.FacetCount{{Value: "SSD", Count: 3}}, res.StorageDrivers)
+	require.Equal(t, []*pb.FacetCount{{Value: "IPS", Count: 3}}, res.ScreenPanels)
+	require.Equal(t, []*pb.HistogramBucket{{Min: 0, Max: 1000, Count: 1}, {Min: 1000, Max: 2000, Count: 2}}, res.PriceHistogram)
+	require.Equal(t, []*pb.HistogramBucket{{Min: 2018, Max: 2020, Count: 3}}, res.YearHistogram)
+
+	res, err = laptopClient.AggregateLaptops(context.Background(), &pb.AggregateLaptopsRequest{})
+	require.NoError(t, err)
+	require.EqualValues(t, 4, res.Total)
+	require.Len(t, res.YearHistogram, 3)
+	require.Len(t, res.PriceHistogram, 3)
+
+	_, err = laptopClient.AggregateLaptops(context.Background(), &pb.AggregateLaptopsRequest{PriceBucketUsd: -1})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClientWatchLaptops(t *testing.T) {
+	t.Parallel()
+
+	laptopEvents := service.NewLaptopEventBus(10)
+	laptopStore := service.NewEventLaptopStore(service.NewInMemoryLaptopStore(), laptopEvents)
+	laptopServer := service.NewLaptopServer(laptopStore, nil, nil, nil, nil, laptopEvents)
+	serverAddress := serveTestLaptopServer(t, laptopServer)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := &pb.WatchLaptopsRequest{Filter: &pb.Filter{Brands: []string{"Dell"}}}
+	stream, err := laptopClient.WatchLaptops(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	dell := sample.NewLaptop()
+	dell.Brand = "Dell"
+	apple := sample.NewLaptop()
+	apple.Brand = "Apple"
+	require.NoError(t, laptopStore.Save(dell))
+	require.NoError(t, laptopStore.Save(apple))
+
+	dell.Version = 1
+	dell.PriceUsd = 999
+	require.NoError(t, laptopStore.Update(dell))
+	require.NoError(t, laptopStore.Delete(dell.Id, 0))
+
+	expected := []pb.LaptopEvent_Type{pb.LaptopEvent_CREATED, pb.LaptopEvent_UPDATED, pb.LaptopEvent_DELETED}
+	var events []*pb.LaptopEvent
+	for range expected {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		events = append(events, res.GetEvent())
+	}
+
+	for i, event := range events {
+		require.Equal(t, expected[i], event.GetType())
+		require.Equal(t, dell.Id, event.GetLaptop().GetId())
+		require.NotEmpty(t, event.GetResumeToken())
+	}
+	require.EqualValues(t, 1, events[0].GetLaptop().GetVersion())
+	require.Equal(t, 999.0, events[1].GetLaptop().GetPriceUsd())
+	require.EqualValues(t, 1, events[1].GetPrevious().GetVersion())
+
+	req.ResumeToken = events[0].GetResumeToken()
+	stream, err = laptopClient.WatchLaptops(ctx, req)
+	require.NoError(t, err)
+	for _, event := range events[1:] {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, event.GetResumeToken(), res.GetEvent().GetResumeToken())
+	}
+
+	otherEvents := service.NewLaptopEventBus(10)
+	_, otherChannel, cancelOther, err := otherEvents.Subscribe("")
+	require.NoError(t, err)
+	defer cancelOther()
+	require.NoError(t, otherEvents.Publish(&pb.LaptopEvent{Type: pb.LaptopEvent_CREATED, Laptop: sample.NewLaptop()}))
+	otherEvent := <-otherChannel
+
+	// push the first events out of the history
+	for i := 0; i < 10; i++ {
+		require.NoError(t, laptopStore.Save(sample.NewLaptop()))
+	}
+
+	testCases := []struct {
+		name  string
+		token string
+		code  codes.Code
+	}{
+		{"malformed token", "not a token", codes.InvalidArgument},
+		{"token of another server", otherEvent.GetResumeToken(), codes.OutOfRange},
+		{"expired token", events[0].GetResumeToken(), codes.OutOfRange},
+	}
+
+	for _, tc := range testCases {
+		stream, err := laptopClient.WatchLaptops(ctx, &pb.WatchLaptopsRequest{ResumeToken: tc.token})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, tc.code, status.Code(err), tc.name)
+	}
+}
+
+func TestClientWatchLaptopsLeavingFilter(t *testing.T) {
+	t.Parallel()
+
+	laptopEvents := service.NewLaptopEventBus(10)
+	laptopStore := service.NewEventLaptopStore(service.NewInMemoryLaptopStore(), laptopEvents)
+	laptopServer := service.NewLaptopServer(laptopStore, nil, nil, nil, nil, laptopEvents)
+	serverAddress := serveTestLaptopServer(t, laptopServer)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := &pb.WatchLaptopsRequest{Filter: &pb.Filter{Brands: []string{"Dell"}}}
+	stream, err := laptopClient.WatchLaptops(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	laptop.Brand = "Dell"
+	require.NoError(t, laptopStore.Save(laptop))
+
+	// the update moving the laptop out of the filter is sent, the ones after it are not
+	laptop.Version = 1
+	laptop.Brand = "Apple"
+	require.NoError(t, laptopStore.Update(laptop))
+	laptop.PriceUsd = 999
+	require.NoError(t, laptopStore.Update(laptop))
+
+	other := sample.NewLaptop()
+	other.Brand = "Dell"
+	require.NoError(t, laptopStore.Save(other))
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, pb.LaptopEvent_CREATED, res.GetEvent().GetType())
+
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, pb.LaptopEvent_UPDATED, res.GetEvent().GetType())
+	require.Equal(t, "Apple", res.GetEvent().GetLaptop().GetBrand())
+	require.Equal(t, "Dell", res.GetEvent().GetPrevious().GetBrand())
+
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, pb.LaptopEvent_CREATED, res.GetEvent().GetType())
+	require.Equal(t, other.Id, res.GetEvent().GetLaptop().GetId())
+}
+func TestClientBatchCreateLaptops(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	existing := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(existing))
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	noBrand := sample.NewLaptop()
+	noBrand.Brand = ""
+	badID := sample.NewLaptop()
+	badID.Id = "invalid-uuid"
+	first := sample.NewLaptop()
+	repeated := sample.NewLaptop()
+	repeated.Id = first.Id
+	noID := sample.NewLaptop()
+	noID.Id = ""
+
+	testCases := []struct {
+		name          string
+		laptops       []*pb.Laptop
+		transactional bool
+		statuses      []pb.BatchCreateLaptopResult_Outcome
+	}{
+		{
+			name:    "each",
+			laptops: []*pb.Laptop{first, existing, noBrand, badID, repeated, noID},
+			statuses: []pb.BatchCreateLaptopResult_Outcome{
+				pb.BatchCreateLaptopResult_CREATED,
+				pb.BatchCreateLaptopResult_DUPLICATE,
+				pb.BatchCreateLaptopResult_INVALID,
+				pb.BatchCreateLaptopResult_INVALID,
+				pb.BatchCreateLaptopResult_DUPLICATE,
+				pb.BatchCreateLaptopResult_CREATED,
+			},
+		},
+		{
+			name:          "rolled back",
+			laptops:       []*pb.Laptop{sample.NewLaptop(), existing, sample.NewLaptop()},
+			transactional: true,
+			statuses: []pb.BatchCreateLaptopResult_Outcome{
+				pb.BatchCreateLaptopResult_ROLLED_BACK,
+				pb.BatchCreateLaptopResult_DUPLICATE,
+				pb.BatchCreateLaptopResult_ROLLED_BACK,
+			},
+		},
+		{
+			name:          "all",
+			laptops:       []*pb.Laptop{sample.NewLaptop(), sample.NewLaptop()},
+			transactional: true,
+			statuses: []pb.BatchCreateLaptopResult_Outcome{
+				pb.BatchCreateLaptopResult_CREATED,
+				pb.BatchCreateLaptopResult_CREATED,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		stream, err := laptopClient.BatchCreateLaptops(context.Background())
+		require.NoError(t, err)
+
+		for _, laptop := range tc.laptops {
+			req := &pb.BatchCreateLaptopsRequest{Laptop: laptop, Transactional: tc.transactional}
+			require.NoError(t, stream.Send(req))
+		}
+
+		res, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		require.Len(t, res.GetResults(), len(tc.statuses), tc.name)
+
+		created := 0
+		for i, result := range res.GetResults() {
+			require.EqualValues(t, i, result.GetIndex())
+			require.Equal(t, tc.statuses[i], result.GetStatus(), "%s: laptop %d", tc.name, i)
+
+			found, err := laptopStore.Find(result.GetId())
+			require.NoError(t, err)
+			if result.GetStatus() == pb.BatchCreateLaptopResult_CREATED {
+				created++
+				require.NotNil(t, found)
+			}
+			if result.GetStatus() == pb.BatchCreateLaptopResult_ROLLED_BACK {
+				require.Nil(t, found)
+			}
+		}
+		require.EqualValues(t, created, res.GetCreatedCount())
+	}
+}
+
+func TestClientGetLaptop(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	err := laptopStore.Save(laptop)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	res, err := laptopClient.GetLaptop(context.Background(), &pb.GetLaptopRequest{Id: laptop.Id})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, res.GetLaptop().GetVersion())
+	laptop.Version = 1
+	require.True(t, proto.Equal(laptop, res.GetLaptop()))
+
+	_, err = laptopClient.GetLaptop(context.Background(), &pb.GetLaptopRequest{Id: sample.NewLaptop().Id})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClientUpdateLaptop(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	err := laptopStore.Save(laptop)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	changes := &pb.Laptop{
+		Id:       laptop.Id,
+		Name:     "Thinkpad X1 Carbon",
+		PriceUsd: 1234,
+		Cpu:      &pb.CPU{MinGhz: 3.1},
+	}
+	req := &pb.UpdateLaptopRequest{
+		Laptop:     changes,
+		UpdateMask: &field_mask.FieldMask{Paths: []string{"name", "price_usd", "cpu.min_ghz"}},
+	}
+
+	res, err := laptopClient.UpdateLaptop(context.Background(), req)
+	require.NoError(t, err)
+
+	other, err := laptopStore.Find(laptop.Id)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(other, res.GetLaptop()))
+	require.Equal(t, "Thinkpad X1 Carbon", other.GetName())
+	require.Equal(t, 1234.0, other.GetPriceUsd())
+	require.Equal(t, 3.1, other.GetCpu().GetMinGhz())
+	require.Equal(t, laptop.GetBrand(), other.GetBrand())
+	require.Equal(t, laptop.GetCpu().GetName(), other.GetCpu().GetName())
+	require.True(t, other.GetUpdatedAt().AsTime().After(laptop.GetUpdatedAt().AsTime()))
+	require.EqualValues(t, 2, other.GetVersion())
+
+	changes.Version = 1
+	_, err = laptopClient.UpdateLaptop(context.Background(), req)
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	changes.Version = 0
+	ctx := metadata.AppendToOutgoingContext(context.Background(), service.IfMatchKey, `"1"`)
+	_, err = laptopClient.UpdateLaptop(ctx, req)
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), service.IfMatchKey, `"2"`)
+	res, err = laptopClient.UpdateLaptop(ctx, req)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, res.GetLaptop().GetVersion())
+
+	req.UpdateMask.Paths = []string{"id"}
+	_, err = laptopClient.UpdateLaptop(context.Background(), req)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	req.UpdateMask.Paths = []string{"cpu.unknown"}
+	_, err = laptopClient.UpdateLaptop(context.Background(), req)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	req.Laptop = sample.NewLaptop()
+	req.UpdateMask = nil
+	_, err = laptopClient.UpdateLaptop(context.Background(), req)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClientDeleteLaptop(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	ratingStore := service.NewInMemoryRatingStore()
+	laptop := sample.NewLaptop()
+	err := laptopStore.Save(laptop)
+	require.NoError(t, err)
+	_, err = ratingStore.Add(laptop.Id, 8)
+	require.NoError(t, err)
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, ratingStore)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	req := &pb.DeleteLaptopRequest{Id: laptop.Id, Version: 2}
+	_, err = laptopClient.DeleteLaptop(context.Background(), req)
+	require.Equal(t, codes.Aborted, status.Code(err))
+	rating, err := ratingStore.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), rating.Count)
+
+	req.Version = 1
+	_, err = laptopClient.DeleteLaptop(context.Background(), req)
+	require.NoError(t, err)
+
+	other, err := laptopStore.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	rating, err = ratingStore.Add(laptop.Id, 5)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), rating.Count)
+
+	_, err = laptopClient.DeleteLaptop(context.Background(), req)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// failingRatingStore fails the first deleteFailures deletes of ratings
+type failingRatingStore struct {
+	*service.InMemoryRatingStore
+	deleteFailures int32
+}
+
+func (store *failingRatingStore) Delete(laptopID string) error {
+	if atomic.AddInt32(&store.deleteFailures, -1) >= 0 {
+		return errors.New("rating store is down")
+	}
+
+	return store.InMemoryRatingStore.Delete(laptopID)
+}
+
+func TestClientDeleteLaptopRetry(t *testing.T) {
+	t.Parallel()
+
+	laptopStore := service.NewInMemoryLaptopStore()
+	ratingStore := &failingRatingStore{service.NewInMemoryRatingStore(), 1}
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+
+	serverAddress := startTestLaptopServer(t, laptopStore, nil, ratingStore)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	// a failed cleanup keeps the laptop, so the delete can be retried
+	req := &pb.DeleteLaptopRequest{Id: laptop.Id}
+	_, err := laptopClient.DeleteLaptop(context.Background(), req)
+	require.Equal(t, codes.Internal, status.Code(err))
+	other, err := laptopStore.Find(laptop.Id)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+
+	_, err = laptopClient.DeleteLaptop(context.Background(), req)
+	require.NoError(t, err)
+	other, err = laptopStore.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Nil(t, other)
+}
+
+func newTestImageStore(t *testing.T) *service.DiskImageStore {
+	imageFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(imageFolder) })
+
+	imageStore, err := service.NewDiskImageStore(imageFolder)
+	require.NoError(t, err)
+	t.Cleanup(func() { imageStore.Close() })
+
+	return imageStore
+}
+
+func newTestUploadStore(t *testing.T) *service.DiskImageUploadStore {
+	uploadFolder, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(uploadFolder) })
+
+	uploadStore, err := service.NewDiskImageUploadStore(uploadFolder)
+	require.NoError(t, err)
+
+	return uploadStore
 }
 
 func startTestLaptopServer(t *testing.T, laptopStore service.LaptopStore, imageStore service.ImageStore, ratingStore service.RatingStore) string {
-	laptopServer := service.NewLaptopServer(laptopStore, imageStore, ratingStore)
+	// the users are not authenticated, so the quota of the empty role applies to all uploads
+	imageQuotas := map[string]service.ImageQuota{"": {}}
+	laptopServer := service.NewLaptopServer(laptopStore, imageStore, newTestUploadStore(t), imageQuotas, ratingStore, nil)
+	return serveTestLaptopServer(t, laptopServer)
+}
 
+func serveTestLaptopServer(t *testing.T, laptopServer *service.LaptopServer) string {
 	grpcServer := grpc.NewServer()
 	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
 
@@ -135,7 +931,7 @@ func TestClientUploadImage(t *testing.T) {
 	t.Parallel()
 	testImageFolder := "../tmp"
 	laptopStore := service.NewInMemoryLaptopStore()
-	imageStore := service.NewDiskImageStore(testImageFolder)
+	imageStore := newTestImageStore(t)
 
 	laptop := sample.NewLaptop()
 	err := laptopStore.Save(laptop)
@@ -156,7 +952,7 @@ func TestClientUploadImage(t *testing.T) {
 		Data: &pb.UploadImageRequest_Info{
 			Info: &pb.ImageInfo{
 				LaptopId:  laptop.GetId(),
-				ImageType: imagePath,
+				ImageType: imageType,
 			},
 		},
 	}
@@ -190,9 +986,386 @@ func TestClientUploadImage(t *testing.T) {
 	require.NotZero(t, res.Id)
 	require.EqualValues(t, size, res.GetSize())
 
-	savedImagePath := fmt.Sprintf("%s/%s%s", testImageFolder, res.GetId(), imageType)
-	require.FileExists(t, savedImagePath)
-	require.NoError(t, os.Remove(savedImagePath))
+	info, err := imageStore.Find(res.GetId())
+	require.NoError(t, err)
+	require.Equal(t, imageType, info.Type)
+	require.FileExists(t, info.Path)
+	require.FileExists(t, info.Thumbnail("small").Path)
+	require.NoError(t, imageStore.Delete(res.GetId()))
+	require.NoFileExists(t, info.Path)
+}
+
+func TestClientResumeImageUpload(t *testing.T) {
+	t.Parallel()
+	laptopStore := service.NewInMemoryLaptopStore()
+	imageStore := newTestImageStore(t)
+
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	imageData, err := ioutil.ReadFile("../tmp/laptop.png")
+	require.NoError(t, err)
+	checksum := sha256.Sum256(imageData)
+
+	info := &pb.ImageInfo{
+		LaptopId:  laptop.GetId(),
+		ImageType: ".png",
+		Size:      uint32(len(imageData)),
+		Sha256:    hex.EncodeToString(checksum[:]),
+	}
+	createRes, err := laptopClient.CreateImageUpload(context.Background(), &pb.CreateImageUploadRequest{Info: info})
+	require.NoError(t, err)
+	uploadID := createRes.GetUpload().GetId()
+	require.NotEmpty(t, uploadID)
+	require.Zero(t, createRes.GetUpload().GetOffset())
+
+	half := len(imageData) / 2
+	_, err = uploadTestImage(t, context.Background(), laptopClient, uploadID, 0, imageData[:half])
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	getRes, err := laptopClient.GetImageUpload(context.Background(), &pb.GetImageUploadRequest{UploadId: uploadID})
+	require.NoError(t, err)
+	require.EqualValues(t, half, getRes.GetUpload().GetOffset())
+
+	_, err = uploadTestImage(t, context.Background(), laptopClient, uploadID, half+1, imageData[half+1:])
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// the chunk overlapping the committed data is only written from the committed offset
+	res, err := uploadTestImage(t, context.Background(), laptopClient, uploadID, half-100, imageData[half-100:])
+	require.NoError(t, err)
+	require.EqualValues(t, len(imageData), res.GetSize())
+
+	data, err := imageStore.Open(res.GetId())
+	require.NoError(t, err)
+	defer data.Close()
+	saved, err := ioutil.ReadAll(data)
+	require.NoError(t, err)
+	require.Equal(t, imageData, saved)
+
+	_, err = laptopClient.GetImageUpload(context.Background(), &pb.GetImageUploadRequest{UploadId: uploadID})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	info.Sha256 = hex.EncodeToString(make([]byte, sha256.Size))
+	createRes, err = laptopClient.CreateImageUpload(context.Background(), &pb.CreateImageUploadRequest{Info: info})
+	require.NoError(t, err)
+	uploadID = createRes.GetUpload().GetId()
+
+	_, err = uploadTestImage(t, context.Background(), laptopClient, uploadID, 0, imageData)
+	require.Equal(t, codes.DataLoss, status.Code(err))
+	_, err = laptopClient.GetImageUpload(context.Background(), &pb.GetImageUploadRequest{UploadId: uploadID})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	info.Sha256 = ""
+	_, err = laptopClient.CreateImageUpload(context.Background(), &pb.CreateImageUploadRequest{Info: info})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClientUploadImageValidation(t *testing.T) {
+	t.Parallel()
+	laptopStore := service.NewInMemoryLaptopStore()
+	imageStore := newTestImageStore(t)
+
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	imageData, err := ioutil.ReadFile("../tmp/laptop.png")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		imageType string
+		data      []byte
+		code      codes.Code
+	}{
+		{"png", ".png", imageData, codes.OK},
+		{"no type", "", imageData, codes.OK},
+		{"mismatch", ".jpg", imageData, codes.InvalidArgument},
+		{"not an image", ".png", []byte("#!/bin/sh\nrm -rf /\n"), codes.InvalidArgument},
+		{"unsupported type", ".svg", imageData, codes.InvalidArgument},
+	}
+
+	for _, tc := range testCases {
+		checksum := sha256.Sum256(tc.data)
+		info := &pb.ImageInfo{
+			LaptopId:  laptop.GetId(),
+			ImageType: tc.imageType,
+			Size:      uint32(len(tc.data)),
+			Sha256:    hex.EncodeToString(checksum[:]),
+		}
+
+		createRes, err := laptopClient.CreateImageUpload(context.Background(), &pb.CreateImageUploadRequest{Info: info})
+		if err == nil {
+			_, err = uploadTestImage(t, context.Background(), laptopClient, createRes.GetUpload().GetId(), 0, tc.data)
+		}
+		require.Equal(t, tc.code, status.Code(err), tc.name)
+	}
+
+	listRes, err := laptopClient.ListImages(context.Background(), &pb.ListImagesRequest{LaptopId: laptop.GetId()})
+	require.NoError(t, err)
+	require.Len(t, listRes.GetImages(), 2)
+
+	image := listRes.GetImages()[1]
+	require.Equal(t, ".png", image.GetImageType())
+	require.Len(t, image.GetThumbnails(), 3)
+
+	thumbnail := image.GetThumbnails()[0]
+	require.Equal(t, "small", thumbnail.GetName())
+	require.Equal(t, "image/png", thumbnail.GetContentType())
+	require.LessOrEqual(t, thumbnail.GetWidth(), uint32(128))
+	require.LessOrEqual(t, thumbnail.GetHeight(), uint32(128))
+
+	req := &pb.DownloadImageRequest{ImageId: image.GetId(), Thumbnail: "small"}
+	stream, err := laptopClient.DownloadImage(context.Background(), req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	var downloaded bytes.Buffer
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		downloaded.Write(res.GetChunkData())
+	}
+	require.EqualValues(t, thumbnail.GetSize(), downloaded.Len())
+
+	req.Thumbnail = "huge"
+	stream, err = laptopClient.DownloadImage(context.Background(), req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClientUploadImageQuota(t *testing.T) {
+	t.Parallel()
+	laptopStore := service.NewInMemoryLaptopStore()
+
+	var laptopIDs []string
+	for i := 0; i < 4; i++ {
+		laptop := sample.NewLaptop()
+		require.NoError(t, laptopStore.Save(laptop))
+		laptopIDs = append(laptopIDs, laptop.GetId())
+	}
+
+	imageData, err := ioutil.ReadFile("../tmp/laptop.png")
+	require.NoError(t, err)
+	size := len(imageData)
+
+	imageQuotas := map[string]service.ImageQuota{
+		"user":  {MaxImageSize: size, MaxLaptopSize: 2*size + size/2, MaxUploaderSize: 3*size + size/2},
+		"admin": {},
+	}
+	laptopServer := service.NewLaptopServer(laptopStore, newTestImageStore(t), newTestUploadStore(t), imageQuotas, nil, nil)
+
+	const laptopServicePath = "/techschool.pcbook.LaptopService/"
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	interceptor := service.NewAuthInterceptor(jwtManager, map[string][]string{
+		laptopServicePath + "UploadImage":       {"user", "admin", "guest"},
+		laptopServicePath + "CreateImageUpload": {"user", "admin", "guest"},
+		laptopServicePath + "GetImageUpload":    {"user", "admin", "guest"},
+	})
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptor.Unary()),
+		grpc.StreamInterceptor(interceptor.Stream()),
+	)
+	pb.RegisterLaptopServiceServer(grpcServer, laptopServer)
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	laptopClient := newTestLaptopClient(t, listener.Addr().String())
+
+	alice := newTestUserContext(t, jwtManager, "alice")
+	bob := newTestUserContext(t, jwtManager, "bob")
+	newRoleContext := func(username string, role string) context.Context {
+		user, err := service.NewUser(username, "secret", role)
+		require.NoError(t, err)
+		accessToken, err := jwtManager.Generate(user)
+		require.NoError(t, err)
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", accessToken)
+	}
+	admin := newRoleContext("carol", "admin")
+	guest := newRoleContext("dave", "guest")
+
+	checksum := sha256.Sum256(imageData)
+	createUpload := func(ctx context.Context, laptopID string, size int) (string, error) {
+		info := &pb.ImageInfo{
+			LaptopId:  laptopID,
+			ImageType: ".png",
+			Size:      uint32(size),
+			Sha256:    hex.EncodeToString(checksum[:]),
+		}
+		res, err := laptopClient.CreateImageUpload(ctx, &pb.CreateImageUploadRequest{Info: info})
+		return res.GetUpload().GetId(), err
+	}
+
+	testCases := []struct {
+		name     string
+		user     context.Context
+		laptopID string
+		code     codes.Code
+	}{
+		{"first image", alice, laptopIDs[0], codes.OK},
+		{"second image", alice, laptopIDs[0], codes.OK},
+		{"laptop quota", bob, laptopIDs[0], codes.ResourceExhausted},
+		{"other laptop", alice, laptopIDs[1], codes.OK},
+		{"uploader quota", alice, laptopIDs[2], codes.ResourceExhausted},
+		{"other uploader", bob, laptopIDs[2], codes.OK},
+	}
+
+	for _, tc := range testCases {
+		uploadID, err := createUpload(tc.user, tc.laptopID, size)
+		if err == nil {
+			_, err = uploadTestImage(t, tc.user, laptopClient, uploadID, 0, imageData)
+		}
+		require.Equal(t, tc.code, status.Code(err), tc.name)
+	}
+
+	_, err = createUpload(bob, laptopIDs[3], size+1)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the size is only declared, the data is checked as it is received
+	uploadID, err := createUpload(bob, laptopIDs[3], size)
+	require.NoError(t, err)
+	_, err = uploadTestImage(t, bob, laptopClient, uploadID, 0, append(imageData, 0))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the uploads started before the quota is reached cannot exceed it
+	var uploadIDs []string
+	for i := 0; i < 3; i++ {
+		uploadID, err := createUpload(bob, laptopIDs[3], size)
+		require.NoError(t, err)
+		uploadIDs = append(uploadIDs, uploadID)
+	}
+
+	for _, uploadID := range uploadIDs[:2] {
+		_, err = uploadTestImage(t, bob, laptopClient, uploadID, 0, imageData)
+		require.NoError(t, err)
+	}
+	_, err = uploadTestImage(t, bob, laptopClient, uploadIDs[2], 0, imageData)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = laptopClient.GetImageUpload(bob, &pb.GetImageUploadRequest{UploadId: uploadIDs[2]})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// a role without a quota cannot upload images
+	_, err = createUpload(guest, laptopIDs[3], size)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = uploadTestImage(t, guest, laptopClient, "", 0, imageData)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// a quota without limits still has a largest image size
+	_, err = createUpload(admin, laptopIDs[3], 32<<20+1)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = createUpload(admin, laptopIDs[3], 32<<20)
+	require.NoError(t, err)
+}
+
+// uploadTestImage sends the data of an upload in chunks starting at offset
+func uploadTestImage(
+	t *testing.T,
+	ctx context.Context,
+	laptopClient pb.LaptopServiceClient,
+	uploadID string,
+	offset int,
+	data []byte,
+) (*pb.UploadImageResponse, error) {
+	stream, err := laptopClient.UploadImage(ctx)
+	require.NoError(t, err)
+
+	req := &pb.UploadImageRequest{
+		Data: &pb.UploadImageRequest_Info{Info: &pb.ImageInfo{UploadId: uploadID}},
+	}
+	require.NoError(t, stream.Send(req))
+
+	for len(data) > 0 {
+		n := 1024
+		if n > len(data) {
+			n = len(data)
+		}
+
+		req := &pb.UploadImageRequest{
+			Data: &pb.UploadImageRequest_Chunk{
+				Chunk: &pb.ImageChunk{Offset: uint32(offset), Data: data[:n]},
+			},
+		}
+		err := stream.Send(req)
+		if err != nil {
+			return nil, stream.RecvMsg(nil)
+		}
+
+		offset += n
+		data = data[n:]
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func TestClientDownloadImage(t *testing.T) {
+	t.Parallel()
+	laptopStore := service.NewInMemoryLaptopStore()
+	imageStore := newTestImageStore(t)
+
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	serverAddress := startTestLaptopServer(t, laptopStore, imageStore, nil)
+	laptopClient := newTestLaptopClient(t, serverAddress)
+
+	imageData, err := ioutil.ReadFile("../tmp/laptop.png")
+	require.NoError(t, err)
+
+	var imageIDs []string
+	for i := 0; i < 2; i++ {
+		imageIDs = append(imageIDs, saveTestImage(t, imageStore, laptop.GetId(), ".png", "", imageData))
+	}
+
+	listRes, err := laptopClient.ListImages(context.Background(), &pb.ListImagesRequest{LaptopId: laptop.GetId()})
+	require.NoError(t, err)
+	require.Len(t, listRes.GetImages(), 2)
+	for i, image := range listRes.GetImages() {
+		require.Equal(t, imageIDs[i], image.GetId())
+		require.Equal(t, "image/png", image.GetContentType())
+		require.EqualValues(t, len(imageData), image.GetSize())
+	}
+
+	stream, err := laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{ImageId: imageIDs[0]})
+	require.NoError(t, err)
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, laptop.GetId(), res.GetImage().GetLaptopId())
+
+	var downloaded bytes.Buffer
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		downloaded.Write(res.GetChunkData())
+	}
+	require.Equal(t, imageData, downloaded.Bytes())
+
+	_, err = laptopClient.DeleteImage(context.Background(), &pb.DeleteImageRequest{ImageId: imageIDs[0]})
+	require.NoError(t, err)
+	_, err = laptopClient.DeleteImage(context.Background(), &pb.DeleteImageRequest{ImageId: imageIDs[0]})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err = laptopClient.DownloadImage(context.Background(), &pb.DownloadImageRequest{ImageId: imageIDs[0]})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	listRes, err = laptopClient.ListImages(context.Background(), &pb.ListImagesRequest{LaptopId: laptop.GetId()})
+	require.NoError(t, err)
+	require.Len(t, listRes.GetImages(), 1)
+
+	_, err = laptopClient.ListImages(context.Background(), &pb.ListImagesRequest{LaptopId: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
 }
 
 func TestClientRatelaptop(t *testing.T) {
@@ -209,8 +1382,8 @@ func TestClientRatelaptop(t *testing.T) {
 	stream, err := laptopClient.RateLaptop(context.Background())
 	require.NoError(t, err)
 
-	scores := []float64{8, 7.6, 10}
-	averages := []float64{8, 7.6, 8.5}
+	scores := []float64{8, 7.5, 10}
+	averages := []float64{8, 7.75, 8.5}
 	n := len(scores)
 
 	for i := 0; i < n; i++ {
@@ -234,7 +1407,7 @@ func TestClientRatelaptop(t *testing.T) {
 		}
 
 		require.NoError(t, err)
-		require.Equal(t, laptop.GetBrand(), res.GetLaptopId())
+		require.Equal(t, laptop.GetId(), res.GetLaptopId())
 		require.Equal(t, uint32(idx+1), res.GetRatedCount())
 		require.Equal(t, averages[idx], res.GetAverageScore())
 	}
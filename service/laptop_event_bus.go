@@ -0,0 +1,228 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"gitlab.techschool.pcbook/pb"
+)
+
+// ErrResumeTokenExpired is returned when events after a resume token are no longer kept
+var ErrResumeTokenExpired = errors.New("resume token expired")
+
+// ErrInvalidResumeToken is returned for a resume token that was not issued by the event bus
+var ErrInvalidResumeToken = errors.New("invalid resume token")
+
+// number of events buffered for each subscriber before it is dropped as too slow
+const laptopSubscriberBuffer = 100
+
+// LaptopEventBus publishes laptop events to subscribers and keeps the most recent ones,
+// so a subscriber can resume after the last event it saw
+type LaptopEventBus struct {
+	mutex       sync.Mutex
+	id          string
+	sequence    uint64
+	history     []*pb.LaptopEvent
+	historySize int
+	subscribers map[chan *pb.LaptopEvent]bool
+}
+
+// laptopEventToken identifies an event, the bus id rejects tokens of another server run
+type laptopEventToken struct {
+	Bus      string `json:"bus"`
+	Sequence uint64 `json:"seq"`
+}
+
+// NewLaptopEventBus returns a bus that keeps the last historySize events
+func NewLaptopEventBus(historySize int) *LaptopEventBus {
+	return &LaptopEventBus{
+		id:          uuid.New().String(),
+		historySize: historySize,
+		subscribers: make(map[chan *pb.LaptopEvent]bool),
+	}
+}
+
+// Publish sets the time and resume token of an event and sends it to every subscriber.
+// The event must not be modified afterwards.
+// A subscriber whose buffer is full is dropped by closing its channel.
+func (bus *LaptopEventBus) Publish(event *pb.LaptopEvent) error {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	bus.sequence++
+	token, err := encodeEventToken(&laptopEventToken{bus.id, bus.sequence})
+	if err != nil {
+		return err
+	}
+
+	event.Time = ptypes.TimestampNow()
+	event.ResumeToken = token
+
+	bus.history = append(bus.history, event)
+	if len(bus.history) > bus.historySize {
+		bus.history = bus.history[len(bus.history)-bus.historySize:]
+	}
+
+	for events := range bus.subscribers {
+		select {
+		case events <- event:
+		default:
+			delete(bus.subscribers, events)
+			close(events)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns the kept events after resumeToken and a channel of the following events.
+// The channel is closed if the subscriber does not keep up. Cancel must be called when done.
+func (bus *LaptopEventBus) Subscribe(resumeToken string) ([]*pb.LaptopEvent, <-chan *pb.LaptopEvent, func(), error) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	var backlog []*pb.LaptopEvent
+	if resumeToken != "" {
+		token, err := decodeEventToken(resumeToken)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if token.Bus != bus.id {
+			return nil, nil, nil, ErrResumeTokenExpired
+		}
+		if token.Sequence > bus.sequence {
+			return nil, nil, nil, ErrInvalidResumeToken
+		}
+
+		missed := bus.sequence - token.Sequence
+		if missed > uint64(len(bus.history)) {
+			return nil, nil, nil, ErrResumeTokenExpired
+		}
+
+		backlog = append(backlog, bus.history[len(bus.history)-int(missed):]...)
+	}
+
+	events := make(chan *pb.LaptopEvent, laptopSubscriberBuffer)
+	bus.subscribers[events] = true
+
+	cancel := func() {
+		bus.mutex.Lock()
+		defer bus.mutex.Unlock()
+
+		if bus.subscribers[events] {
+			delete(bus.subscribers, events)
+			close(events)
+		}
+	}
+
+	return backlog, events, cancel, nil
+}
+
+func encodeEventToken(token *laptopEventToken) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode resume token %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeEventToken(resumeToken string) (*laptopEventToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(resumeToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResumeToken, err)
+	}
+
+	token := &laptopEventToken{}
+	err = json.Unmarshal(data, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResumeToken, err)
+	}
+
+	return token, nil
+}
+
+// EventLaptopStore publishes an event to a LaptopEventBus for every write to a laptop store
+type EventLaptopStore struct {
+	LaptopStore
+	bus *LaptopEventBus
+}
+
+func NewEventLaptopStore(store LaptopStore, bus *LaptopEventBus) *EventLaptopStore {
+	return &EventLaptopStore{store, bus}
+}
+
+func (store *EventLaptopStore) Save(laptop *pb.Laptop) error {
+	err := store.LaptopStore.Save(laptop)
+	if err != nil {
+		return err
+	}
+
+	return store.publishCreated(laptop)
+}
+
+func (store *EventLaptopStore) SaveAll(laptops []*pb.Laptop) error {
+	err := store.LaptopStore.SaveAll(laptops)
+	if err != nil {
+		return err
+	}
+
+	for _, laptop := range laptops {
+		err := store.publishCreated(laptop)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *EventLaptopStore) publishCreated(laptop *pb.Laptop) error {
+	// read it back to publish the stored version
+	saved, err := store.LaptopStore.Find(laptop.GetId())
+	if err != nil || saved == nil {
+		return err
+	}
+
+	return store.bus.Publish(&pb.LaptopEvent{Type: pb.LaptopEvent_CREATED, Laptop: saved})
+}
+
+func (store *EventLaptopStore) Update(laptop *pb.Laptop) error {
+	previous, err := store.LaptopStore.Find(laptop.GetId())
+	if err != nil {
+		return err
+	}
+
+	err = store.LaptopStore.Update(laptop)
+	if err != nil {
+		return err
+	}
+
+	updated, err := deepCopy(laptop)
+	if err != nil {
+		return err
+	}
+
+	return store.bus.Publish(&pb.LaptopEvent{Type: pb.LaptopEvent_UPDATED, Laptop: updated, Previous: previous})
+}
+
+func (store *EventLaptopStore) Delete(id string, version uint64) error {
+	deleted, err := store.LaptopStore.Find(id)
+	if err != nil {
+		return err
+	}
+
+	err = store.LaptopStore.Delete(id, version)
+	if err != nil {
+		return err
+	}
+
+	if deleted == nil {
+		deleted = &pb.Laptop{Id: id}
+	}
+	return store.bus.Publish(&pb.LaptopEvent{Type: pb.LaptopEvent_DELETED, Laptop: deleted})
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gitlab.techschool.pcbook/pb"
+)
+
+// LaptopTextSearcher is implemented by laptop stores that support free text search
+type LaptopTextSearcher interface {
+	// SearchText returns the relevance score of every laptop matching all words of text,
+	// scored with the statistics in stats and adding the missing ones to it
+	SearchText(text string, stats *TextStats) map[string]float64
+}
+
+// TextStats holds the laptop count and term frequencies a text search is scored with.
+// Passing the stats of the first page to later pages keeps the relevance of every
+// laptop stable while laptops are saved or deleted in between.
+type TextStats struct {
+	// Laptops is the number of indexed laptops
+	Laptops int `json:"laptops"`
+	// Terms is the number of laptops containing each matched term
+	Terms map[string]int `json:"terms"`
+}
+
+// LaptopIndex is an inverted index over laptop brand, name, CPU and GPU names
+type LaptopIndex struct {
+	mutex sync.RWMutex
+	// term frequencies of every laptop containing a term
+	terms map[string]map[string]float64
+	// terms of every laptop, to remove them again
+	laptops map[string][]string
+}
+
+// weights of the indexed fields, a brand or name match is worth more than a GPU match
+const (
+	laptopNameWeight = 2
+	cpuNameWeight    = 1
+	gpuNameWeight    = 1
+)
+
+// relevance of the different ways a query word can match an indexed term
+const (
+	exactMatchScore  = 1.0
+	prefixMatchScore = 0.8
+	fuzzyMatchScore  = 0.5
+)
+
+func NewLaptopIndex() *LaptopIndex {
+	return &LaptopIndex{
+		terms:   make(map[string]map[string]float64),
+		laptops: make(map[string][]string),
+	}
+}
+
+// Add indexes a laptop, replacing what was indexed before for the same id
+func (index *LaptopIndex) Add(laptop *pb.Laptop) {
+	frequencies := make(map[string]float64)
+	addTerms := func(text string, weight float64) {
+		for _, term := range tokenize(text) {
+			frequencies[term] += weight
+		}
+	}
+
+	addTerms(laptop.GetBrand(), laptopNameWeight)
+	addTerms(laptop.GetName(), laptopNameWeight)
+	addTerms(laptop.GetCpu().GetBrand(), cpuNameWeight)
+	addTerms(laptop.GetCpu().GetName(), cpuNameWeight)
+	for _, gpu := range laptop.GetGpus() {
+		addTerms(gpu.GetBrand(), gpuNameWeight)
+		addTerms(gpu.GetName(), gpuNameWeight)
+	}
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.remove(laptop.GetId())
+	for term, frequency := range frequencies {
+		if index.terms[term] == nil {
+			index.terms[term] = make(map[string]float64)
+		}
+		index.terms[term][laptop.GetId()] = frequency
+		index.laptops[laptop.GetId()] = append(index.laptops[laptop.GetId()], term)
+	}
+}
+
+// Remove drops a laptop from the index
+func (index *LaptopIndex) Remove(id string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.remove(id)
+}
+
+func (index *LaptopIndex) remove(id string) {
+	for _, term := range index.laptops[id] {
+		delete(index.terms[term], id)
+		if len(index.terms[term]) == 0 {
+			delete(index.terms, term)
+		}
+	}
+
+	delete(index.laptops, id)
+}
+
+// SearchText returns the relevance score of every laptop matching all words of text.
+// A word matches a term exactly, as a prefix, or with a small typo.
+// Counts missing from stats are taken from the index and added to stats.
+func (index *LaptopIndex) SearchText(text string, stats *TextStats) map[string]float64 {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	if stats.Laptops == 0 {
+		stats.Laptops = len(index.laptops)
+	}
+	if stats.Terms == nil {
+		stats.Terms = make(map[string]int)
+	}
+
+	var scores map[string]float64
+	for _, word := range tokenize(text) {
+		wordScores := make(map[string]float64)
+		for term, laptops := range index.terms {
+			match := matchTerm(word, term)
+			if match == 0 {
+				continue
+			}
+
+			if _, ok := stats.Terms[term]; !ok {
+				stats.Terms[term] = len(laptops)
+			}
+
+			// rare terms tell more about a laptop than common ones
+			idf := math.Log(1 + float64(stats.Laptops)/float64(stats.Terms[term]))
+			for id, frequency := range laptops {
+				score := match * frequency * idf
+				if score > wordScores[id] {
+					wordScores[id] = score
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+
+		for id := range scores {
+			if wordScores[id] == 0 {
+				delete(scores, id)
+			} else {
+				scores[id] += wordScores[id]
+			}
+		}
+	}
+
+	return scores
+}
+
+// Build indexes every laptop of a store
+func (index *LaptopIndex) Build(store LaptopStore) error {
+	return store.Search(context.Background(), &pb.Filter{}, func(laptop *pb.Laptop) error {
+		index.Add(laptop)
+		return nil
+	})
+}
+
+// tokenize splits text into lower case words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchTerm(word, term string) float64 {
+	switch {
+	case word == term:
+		return exactMatchScore
+	case strings.HasPrefix(term, word):
+		return prefixMatchScore
+	case isFuzzyMatch(word, term):
+		return fuzzyMatchScore
+	default:
+		return 0
+	}
+}
+
+// isFuzzyMatch allows one typo in words of 4 letters and two in words of 8 letters
+func isFuzzyMatch(word, term string) bool {
+	maxDistance := 0
+	switch {
+	case len(word) >= 8:
+		maxDistance = 2
+	case len(word) >= 4:
+		maxDistance = 1
+	}
+
+	if maxDistance == 0 {
+		return false
+	}
+
+	return editDistance(word, term, maxDistance) <= maxDistance
+}
+
+// editDistance returns the Levenshtein distance of a and b, or max+1 if it is larger than max
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > max {
+		return max + 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if current[j] < rowMin {
+				rowMin = current[j]
+			}
+		}
+
+		if rowMin > max {
+			return max + 1
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
+
+// IndexedLaptopStore keeps a LaptopIndex up to date with every write to a laptop store
+type IndexedLaptopStore struct {
+	LaptopStore
+	index *LaptopIndex
+}
+
+// NewIndexedLaptopStore indexes the laptops already in store
+func NewIndexedLaptopStore(store LaptopStore) (*IndexedLaptopStore, error) {
+	index := NewLaptopIndex()
+	err := index.Build(store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IndexedLaptopStore{store, index}, nil
+}
+
+func (store *IndexedLaptopStore) Save(laptop *pb.Laptop) error {
+	err := store.LaptopStore.Save(laptop)
+	if err != nil {
+		return err
+	}
+
+	store.index.Add(laptop)
+	return nil
+}
+
+func (store *IndexedLaptopStore) SaveAll(laptops []*pb.Laptop) error {
+	err := store.LaptopStore.SaveAll(laptops)
+	if err != nil {
+		return err
+	}
+
+	for _, laptop := range laptops {
+		store.index.Add(laptop)
+	}
+	return nil
+}
+
+func (store *IndexedLaptopStore) Update(laptop *pb.Laptop) error {
+	err := store.LaptopStore.Update(laptop)
+	if err != nil {
+		return err
+	}
+
+	store.index.Add(laptop)
+	return nil
+}
+
+func (store *IndexedLaptopStore) Delete(id string, version uint64) error {
+	err := store.LaptopStore.Delete(id, version)
+	if err != nil {
+		return err
+	}
+
+	store.index.Remove(id)
+	return nil
+}
+
+func (store *IndexedLaptopStore) SearchText(text string, stats *TextStats) map[string]float64 {
+	return store.index.SearchText(text, stats)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// laptopCursor holds the sort keys of a laptop.
+// A page token is the cursor of the last laptop of a page, so the next page
+// starts right after it even if laptops were saved in the meantime.
+// For text searches it also carries the statistics the first page was scored
+// with, otherwise relevance would shift with every saved or deleted laptop.
+type laptopCursor struct {
+	Query       uint64     `json:"query"`
+	ID          string     `json:"id"`
+	PriceUsd    float64    `json:"price_usd,omitempty"`
+	ReleaseYear uint32     `json:"release_year,omitempty"`
+	CpuGhz      float64    `json:"cpu_ghz,omitempty"`
+	RamBits     uint64     `json:"ram_bits,omitempty"`
+	Rating      float64    `json:"rating,omitempty"`
+	UpdatedAt   int64      `json:"updated_at,omitempty"`
+	Relevance   float64    `json:"relevance,omitempty"`
+	Text        *TextStats `json:"text,omitempty"`
+}
+
+func newLaptopCursor(query uint64, laptop *pb.Laptop, rating *Rating) *laptopCursor {
+	cursor := &laptopCursor{
+		Query:       query,
+		ID:          laptop.GetId(),
+		PriceUsd:    laptop.GetPriceUsd(),
+		ReleaseYear: laptop.GetReleaseYear(),
+		CpuGhz:      laptop.GetCpu().GetMinGhz(),
+		RamBits:     toBit(laptop.GetRam()),
+	}
+
+	if rating != nil && rating.Count > 0 {
+		cursor.Rating = rating.Sum / float64(rating.Count)
+	}
+	if updatedAt := laptop.GetUpdatedAt(); updatedAt != nil {
+		cursor.UpdatedAt = updatedAt.GetSeconds()*1e9 + int64(updatedAt.GetNanos())
+	}
+
+	return cursor
+}
+
+// compareLaptopCursors returns a negative number if a comes before b in the given order
+func compareLaptopCursors(a, b *laptopCursor, orderBy []*pb.SortOrder) int {
+	for _, order := range orderBy {
+		result := 0
+		switch order.GetKey() {
+		case pb.SortOrder_PRICE_USD:
+			result = compareFloat(a.PriceUsd, b.PriceUsd)
+		case pb.SortOrder_RELEASE_YEAR:
+			result = compareUint(uint64(a.ReleaseYear), uint64(b.ReleaseYear))
+		case pb.SortOrder_CPU_GHZ:
+			result = compareFloat(a.CpuGhz, b.CpuGhz)
+		case pb.SortOrder_RAM:
+			result = compareUint(a.RamBits, b.RamBits)
+		case pb.SortOrder_RATING:
+			result = compareFloat(a.Rating, b.Rating)
+		case pb.SortOrder_UPDATED_AT:
+			result = compareInt(a.UpdatedAt, b.UpdatedAt)
+		case pb.SortOrder_RELEVANCE:
+			result = compareFloat(a.Relevance, b.Relevance)
+		}
+
+		if order.GetDescending() {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+
+	return strings.Compare(a.ID, b.ID)
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func validateSortOrder(orderBy []*pb.SortOrder) error {
+	for _, order := range orderBy {
+		if _, ok := pb.SortOrder_Key_name[int32(order.GetKey())]; !ok || order.GetKey() == pb.SortOrder_UNKNOWN {
+			return fmt.Errorf("unknown sort key %v", order.GetKey())
+		}
+	}
+
+	return nil
+}
+
+// searchQueryHash identifies the filter, query, text and order of a search,
+// so a page token cannot be reused for another search
+func searchQueryHash(req *pb.SearchLaptopRequest) (uint64, error) {
+	query := &pb.SearchLaptopRequest{
+		Filter:  req.GetFilter(),
+		OrderBy: req.GetOrderBy(),
+		Query:   req.GetQuery(),
+		Text:    req.GetText(),
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(query)
+	if err != nil {
+		return 0, fmt.Errorf("cannot marshal search query %w", err)
+	}
+
+	hash := fnv.New64a()
+	hash.Write(data)
+	return hash.Sum64(), nil
+}
+
+func encodePageToken(cursor *laptopCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode page token %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodePageToken(token string) (*laptopCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token %w", err)
+	}
+
+	cursor := &laptopCursor{}
+	err = json.Unmarshal(data, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token %w", err)
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,609 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// A laptop query is a boolean expression over laptop fields, for example
+//
+//	brand in ("Apple", "Dell") and ram >= 16GB and price_usd < 2000 and screen.panel = OLED
+//
+// Fields are proto field names joined with dots. A comparison on a repeated field
+// such as gpus.brand matches if any element matches. Memory fields are compared
+// with sizes like 512MB or 1TB, and weight_kg/weight_lb convert between units.
+type laptopQuery interface {
+	match(laptop *pb.Laptop) bool
+}
+
+// QuerySyntaxError reports the position of the offending token in a laptop query
+type QuerySyntaxError struct {
+	Position int
+	Token    string
+	Message  string
+}
+
+func (err *QuerySyntaxError) Error() string {
+	if err.Token == "" {
+		return fmt.Sprintf("%s at end of query", err.Message)
+	}
+
+	return fmt.Sprintf("%s at position %d near %q", err.Message, err.Position, err.Token)
+}
+
+type andQuery struct {
+	left, right laptopQuery
+}
+
+func (query *andQuery) match(laptop *pb.Laptop) bool {
+	return query.left.match(laptop) && query.right.match(laptop)
+}
+
+type orQuery struct {
+	left, right laptopQuery
+}
+
+func (query *orQuery) match(laptop *pb.Laptop) bool {
+	return query.left.match(laptop) || query.right.match(laptop)
+}
+
+type notQuery struct {
+	query laptopQuery
+}
+
+func (query *notQuery) match(laptop *pb.Laptop) bool {
+	return !query.query.match(laptop)
+}
+
+// parseLaptopQuery parses a query, an empty query matches every laptop
+func parseLaptopQuery(text string) (laptopQuery, error) {
+	tokens, err := lexLaptopQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &queryParser{tokens: tokens}
+	if parser.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	query, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token := parser.peek(); token.kind != tokenEOF {
+		return nil, token.errorf("unexpected token")
+	}
+
+	return query, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type queryToken struct {
+	kind     tokenKind
+	text     string
+	position int
+	// number and unit of a number token such as 16GB
+	number float64
+	unit   string
+}
+
+func (token queryToken) errorf(format string, args ...interface{}) error {
+	text := token.text
+	if token.kind == tokenEOF {
+		text = ""
+	}
+
+	return &QuerySyntaxError{
+		Position: token.position,
+		Token:    text,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (token queryToken) isKeyword(keyword string) bool {
+	return token.kind == tokenIdent && strings.EqualFold(token.text, keyword)
+}
+
+func lexLaptopQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		position := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenLeftParen, text: "(", position: position})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenRightParen, text: ")", position: position})
+			i++
+		case r == ',':
+			tokens = append(tokens, queryToken{kind: tokenComma, text: ",", position: position})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+
+			operator := string(runes[start:i])
+			if operator == "!" {
+				return nil, &QuerySyntaxError{Position: position, Token: operator, Message: "unknown operator"}
+			}
+			if operator == "==" {
+				operator = "="
+			}
+			tokens = append(tokens, queryToken{kind: tokenOperator, text: operator, position: position})
+		case r == '"' || r == '\'':
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &QuerySyntaxError{Position: position, Token: string(runes[start:]), Message: "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, queryToken{kind: tokenString, text: value.String(), position: position})
+		case unicode.IsDigit(r):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			number, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, &QuerySyntaxError{Position: position, Token: string(runes[start:i]), Message: "invalid number"}
+			}
+
+			unitStart := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{
+				kind:     tokenNumber,
+				text:     string(runes[start:i]),
+				position: position,
+				number:   number,
+				unit:     strings.ToUpper(string(runes[unitStart:i])),
+			})
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: tokenIdent, text: string(runes[start:i]), position: position})
+		default:
+			return nil, &QuerySyntaxError{Position: position, Token: string(r), Message: "unexpected character"}
+		}
+	}
+
+	return append(tokens, queryToken{kind: tokenEOF, position: len(runes) + 1}), nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	next   int
+}
+
+func (parser *queryParser) peek() queryToken {
+	return parser.tokens[parser.next]
+}
+
+func (parser *queryParser) advance() queryToken {
+	token := parser.tokens[parser.next]
+	if token.kind != tokenEOF {
+		parser.next++
+	}
+
+	return token
+}
+
+func (parser *queryParser) parseOr() (laptopQuery, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.peek().isKeyword("or") {
+		parser.advance()
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orQuery{left, right}
+	}
+
+	return left, nil
+}
+
+func (parser *queryParser) parseAnd() (laptopQuery, error) {
+	left, err := parser.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.peek().isKeyword("and") {
+		parser.advance()
+		right, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andQuery{left, right}
+	}
+
+	return left, nil
+}
+
+func (parser *queryParser) parseNot() (laptopQuery, error) {
+	if parser.peek().isKeyword("not") {
+		parser.advance()
+		query, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notQuery{query}, nil
+	}
+
+	if parser.peek().kind == tokenLeftParen {
+		parser.advance()
+		query, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		token := parser.advance()
+		if token.kind != tokenRightParen {
+			return nil, token.errorf("expected )")
+		}
+
+		return query, nil
+	}
+
+	return parser.parseComparison()
+}
+
+func (parser *queryParser) parseComparison() (laptopQuery, error) {
+	token := parser.advance()
+	if token.kind != tokenIdent {
+		return nil, token.errorf("expected field name")
+	}
+
+	field, err := resolveQueryField(token)
+	if err != nil {
+		return nil, err
+	}
+
+	operator := parser.advance()
+	if operator.isKeyword("in") {
+		values, err := parser.parseValueList(field)
+		if err != nil {
+			return nil, err
+		}
+
+		return &comparisonQuery{field: field, operator: "in", values: values}, nil
+	}
+
+	if operator.kind != tokenOperator {
+		return nil, operator.errorf("expected comparison operator")
+	}
+	if !field.isOrdered() && operator.text != "=" && operator.text != "!=" {
+		return nil, operator.errorf("field %s only supports = and !=", token.text)
+	}
+
+	value, err := parser.parseValue(field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comparisonQuery{field: field, operator: operator.text, values: []queryValue{value}}, nil
+}
+
+func (parser *queryParser) parseValueList(field *queryField) ([]queryValue, error) {
+	token := parser.advance()
+	if token.kind != tokenLeftParen {
+		return nil, token.errorf("expected ( after in")
+	}
+
+	var values []queryValue
+	for {
+		value, err := parser.parseValue(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		token = parser.advance()
+		if token.kind == tokenRightParen {
+			return values, nil
+		}
+		if token.kind != tokenComma {
+			return nil, token.errorf("expected , or )")
+		}
+	}
+}
+
+func (parser *queryParser) parseValue(field *queryField) (queryValue, error) {
+	token := parser.advance()
+
+	switch field.kind {
+	case fieldString:
+		if token.kind != tokenString && token.kind != tokenIdent {
+			return queryValue{}, token.errorf("expected text value")
+		}
+		return queryValue{text: token.text}, nil
+
+	case fieldBool:
+		if token.isKeyword("true") {
+			return queryValue{boolean: true}, nil
+		}
+		if token.isKeyword("false") {
+			return queryValue{boolean: false}, nil
+		}
+		return queryValue{}, token.errorf("expected true or false")
+
+	case fieldEnum:
+		if token.kind == tokenString || token.kind == tokenIdent {
+			value := field.enum.Values().ByName(protoreflect.Name(strings.ToUpper(token.text)))
+			if value != nil {
+				return queryValue{enum: value.Number()}, nil
+			}
+		}
+		return queryValue{}, token.errorf("unknown %s value", field.enum.Name())
+
+	case fieldMemory:
+		if token.kind != tokenNumber {
+			return queryValue{}, token.errorf("expected memory size such as 16GB")
+		}
+		shift, ok := memoryUnitShifts[token.unit]
+		if !ok {
+			return queryValue{}, token.errorf("unknown memory unit")
+		}
+		return queryValue{number: token.number * float64(uint64(1)<<shift)}, nil
+
+	default:
+		if token.kind != tokenNumber || token.unit != "" {
+			return queryValue{}, token.errorf("expected number")
+		}
+		return queryValue{number: token.number}, nil
+	}
+}
+
+// memoryUnitShifts converts memory units to bits, like toBit
+var memoryUnitShifts = map[string]uint{
+	"BIT": 0,
+	"B":   3,
+	"KB":  13,
+	"MB":  23,
+	"GB":  33,
+	"TB":  43,
+}
+
+type fieldKind int
+
+const (
+	fieldNumber fieldKind = iota
+	fieldString
+	fieldBool
+	fieldEnum
+	fieldMemory
+	fieldWeight
+)
+
+// queryField is a path of fields from a laptop to the compared value
+type queryField struct {
+	path []protoreflect.FieldDescriptor
+	kind fieldKind
+	enum protoreflect.EnumDescriptor
+}
+
+func (field *queryField) isOrdered() bool {
+	return field.kind == fieldNumber || field.kind == fieldMemory || field.kind == fieldWeight
+}
+
+var memoryDescriptor = (&pb.Memory{}).ProtoReflect().Descriptor()
+
+func resolveQueryField(token queryToken) (*queryField, error) {
+	field := &queryField{}
+	message := (&pb.Laptop{}).ProtoReflect().Descriptor()
+
+	names := strings.Split(token.text, ".")
+	for i, name := range names {
+		if message == nil {
+			return nil, token.errorf("field %s has no subfields", strings.Join(names[:i], "."))
+		}
+
+		descriptor := findQueryField(message.Fields(), name)
+		if descriptor == nil {
+			return nil, token.errorf("unknown field")
+		}
+		field.path = append(field.path, descriptor)
+
+		message = descriptor.Message()
+		if message != nil && message.FullName() == memoryDescriptor.FullName() {
+			message = nil
+		}
+	}
+
+	leaf := field.path[len(field.path)-1]
+	switch {
+	case leaf.ContainingOneof() != nil && leaf.ContainingOneof().Name() == "weight":
+		field.kind = fieldWeight
+	case leaf.Message() != nil && leaf.Message().FullName() == memoryDescriptor.FullName():
+		field.kind = fieldMemory
+	case leaf.Message() != nil:
+		return nil, token.errorf("field cannot be compared, choose one of its subfields")
+	case leaf.Kind() == protoreflect.StringKind:
+		field.kind = fieldString
+	case leaf.Kind() == protoreflect.BoolKind:
+		field.kind = fieldBool
+	case leaf.Kind() == protoreflect.EnumKind:
+		field.kind = fieldEnum
+		field.enum = leaf.Enum()
+	case leaf.Kind() == protoreflect.BytesKind:
+		return nil, token.errorf("field cannot be compared")
+	default:
+		field.kind = fieldNumber
+	}
+
+	return field, nil
+}
+
+// findQueryField finds a field by name ignoring case, so keyboard matches the Keyboard field
+func findQueryField(fields protoreflect.FieldDescriptors, name string) protoreflect.FieldDescriptor {
+	for i := 0; i < fields.Len(); i++ {
+		if strings.EqualFold(string(fields.Get(i).Name()), name) {
+			return fields.Get(i)
+		}
+	}
+
+	return nil
+}
+
+type queryValue struct {
+	text    string
+	number  float64
+	boolean bool
+	enum    protoreflect.EnumNumber
+}
+
+type comparisonQuery struct {
+	field    *queryField
+	operator string
+	values   []queryValue
+}
+
+func (query *comparisonQuery) match(laptop *pb.Laptop) bool {
+	if query.field.kind == fieldWeight {
+		weight, ok := weightKg(laptop)
+		if !ok {
+			return false
+		}
+		if query.field.path[0].Name() == "weight_lb" {
+			weight /= kgPerLb
+		}
+
+		return query.matchValue(protoreflect.ValueOfFloat64(weight))
+	}
+
+	return query.matchPath(laptop.ProtoReflect(), query.field.path)
+}
+
+// matchPath reports whether any value at the end of path matches
+func (query *comparisonQuery) matchPath(message protoreflect.Message, path []protoreflect.FieldDescriptor) bool {
+	descriptor := path[0]
+	if descriptor.IsList() {
+		list := message.Get(descriptor).List()
+		for i := 0; i < list.Len(); i++ {
+			if query.matchElement(list.Get(i), path) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return query.matchElement(message.Get(descriptor), path)
+}
+
+func (query *comparisonQuery) matchElement(value protoreflect.Value, path []protoreflect.FieldDescriptor) bool {
+	if len(path) == 1 {
+		return query.matchValue(value)
+	}
+
+	return query.matchPath(value.Message(), path[1:])
+}
+
+func (query *comparisonQuery) matchValue(value protoreflect.Value) bool {
+	if query.operator == "in" {
+		for _, other := range query.values {
+			if query.compare(value, other) == 0 {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	result := query.compare(value, query.values[0])
+	switch query.operator {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	default:
+		return false
+	}
+}
+
+// compare returns 0 if the field value equals the query value, and its sign otherwise
+func (query *comparisonQuery) compare(value protoreflect.Value, other queryValue) int {
+	switch query.field.kind {
+	case fieldString:
+		if strings.EqualFold(value.String(), other.text) {
+			return 0
+		}
+		return 1
+	case fieldBool:
+		if value.Bool() == other.boolean {
+			return 0
+		}
+		return 1
+	case fieldEnum:
+		if value.Enum() == other.enum {
+			return 0
+		}
+		return 1
+	case fieldMemory:
+		memory, _ := value.Message().Interface().(*pb.Memory)
+		return compareFloat(float64(toBit(memory)), other.number)
+	default:
+		return compareFloat(queryNumber(value, query.field.path[len(query.field.path)-1]), other.number)
+	}
+}
+
+func queryNumber(value protoreflect.Value, descriptor protoreflect.FieldDescriptor) float64 {
+	switch descriptor.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return float64(value.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
@@ -3,26 +3,80 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/uuid"
+	"gitlab.techschool.pcbook/imaging"
 	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/protodiff"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
-const maxImageSize = 1 << 20
+// imageChunkSize is the size of the chunks of a downloaded image
+const imageChunkSize = 64 << 10
+
+// imageURLDuration is the time a download URL of an image is valid
+const imageURLDuration = 15 * time.Minute
+
+// imageLimits are the largest dimensions of an uploaded image
+var imageLimits = imaging.Limits{MaxWidth: 4096, MaxHeight: 4096}
+
+// imageThumbnails are the names and the sizes in pixels of the thumbnails made for every image
+var imageThumbnails = []struct {
+	name string
+	size int
+}{
+	{"small", 128},
+	{"medium", 256},
+	{"large", 512},
+}
 
 type LaptopServer struct {
-	laptopStore LaptopStore
-	imageStore  ImageStore
-	ratingScore RatingStore
+	laptopStore  LaptopStore
+	imageStore   ImageStore
+	uploadStore  ImageUploadStore
+	imageQuotas  map[string]ImageQuota
+	ratingScore  RatingStore
+	laptopEvents *LaptopEventBus
+	// quotaLocks serializes the checks of the image quotas with the commits of the images
+	// of the same laptop or of the same uploader
+	quotaLocks keyMutex
 }
 
-func NewLaptopServer(laptopStore LaptopStore, imageStore ImageStore, ratingStore RatingStore) *LaptopServer {
-	return &LaptopServer{laptopStore, imageStore, ratingStore}
+// NewLaptopServer returns a laptop server. WatchLaptops needs laptopEvents,
+// which the laptop store must publish to, see EventLaptopStore. imageQuotas are
+// the quotas of the roles, the quota of the empty role applies to the roles without
+// a quota and to the uploads of users not authenticated. Without a quota, no image
+// can be uploaded.
+func NewLaptopServer(
+	laptopStore LaptopStore,
+	imageStore ImageStore,
+	uploadStore ImageUploadStore,
+	imageQuotas map[string]ImageQuota,
+	ratingStore RatingStore,
+	laptopEvents *LaptopEventBus,
+) *LaptopServer {
+	return &LaptopServer{
+		laptopStore:  laptopStore,
+		imageStore:   imageStore,
+		uploadStore:  uploadStore,
+		imageQuotas:  imageQuotas,
+		ratingScore:  ratingStore,
+		laptopEvents: laptopEvents,
+	}
 }
 
 func (server *LaptopServer) CreateLaptop(
@@ -66,16 +120,350 @@ func (server *LaptopServer) CreateLaptop(
 	return res, nil
 }
 
+// BatchCreateLaptops saves the laptops of a stream and reports the status of each one.
+// In transactional mode either all laptops are created or none.
+func (server *LaptopServer) BatchCreateLaptops(stream pb.LaptopService_BatchCreateLaptopsServer) error {
+	var laptops []*pb.Laptop
+	var results []*pb.BatchCreateLaptopResult
+	transactional := false
+	ids := make(map[string]bool)
+
+	for index := 0; ; index++ {
+		err := contextError(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot receive laptop: %v", err))
+		}
+		if index == 0 {
+			transactional = req.GetTransactional()
+		}
+
+		laptop := req.GetLaptop()
+		result := &pb.BatchCreateLaptopResult{Index: uint32(index)}
+		err = prepareBatchLaptop(laptop)
+		switch {
+		case err != nil:
+			result.Status = pb.BatchCreateLaptopResult_INVALID
+			result.Error = err.Error()
+		case ids[laptop.GetId()]:
+			result.Status = pb.BatchCreateLaptopResult_DUPLICATE
+			result.Error = "laptop id is repeated in the batch"
+		}
+
+		result.Id = laptop.GetId()
+		ids[laptop.GetId()] = true
+		laptops = append(laptops, laptop)
+		results = append(results, result)
+	}
+
+	var err error
+	if transactional {
+		err = server.batchCreateAll(laptops, results)
+	} else {
+		err = server.batchCreateEach(laptops, results)
+	}
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot save laptops to the store: %v", err))
+	}
+
+	res := &pb.BatchCreateLaptopsResponse{Results: results}
+	for _, result := range results {
+		if result.Status == pb.BatchCreateLaptopResult_CREATED {
+			res.CreatedCount++
+		}
+	}
+
+	log.Printf("created %d of %d laptops, transactional: %t", res.CreatedCount, len(results), transactional)
+	err = stream.SendAndClose(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send response %v", err))
+	}
+
+	return nil
+}
+
+// batchCreateEach saves every laptop without a status on its own
+func (server *LaptopServer) batchCreateEach(laptops []*pb.Laptop, results []*pb.BatchCreateLaptopResult) error {
+	for i, laptop := range laptops {
+		if results[i].Status != pb.BatchCreateLaptopResult_UNKNOWN {
+			continue
+		}
+
+		err := server.laptopStore.Save(laptop)
+		switch {
+		case errors.Is(err, ErrAlreadyExists):
+			results[i].Status = pb.BatchCreateLaptopResult_DUPLICATE
+			results[i].Error = err.Error()
+		case err != nil:
+			return err
+		default:
+			results[i].Status = pb.BatchCreateLaptopResult_CREATED
+		}
+	}
+
+	return nil
+}
+
+// batchCreateAll saves the laptops with SaveAll if all of them are valid and new
+func (server *LaptopServer) batchCreateAll(laptops []*pb.Laptop, results []*pb.BatchCreateLaptopResult) error {
+	failed := false
+	for i, laptop := range laptops {
+		if results[i].Status != pb.BatchCreateLaptopResult_UNKNOWN {
+			failed = true
+			continue
+		}
+
+		found, err := server.laptopStore.Find(laptop.GetId())
+		if err != nil {
+			return err
+		}
+		if found != nil {
+			results[i].Status = pb.BatchCreateLaptopResult_DUPLICATE
+			results[i].Error = ErrAlreadyExists.Error()
+			failed = true
+		}
+	}
+
+	if !failed {
+		err := server.laptopStore.SaveAll(laptops)
+		var batchErr *BatchError
+		switch {
+		case errors.As(err, &batchErr) && errors.Is(batchErr, ErrAlreadyExists):
+			// saved by someone else in the meantime
+			results[batchErr.Index].Status = pb.BatchCreateLaptopResult_DUPLICATE
+			results[batchErr.Index].Error = batchErr.Err.Error()
+			failed = true
+		case err != nil:
+			return err
+		}
+	}
+
+	for _, result := range results {
+		if result.Status != pb.BatchCreateLaptopResult_UNKNOWN {
+			continue
+		}
+
+		if failed {
+			result.Status = pb.BatchCreateLaptopResult_ROLLED_BACK
+		} else {
+			result.Status = pb.BatchCreateLaptopResult_CREATED
+		}
+	}
+
+	return nil
+}
+
+// prepareBatchLaptop checks a laptop of a batch and generates its id if it has none
+func prepareBatchLaptop(laptop *pb.Laptop) error {
+	if laptop == nil {
+		return errors.New("laptop is required")
+	}
+	if strings.TrimSpace(laptop.GetBrand()) == "" || strings.TrimSpace(laptop.GetName()) == "" {
+		return errors.New("laptop brand and name are required")
+	}
+	if laptop.GetPriceUsd() < 0 {
+		return errors.New("laptop price cannot be negative")
+	}
+
+	if len(laptop.Id) > 0 {
+		_, err := uuid.Parse(laptop.Id)
+		if err != nil {
+			return fmt.Errorf("laptop ID is not a valid UUID: %v", err)
+		}
+		return nil
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("cannot generate a new laptop ID %v", err)
+	}
+
+	laptop.Id = id.String()
+	return nil
+}
+
+func (server *LaptopServer) GetLaptop(
+	ctx context.Context,
+	req *pb.GetLaptopRequest,
+) (*pb.GetLaptopResponse, error) {
+	laptopID := req.GetId()
+	log.Printf("receive a get-laptop request with id: %s", laptopID)
+
+	laptop, err := server.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop %v", err)
+	}
+	if laptop == nil {
+		return nil, status.Errorf(codes.NotFound, "laptop %s doesnt exist", laptopID)
+	}
+
+	res := &pb.GetLaptopResponse{
+		Laptop: laptop,
+	}
+
+	return res, nil
+}
+
+func (server *LaptopServer) UpdateLaptop(
+	ctx context.Context,
+	req *pb.UpdateLaptopRequest,
+) (*pb.UpdateLaptopResponse, error) {
+	laptop := req.GetLaptop()
+	paths := req.GetUpdateMask().GetPaths()
+	log.Printf("receive an update-laptop request with id: %s paths: %v", laptop.GetId(), paths)
+
+	for _, path := range paths {
+		if path == "id" {
+			return nil, status.Errorf(codes.InvalidArgument, "laptop ID cannot be updated")
+		}
+	}
+
+	version, err := expectedVersion(ctx, laptop.GetVersion())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid laptop version: %v", err)
+	}
+
+	found, err := server.laptopStore.Find(laptop.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop %v", err)
+	}
+	if found == nil {
+		return nil, status.Errorf(codes.NotFound, "laptop %s doesnt exist", laptop.GetId())
+	}
+	if version != 0 && version != found.Version {
+		return nil, status.Errorf(codes.Aborted, "laptop %s has version %d, not %d", found.Id, found.Version, version)
+	}
+
+	// an empty mask replaces the whole laptop
+	updated := proto.Clone(laptop).(*pb.Laptop)
+	if len(paths) > 0 {
+		updated = proto.Clone(found).(*pb.Laptop)
+		err = applyFieldMask(updated, laptop, paths)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid update mask: %v", err)
+		}
+	}
+	updated.UpdatedAt = ptypes.TimestampNow()
+	// the store rejects the update if the laptop changed since it was read
+	updated.Version = found.Version
+
+	err = server.laptopStore.Update(updated)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, ErrVersionMismatch) {
+			code = codes.Aborted
+		}
+
+		return nil, status.Errorf(code, "cannot update laptop in store %v", err)
+	}
+
+	log.Printf("Updated laptop with id: %s", updated.Id)
+	logLaptopChanges(found, updated)
+	res := &pb.UpdateLaptopResponse{
+		Laptop: updated,
+	}
+
+	return res, nil
+}
+
+// logLaptopChanges writes the changes of an updated laptop to the audit log
+func logLaptopChanges(old, new *pb.Laptop) {
+	diff, err := protodiff.Compare(old, new)
+	if err != nil {
+		log.Printf("cannot compare laptop %s: %v", new.GetId(), err)
+		return
+	}
+
+	log.Printf("laptop %s changes:\n%s", new.GetId(), diff)
+}
+
+func (server *LaptopServer) DeleteLaptop(
+	ctx context.Context,
+	req *pb.DeleteLaptopRequest,
+) (*pb.DeleteLaptopResponse, error) {
+	laptopID := req.GetId()
+	log.Printf("receive a delete-laptop request with id: %s version: %d", laptopID, req.GetVersion())
+
+	version, err := expectedVersion(ctx, req.GetVersion())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid laptop version: %v", err)
+	}
+
+	laptop, err := server.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop %v", err)
+	}
+	if laptop == nil {
+		return nil, status.Errorf(codes.NotFound, "cannot delete laptop from store %v", ErrNotFound)
+	}
+	if version != 0 && laptop.GetVersion() != version {
+		return nil, status.Errorf(codes.Aborted, "cannot delete laptop from store %v", ErrVersionMismatch)
+	}
+
+	// the images and the ratings go first, so that a failed delete can be retried
+	// rather than leaving them behind a laptop that no longer exists
+	if server.imageStore != nil {
+		err = server.imageStore.DeleteByLaptop(laptopID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot delete laptop images %v", err)
+		}
+	}
+
+	if server.ratingScore != nil {
+		err = server.ratingScore.Delete(laptopID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot delete laptop ratings %v", err)
+		}
+	}
+
+	err = server.laptopStore.Delete(laptopID, version)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, ErrVersionMismatch) {
+			code = codes.Aborted
+		}
+
+		return nil, status.Errorf(code, "cannot delete laptop from store %v", err)
+	}
+
+	log.Printf("Deleted laptop with id: %s", laptopID)
+	return &pb.DeleteLaptopResponse{}, nil
+}
+
 func (server *LaptopServer) SearchLaptop(
 	req *pb.SearchLaptopRequest,
 	stream pb.LaptopService_SearchLaptopServer,
 ) error {
 	filter := req.GetFilter()
-	log.Printf("Receive a search laptop request with filter: %v", filter)
-	err := server.laptopStore.Search(
+	log.Printf("Receive a search laptop request with filter: %v query: %q text: %q", filter, req.GetQuery(), req.GetText())
+
+	query, err := parseLaptopQuery(req.GetQuery())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid query: %v", err)
+	}
+
+	hasText := strings.TrimSpace(req.GetText()) != ""
+	if hasText || len(req.GetOrderBy()) > 0 || req.GetPageSize() > 0 || req.GetPageToken() != "" {
+		return server.searchLaptopPage(req, query, stream)
+	}
+
+	err = server.searchLaptops(
 		stream.Context(),
-		filter,
-		func(laptop *pb.Laptop) error {
+		req,
+		query,
+		nil,
+		func(laptop *pb.Laptop, relevance float64) error {
 			res := &pb.SearchLaptopResponse{Laptop: laptop}
 			err := stream.Send(res)
 			if err != nil {
@@ -87,89 +475,887 @@ func (server *LaptopServer) SearchLaptop(
 		})
 
 	if err != nil {
-		return status.Errorf(codes.Internal, "unexpected error: %v", err)
+		return logError(searchError(err))
 	}
 
 	return nil
 }
 
-func (server *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServer) error {
-	req, err := stream.Recv()
+// searchLaptopPage sorts all matching laptops and sends the page after the request's page token
+func (server *LaptopServer) searchLaptopPage(
+	req *pb.SearchLaptopRequest,
+	query laptopQuery,
+	stream pb.LaptopService_SearchLaptopServer,
+) error {
+	orderBy := req.GetOrderBy()
+	err := validateSortOrder(orderBy)
 	if err != nil {
-		return logError(status.Errorf(codes.Unknown, "cannot receive image infp"))
+		return status.Errorf(codes.InvalidArgument, "invalid order: %v", err)
+	}
+	hasText := strings.TrimSpace(req.GetText()) != ""
+	if len(orderBy) == 0 && hasText {
+		orderBy = []*pb.SortOrder{{Key: pb.SortOrder_RELEVANCE, Descending: true}}
 	}
 
-	laptopID := req.GetInfo().GetLaptopId()
-	imageType := req.GetInfo().GetImageType()
-	log.Printf("receive an upload image request for laptop %s with image type %s", laptopID, imageType)
-
-	laptop, err := server.laptopStore.Find(laptopID)
+	hash, err := searchQueryHash(req)
 	if err != nil {
-		return logError(status.Errorf(codes.Internal, "cannot find laptop %v", err))
-	}
-	if laptop == nil {
-		return logError(status.Errorf(codes.InvalidArgument, "laptop %s doesnt exist", laptopID))
+		return status.Errorf(codes.Internal, "unexpected error: %v", err)
 	}
 
-	imageData := bytes.Buffer{}
-	imageSize := 0
-
-	for {
-		log.Print("waitnig for receive more data")
-		req, err := stream.Recv()
-		if err == io.EOF {
-			log.Print("no more data")
-			break
-		}
+	var after *laptopCursor
+	if req.GetPageToken() != "" {
+		after, err = decodePageToken(req.GetPageToken())
 		if err != nil {
-			return logError(status.Errorf(codes.Unknown, "cannot receive ckunk data %v", err))
+			return status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+		}
+		if after.Query != hash {
+			return status.Errorf(codes.InvalidArgument, "page token does not match the search filter, query and order")
 		}
+	}
+
+	stats := &TextStats{}
+	if after != nil && after.Text != nil {
+		stats = after.Text
+	}
+
+	var laptops []*pb.Laptop
+	var cursors []*laptopCursor
+	err = server.searchLaptops(
+		stream.Context(),
+		req,
+		query,
+		stats,
+		func(laptop *pb.Laptop, relevance float64) error {
+			rating, err := server.findRating(laptop.GetId(), orderBy)
+			if err != nil {
+				return err
+			}
+
+			cursor := newLaptopCursor(hash, laptop, rating)
+			cursor.Relevance = relevance
+			if after != nil && compareLaptopCursors(cursor, after, orderBy) <= 0 {
+				return nil
+			}
 
-		chunk := req.GetChunkData()
-		size := len(chunk)
+			laptops = append(laptops, laptop)
+			cursors = append(cursors, cursor)
+			return nil
+		})
+	if err != nil {
+		return logError(searchError(err))
+	}
 
-		log.Printf("received a chunk with size %d", size)
+	sort.Sort(laptopsByCursor{laptops, cursors, orderBy})
 
-		imageSize += size
-		if imageSize > maxImageSize {
-			return logError(status.Errorf(codes.Internal, "cannot write chunk data %v", err))
+	count := len(laptops)
+	if req.GetPageSize() > 0 && count > int(req.GetPageSize()) {
+		count = int(req.GetPageSize())
+	}
+
+	for i := 0; i < count; i++ {
+		res := &pb.SearchLaptopResponse{
+			Laptop:    laptops[i],
+			Relevance: cursors[i].Relevance,
+		}
+		if i == count-1 && count < len(laptops) {
+			if hasText {
+				cursors[i].Text = stats
+			}
+			res.NextPageToken, err = encodePageToken(cursors[i])
+			if err != nil {
+				return status.Errorf(codes.Internal, "unexpected error: %v", err)
+			}
 		}
 
-		_, err = imageData.Write(chunk)
+		err = stream.Send(res)
 		if err != nil {
-			return logError(status.Errorf(codes.Internal, "cannot write chunk data %v:", err))
+			return status.Errorf(codes.Internal, "unexpected error: %v", err)
 		}
+
+		log.Printf("sent laptop with id:%s", laptops[i].GetId())
 	}
 
-	imageId, err := server.imageStore.Save(laptopID, imageType, imageData)
-	if err != nil {
-		return logError(status.Errorf(codes.Internal, "cannotsave iamge to the store %v", err))
+	return nil
+}
+
+// errTextSearchNotSupported is returned for text searches on a store without a text index
+var errTextSearchNotSupported = errors.New("text search is not supported by the laptop store")
+
+// searchLaptops calls found for every laptop matching the filter, query and text of the request,
+// together with its relevance to the text scored with stats
+func (server *LaptopServer) searchLaptops(
+	ctx context.Context,
+	req *pb.SearchLaptopRequest,
+	query laptopQuery,
+	stats *TextStats,
+	found func(laptop *pb.Laptop, relevance float64) error,
+) error {
+	if strings.TrimSpace(req.GetText()) == "" {
+		return server.laptopStore.Search(ctx, req.GetFilter(), func(laptop *pb.Laptop) error {
+			if query != nil && !query.match(laptop) {
+				return nil
+			}
+
+			return found(laptop, 0)
+		})
 	}
 
-	res := &pb.UploadImageResponse{
-		Id:   imageId,
-		Size: uint32(imageSize),
+	searcher, ok := server.laptopStore.(LaptopTextSearcher)
+	if !ok {
+		return errTextSearchNotSupported
 	}
 
-	err = stream.SendAndClose(res)
-	if err != nil {
-		return logError(status.Errorf(codes.Unknown, "cannot send response %v", err))
+	if stats == nil {
+		stats = &TextStats{}
 	}
 
-	log.Printf("Saved images with id: %s , size %d", imageId, imageSize)
+	for id, relevance := range searcher.SearchText(req.GetText(), stats) {
+		err := contextError(ctx)
+		if err != nil {
+			return err
+		}
 
-	return nil
-}
+		laptop, err := server.laptopStore.Find(id)
+		if err != nil {
+			return err
+		}
+		if laptop == nil || !isQualified(req.GetFilter(), laptop) {
+			continue
+		}
+		if query != nil && !query.match(laptop) {
+			continue
+		}
 
-func (server *LaptopServer) RateLaptop(stream pb.LaptopService_RateLaptopServer) error {
-	for {
-		err := contextError(stream.Context())
+		err = found(laptop, relevance)
 		if err != nil {
 			return err
 		}
+	}
 
-		req, err := stream.Recv()
-		if err != io.EOF {
+	return nil
+}
+
+func searchError(err error) error {
+	if errors.Is(err, errTextSearchNotSupported) {
+		return status.Errorf(codes.Unimplemented, "%v", err)
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	return status.Errorf(codes.Internal, "unexpected error: %v", err)
+}
+
+// AggregateLaptops counts the facets and histogram buckets of the laptops matching a filter
+func (server *LaptopServer) AggregateLaptops(
+	ctx context.Context,
+	req *pb.AggregateLaptopsRequest,
+) (*pb.AggregateLaptopsResponse, error) {
+	filter := req.GetFilter()
+	log.Printf("receive an aggregate laptops request with filter: %v", filter)
+
+	aggregator, err := newLaptopAggregator(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	err = server.laptopStore.Search(ctx, filter, func(laptop *pb.Laptop) error {
+		aggregator.add(laptop)
+		return nil
+	})
+	if err != nil {
+		return nil, logError(searchError(err))
+	}
+
+	return aggregator.response(), nil
+}
+
+// WatchLaptops streams the events of laptops matching a filter until the client goes away
+func (server *LaptopServer) WatchLaptops(
+	req *pb.WatchLaptopsRequest,
+	stream pb.LaptopService_WatchLaptopsServer,
+) error {
+	filter := req.GetFilter()
+	log.Printf("receive a watch laptops request with filter: %v", filter)
+
+	return watchLaptopEvents(stream, server.laptopEvents, req.GetResumeToken(), func(event *pb.LaptopEvent) error {
+		// an update of a laptop leaving the filter is sent too, the client would keep it otherwise
+		if !isQualified(filter, event.GetLaptop()) &&
+			(event.GetPrevious() == nil || !isQualified(filter, event.GetPrevious())) {
+			return nil
+		}
+
+		err := stream.Send(&pb.WatchLaptopsResponse{Event: event})
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot send event: %v", err))
+		}
+
+		log.Printf("sent %v event of laptop with id:%s", event.GetType(), event.GetLaptop().GetId())
+		return nil
+	})
+}
+
+// watchLaptopEvents calls send for the events after resumeToken and then for every new event,
+// until the client goes away
+func watchLaptopEvents(
+	stream grpc.ServerStream,
+	laptopEvents *LaptopEventBus,
+	resumeToken string,
+	send func(event *pb.LaptopEvent) error,
+) error {
+	if laptopEvents == nil {
+		return status.Errorf(codes.Unimplemented, "laptop events are not enabled")
+	}
+
+	backlog, events, cancel, err := laptopEvents.Subscribe(resumeToken)
+	switch {
+	case errors.Is(err, ErrResumeTokenExpired):
+		return status.Errorf(codes.OutOfRange, "%v, search the laptops again and watch without it", err)
+	case errors.Is(err, ErrInvalidResumeToken):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return status.Errorf(codes.Internal, "cannot watch laptops: %v", err)
+	}
+	defer cancel()
+
+	// the header tells the client that no event is missed from now on
+	err = stream.SendHeader(metadata.MD{})
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send header: %v", err))
+	}
+
+	for _, event := range backlog {
+		err := send(event)
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return contextError(stream.Context())
+		case event, ok := <-events:
+			if !ok {
+				return logError(status.Errorf(codes.Aborted, "too many events, resume from the last event"))
+			}
+
+			err := send(event)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// findRating returns the laptop rating only if the order needs it
+func (server *LaptopServer) findRating(laptopID string, orderBy []*pb.SortOrder) (*Rating, error) {
+	if server.ratingScore == nil {
+		return nil, nil
+	}
+
+	for _, order := range orderBy {
+		if order.GetKey() == pb.SortOrder_RATING {
+			return server.ratingScore.Find(laptopID)
+		}
+	}
+
+	return nil, nil
+}
+
+// laptopsByCursor sorts laptops together with their cursors
+type laptopsByCursor struct {
+	laptops []*pb.Laptop
+	cursors []*laptopCursor
+	orderBy []*pb.SortOrder
+}
+
+func (s laptopsByCursor) Len() int {
+	return len(s.laptops)
+}
+
+func (s laptopsByCursor) Less(i, j int) bool {
+	return compareLaptopCursors(s.cursors[i], s.cursors[j], s.orderBy) < 0
+}
+
+func (s laptopsByCursor) Swap(i, j int) {
+	s.laptops[i], s.laptops[j] = s.laptops[j], s.laptops[i]
+	s.cursors[i], s.cursors[j] = s.cursors[j], s.cursors[i]
+}
+
+// UploadImage receives the image info, then the chunks of the image data,
+// which are committed to an upload as they arrive. An upload from CreateImageUpload
+// is kept when the stream breaks so that the client can resume it, otherwise the stream
+// starts an upload that is removed when it ends. The image is streamed to the image store
+// once all of its data is received and matches its SHA-256, if it fits in the quota
+// of the role of the user.
+func (server *LaptopServer) UploadImage(stream pb.LaptopService_UploadImageServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot receive image infp"))
+	}
+
+	info := req.GetInfo()
+	uploadID := info.GetUploadId()
+	keepUpload := uploadID != ""
+	quota, _, err := server.imageQuota(stream.Context())
+	if err != nil {
+		return logError(err)
+	}
+
+	var upload *ImageUpload
+	if uploadID == "" {
+		log.Printf("receive an upload image request for laptop %s with image type %s", info.GetLaptopId(), info.GetImageType())
+
+		upload, err = server.newImageUpload(stream.Context(), info)
+		if err != nil {
+			return logError(err)
+		}
+	} else {
+		upload, err = server.uploadStore.Find(uploadID)
+		if err != nil {
+			return logError(status.Errorf(codes.Internal, "cannot find upload %v", err))
+		}
+		if upload == nil {
+			return logError(status.Errorf(codes.NotFound, "upload %s doesnt exist", uploadID))
+		}
+
+		log.Printf("receive an upload image request for upload %s at offset %d", uploadID, upload.Offset)
+	}
+
+	defer func() {
+		if !keepUpload {
+			server.deleteImageUpload(upload.ID)
+		}
+	}()
+
+	for {
+		err := contextError(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		log.Print("waitnig for receive more data")
+		req, err := stream.Recv()
+		if err == io.EOF {
+			log.Print("no more data")
+			break
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot receive ckunk data %v", err))
+		}
+
+		offset, chunk := upload.Offset, req.GetChunkData()
+		if req.GetChunk() != nil {
+			offset, chunk = int(req.GetChunk().GetOffset()), req.GetChunk().GetData()
+		}
+
+		log.Printf("received a chunk with size %d at offset %d", len(chunk), offset)
+
+		if offset > upload.Offset {
+			return logError(status.Errorf(codes.FailedPrecondition,
+				"chunk at offset %d is after the committed offset %d", offset, upload.Offset))
+		}
+
+		// skip the bytes committed before the chunk was resent
+		if upload.Offset-offset >= len(chunk) {
+			continue
+		}
+		chunk = chunk[upload.Offset-offset:]
+
+		imageSize := upload.Offset + len(chunk)
+		if imageSize > quota.MaxImageSize {
+			return logError(status.Errorf(codes.ResourceExhausted, "image is too large: %d > %d", imageSize, quota.MaxImageSize))
+		}
+		if upload.Size > 0 && imageSize > upload.Size {
+			return logError(status.Errorf(codes.InvalidArgument, "image is larger than its size %d", upload.Size))
+		}
+
+		upload.Offset, err = server.uploadStore.Append(upload.ID, upload.Offset, chunk)
+		if errors.Is(err, ErrInvalidOffset) {
+			return logError(status.Errorf(codes.Aborted, "cannot write chunk data %v", err))
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Internal, "cannot write chunk data %v:", err))
+		}
+	}
+
+	if upload.Size > 0 && upload.Offset < upload.Size {
+		return logError(status.Errorf(codes.FailedPrecondition,
+			"upload %s is incomplete: %d of %d bytes", upload.ID, upload.Offset, upload.Size))
+	}
+
+	image, err := server.saveImageUpload(upload, quota)
+	if err != nil {
+		// the client has to start over, unless the image store failed
+		if status.Code(err) != codes.Internal {
+			keepUpload = false
+		}
+		return logError(err)
+	}
+	keepUpload = false
+
+	res := &pb.UploadImageResponse{
+		Id:   image.ID,
+		Size: uint32(image.Size),
+	}
+
+	err = stream.SendAndClose(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send response %v", err))
+	}
+
+	log.Printf("Saved images with id: %s , size %d", image.ID, image.Size)
+
+	return nil
+}
+
+// CreateImageUpload starts an upload that UploadImage can resume,
+// which needs the size and the SHA-256 of the image
+func (server *LaptopServer) CreateImageUpload(
+	ctx context.Context,
+	req *pb.CreateImageUploadRequest,
+) (*pb.CreateImageUploadResponse, error) {
+	info := req.GetInfo()
+	log.Printf("receive a create image upload request for laptop %s with image type %s", info.GetLaptopId(), info.GetImageType())
+
+	if info.GetSize() == 0 || info.GetSha256() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "image size and sha256 are required")
+	}
+
+	upload, err := server.newImageUpload(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := imageUploadToProto(upload)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot convert upload %v", err)
+	}
+
+	log.Printf("Created image upload with id: %s", upload.ID)
+	return &pb.CreateImageUploadResponse{Upload: other}, nil
+}
+
+// GetImageUpload returns an upload with the offset to resume it from
+func (server *LaptopServer) GetImageUpload(
+	ctx context.Context,
+	req *pb.GetImageUploadRequest,
+) (*pb.GetImageUploadResponse, error) {
+	uploadID := req.GetUploadId()
+	log.Printf("receive a get image upload request for upload %s", uploadID)
+
+	upload, err := server.uploadStore.Find(uploadID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find upload %v", err)
+	}
+	if upload == nil {
+		return nil, status.Errorf(codes.NotFound, "upload %s doesnt exist", uploadID)
+	}
+
+	other, err := imageUploadToProto(upload)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot convert upload %v", err)
+	}
+
+	return &pb.GetImageUploadResponse{Upload: other}, nil
+}
+
+// newImageUpload checks the image info and the quota of the user, and saves a new upload for it
+func (server *LaptopServer) newImageUpload(ctx context.Context, info *pb.ImageInfo) (*ImageUpload, error) {
+	laptopID := info.GetLaptopId()
+	laptop, err := server.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop %v", err)
+	}
+	if laptop == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "laptop %s doesnt exist", laptopID)
+	}
+
+	quota, uploader, err := server.imageQuota(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.checkImageQuota(quota, laptopID, uploader, int(info.GetSize()))
+	if err != nil {
+		return nil, err
+	}
+
+	if info.GetImageType() != "" {
+		_, err := imaging.FormatFromExtension(info.GetImageType())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported image type %v", err)
+		}
+	}
+
+	checksum := strings.ToLower(info.GetSha256())
+	if checksum != "" {
+		sum, err := hex.DecodeString(checksum)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid sha256 %q", info.GetSha256())
+		}
+	}
+
+	upload := &ImageUpload{
+		LaptopID:  laptopID,
+		ImageType: info.GetImageType(),
+		Size:      int(info.GetSize()),
+		SHA256:    checksum,
+		Uploader:  uploader,
+		CreatedAt: time.Now(),
+	}
+
+	err = server.uploadStore.Create(upload)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create upload %v", err)
+	}
+
+	return upload, nil
+}
+
+// saveImageUpload checks the data of an upload and copies it without its metadata to a new image,
+// which is committed if it fits in the quota, then saves its thumbnails. The data is streamed from
+// the upload to the image store, only the pixels of the image are decoded in memory for the thumbnails.
+func (server *LaptopServer) saveImageUpload(upload *ImageUpload, quota ImageQuota) (*ImageInfo, error) {
+	data, err := server.uploadStore.Open(upload.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot open upload %v", err)
+	}
+	defer data.Close()
+
+	err = checkImageUpload(upload, data)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := validateImage(upload.ImageType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := server.imageStore.Create(upload.LaptopID, image.Format.Extension(), upload.Uploader)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create image %v", err)
+	}
+
+	size, err := imaging.WriteWithoutMetadata(writer, image.Format, data)
+	if err != nil {
+		writer.Abort()
+		if errors.Is(err, imaging.ErrCorrupted) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid image %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "cannot write image %v", err)
+	}
+
+	info, err := server.commitImage(writer, quota, upload.LaptopID, upload.Uploader, int(size))
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.saveThumbnails(info.ID, image.Format, data)
+	if err != nil {
+		if deleteErr := server.imageStore.Delete(info.ID); deleteErr != nil {
+			log.Printf("cannot delete image %s without thumbnails: %v", info.ID, deleteErr)
+		}
+		return nil, status.Errorf(codes.Internal, "cannot save thumbnails %v", err)
+	}
+
+	return info, nil
+}
+
+// checkImageUpload checks the data of an upload against its SHA-256
+func checkImageUpload(upload *ImageUpload, data io.Reader) error {
+	if upload.SHA256 == "" {
+		return nil
+	}
+
+	hash := sha256.New()
+	_, err := io.Copy(hash, data)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot read upload %v", err)
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != upload.SHA256 {
+		return status.Errorf(codes.DataLoss, "image sha256 %s doesnt match %s", checksum, upload.SHA256)
+	}
+
+	return nil
+}
+
+// validateImage checks that the image is in the format of its type, which can be empty,
+// and returns it without its data
+func validateImage(imageType string, data io.ReadSeeker) (*imaging.Image, error) {
+	_, err := data.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot read image %v", err)
+	}
+
+	image, err := imaging.DecodeHeader(data, imageLimits)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid image %v", err)
+	}
+
+	if imageType != "" {
+		format, err := imaging.FormatFromExtension(imageType)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported image type %v", err)
+		}
+		if format != image.Format {
+			return nil, status.Errorf(codes.InvalidArgument, "image is a %s, not a %s", image.Format, format)
+		}
+	}
+
+	return image, nil
+}
+
+// saveThumbnails decodes the image read from data to save its thumbnails
+func (server *LaptopServer) saveThumbnails(imageID string, format imaging.Format, data io.ReadSeeker) error {
+	_, err := data.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	sizes := make([]int, len(imageThumbnails))
+	for i, thumbnail := range imageThumbnails {
+		sizes[i] = thumbnail.size
+	}
+
+	thumbnails, err := imaging.DecodeThumbnails(data, format, sizes...)
+	if err != nil {
+		return err
+	}
+
+	for i, thumbnail := range thumbnails {
+		info := ThumbnailInfo{
+			Name:   imageThumbnails[i].name,
+			Type:   thumbnail.Format.Extension(),
+			Width:  thumbnail.Width,
+			Height: thumbnail.Height,
+		}
+
+		err := server.imageStore.SaveThumbnail(imageID, info, bytes.NewReader(thumbnail.Data))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (server *LaptopServer) deleteImageUpload(uploadID string) {
+	err := server.uploadStore.Delete(uploadID)
+	if err != nil {
+		log.Printf("cannot delete upload %s: %v", uploadID, err)
+	}
+}
+
+func imageUploadToProto(upload *ImageUpload) (*pb.ImageUpload, error) {
+	createdAt, err := ptypes.TimestampProto(upload.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ImageUpload{
+		Id: upload.ID,
+		Info: &pb.ImageInfo{
+			LaptopId:  upload.LaptopID,
+			ImageType: upload.ImageType,
+			Size:      uint32(upload.Size),
+			Sha256:    upload.SHA256,
+			UploadId:  upload.ID,
+		},
+		Offset:    uint32(upload.Offset),
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func (server *LaptopServer) ListImages(
+	ctx context.Context,
+	req *pb.ListImagesRequest,
+) (*pb.ListImagesResponse, error) {
+	laptopID := req.GetLaptopId()
+	log.Printf("receive a list images request for laptop %s", laptopID)
+
+	laptop, err := server.laptopStore.Find(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find laptop %v", err)
+	}
+	if laptop == nil {
+		return nil, status.Errorf(codes.NotFound, "laptop %s doesnt exist", laptopID)
+	}
+
+	images, err := server.imageStore.List(laptopID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list images %v", err)
+	}
+
+	res := &pb.ListImagesResponse{}
+	for _, info := range images {
+		image, err := imageToProto(info)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot convert image %v", err)
+		}
+		res.Images = append(res.Images, image)
+	}
+
+	return res, nil
+}
+
+// DownloadImage sends the image, then the data of the image or of the thumbnail requested in chunks
+func (server *LaptopServer) DownloadImage(
+	req *pb.DownloadImageRequest,
+	stream pb.LaptopService_DownloadImageServer,
+) error {
+	imageID := req.GetImageId()
+	thumbnail := req.GetThumbnail()
+	log.Printf("receive a download image request for image %s thumbnail %q", imageID, thumbnail)
+
+	info, err := server.imageStore.Find(imageID)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot find image %v", err))
+	}
+	if info == nil {
+		return logError(status.Errorf(codes.NotFound, "image %s doesnt exist", imageID))
+	}
+
+	image, err := imageToProto(info)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot convert image %v", err))
+	}
+
+	var data io.ReadCloser
+	if thumbnail == "" {
+		data, err = server.imageStore.Open(imageID)
+	} else if info.Thumbnail(thumbnail) == nil {
+		return logError(status.Errorf(codes.NotFound, "image %s has no thumbnail %s", imageID, thumbnail))
+	} else {
+		data, err = server.imageStore.OpenThumbnail(imageID, thumbnail)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return logError(status.Errorf(codes.NotFound, "image %s doesnt exist", imageID))
+	}
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot open image %v", err))
+	}
+	defer data.Close()
+
+	err = stream.Send(&pb.DownloadImageResponse{Data: &pb.DownloadImageResponse_Image{Image: image}})
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send image %v", err))
+	}
+
+	chunk := make([]byte, imageChunkSize)
+	for {
+		err := contextError(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		n, err := data.Read(chunk)
+		if n > 0 {
+			res := &pb.DownloadImageResponse{Data: &pb.DownloadImageResponse_ChunkData{ChunkData: chunk[:n]}}
+			err := stream.Send(res)
+			if err != nil {
+				return logError(status.Errorf(codes.Unknown, "cannot send chunk data %v", err))
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Internal, "cannot read image data %v", err))
+		}
+	}
+}
+
+// GetImageURL returns a URL to download an image or one of its thumbnails from the image store
+func (server *LaptopServer) GetImageURL(
+	ctx context.Context,
+	req *pb.GetImageURLRequest,
+) (*pb.GetImageURLResponse, error) {
+	imageID := req.GetImageId()
+	thumbnail := req.GetThumbnail()
+	log.Printf("receive a get image url request for image %s thumbnail %q", imageID, thumbnail)
+
+	expiresAt := time.Now().Add(imageURLDuration)
+	url, err := server.imageStore.ImageURL(imageID, thumbnail, imageURLDuration)
+	if errors.Is(err, ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "image %s doesnt exist", imageID)
+	}
+	if errors.Is(err, ErrImageURLNotSupported) {
+		return nil, status.Errorf(codes.Unimplemented, "cannot get image url %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot get image url %v", err)
+	}
+
+	res := &pb.GetImageURLResponse{Url: url}
+	res.ExpiresAt, err = ptypes.TimestampProto(expiresAt)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot convert expiration time %v", err)
+	}
+
+	return res, nil
+}
+
+func (server *LaptopServer) DeleteImage(
+	ctx context.Context,
+	req *pb.DeleteImageRequest,
+) (*pb.DeleteImageResponse, error) {
+	imageID := req.GetImageId()
+	log.Printf("receive a delete image request for image %s", imageID)
+
+	err := server.imageStore.Delete(imageID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "image %s doesnt exist", imageID)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot delete image %v", err)
+	}
+
+	log.Printf("Deleted image with id: %s", imageID)
+	return &pb.DeleteImageResponse{}, nil
+}
+
+func imageToProto(info *ImageInfo) (*pb.Image, error) {
+	createdAt, err := ptypes.TimestampProto(info.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails := make([]*pb.ImageThumbnail, 0, len(info.Thumbnails))
+	for _, thumbnail := range info.Thumbnails {
+		thumbnails = append(thumbnails, &pb.ImageThumbnail{
+			Name:        thumbnail.Name,
+			Width:       uint32(thumbnail.Width),
+			Height:      uint32(thumbnail.Height),
+			Size:        uint32(thumbnail.Size),
+			ContentType: thumbnail.ContentType(),
+		})
+	}
+
+	return &pb.Image{
+		Id:          info.ID,
+		LaptopId:    info.LaptopID,
+		ImageType:   info.Type,
+		Size:        uint32(info.Size),
+		ContentType: info.ContentType(),
+		CreatedAt:   createdAt,
+		Thumbnails:  thumbnails,
+	}, nil
+}
+
+func (server *LaptopServer) RateLaptop(stream pb.LaptopService_RateLaptopServer) error {
+	for {
+		err := contextError(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		req, err := stream.Recv()
+		if err == io.EOF {
 			log.Print("no more data")
 			break
 		}
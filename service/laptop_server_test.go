@@ -64,7 +64,7 @@ func TestServerCreateLaptop(t *testing.T) {
 			req := &pb.CreateLaptopRequest{
 				Laptop: tc.laptop,
 			}
-			server := service.NewLaptopServer(tc.store, nil, nil)
+			server := service.NewLaptopServer(tc.store, nil, nil, nil, nil, nil)
 			res, err := server.CreateLaptop(context.Background(), req)
 			if tc.code == codes.OK {
 				require.NoError(t, err)
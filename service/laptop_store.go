@@ -5,28 +5,66 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 
 	"github.com/jinzhu/copier"
 	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/proto"
 )
 
 var ErrAlreadyExists = errors.New("record already exosts!")
 
+// ErrNotFound is returned when a record to update or delete does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrVersionMismatch is returned when a record was modified by someone else
+var ErrVersionMismatch = errors.New("record version mismatch")
+
+// BatchError tells which laptop of a batch could not be saved
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (err *BatchError) Error() string {
+	return fmt.Sprintf("laptop %d: %v", err.Index, err.Err)
+}
+
+func (err *BatchError) Unwrap() error {
+	return err.Err
+}
+
+// LaptopStore is an interface to store laptops.
+// Every saved laptop has a version that starts at 1 and grows with each update.
 type LaptopStore interface {
 	Save(laptop *pb.Laptop) error
+	// SaveAll saves either all laptops or none of them.
+	// A laptop that cannot be saved is reported with a *BatchError.
+	SaveAll(laptops []*pb.Laptop) error
 	Find(id string) (*pb.Laptop, error)
+	// Update replaces a laptop only if its stored version equals laptop.Version,
+	// then sets laptop.Version to the new version.
+	Update(laptop *pb.Laptop) error
+	// Delete removes a laptop only if its stored version equals version.
+	// A zero version deletes the laptop unconditionally.
+	Delete(id string, version uint64) error
 	Search(ctx context.Context, filter *pb.Filter, found func(laptop *pb.Laptop) error) error
 }
 
+// InMemoryLaptopStore stores laptops in memory.
+// Stored laptops are never modified, an update replaces them with a new copy,
+// so a search can release the mutex before it copies and sends them.
 type InMemoryLaptopStore struct {
-	mutex sync.RWMutex
-	data  map[string]*pb.Laptop
+	mutex   sync.RWMutex
+	data    map[string]*pb.Laptop
+	indexes []*sortedIndex
 }
 
 func NewInMemoryLaptopStore() *InMemoryLaptopStore {
 	return &InMemoryLaptopStore{
-		data: make(map[string]*pb.Laptop),
+		data:    make(map[string]*pb.Laptop),
+		indexes: newLaptopIndexes(),
 	}
 }
 
@@ -43,7 +81,36 @@ func (store *InMemoryLaptopStore) Save(laptop *pb.Laptop) error {
 	if err != nil {
 		return err
 	}
+	other.Version = 1
 	store.data[other.Id] = other
+	store.addToIndexes(other)
+	return nil
+}
+
+func (store *InMemoryLaptopStore) SaveAll(laptops []*pb.Laptop) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	others := make([]*pb.Laptop, len(laptops))
+	saved := make(map[string]bool)
+	for i, laptop := range laptops {
+		if store.data[laptop.Id] != nil || saved[laptop.Id] {
+			return &BatchError{i, ErrAlreadyExists}
+		}
+		saved[laptop.Id] = true
+
+		other, err := deepCopy(laptop)
+		if err != nil {
+			return &BatchError{i, err}
+		}
+		other.Version = 1
+		others[i] = other
+	}
+
+	for _, other := range others {
+		store.data[other.Id] = other
+		store.addToIndexes(other)
+	}
 	return nil
 }
 
@@ -59,28 +126,62 @@ func (store *InMemoryLaptopStore) Find(id string) (*pb.Laptop, error) {
 	return deepCopy(laptop)
 }
 
+// Update replaces an existing laptop in the store
+func (store *InMemoryLaptopStore) Update(laptop *pb.Laptop) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	current := store.data[laptop.Id]
+	if current == nil {
+		return ErrNotFound
+	}
+	if current.Version != laptop.Version {
+		return ErrVersionMismatch
+	}
+
+	other, err := deepCopy(laptop)
+	if err != nil {
+		return err
+	}
+	other.Version++
+	store.removeFromIndexes(current)
+	store.data[other.Id] = other
+	store.addToIndexes(other)
+	laptop.Version = other.Version
+	return nil
+}
+
+// Delete removes a laptop from the store
+func (store *InMemoryLaptopStore) Delete(id string, version uint64) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	current := store.data[id]
+	if current == nil {
+		return ErrNotFound
+	}
+	if version != 0 && current.Version != version {
+		return ErrVersionMismatch
+	}
+
+	store.removeFromIndexes(current)
+	delete(store.data, id)
+	return nil
+}
+
 func (store *InMemoryLaptopStore) Search(
 	ctx context.Context,
 	filter *pb.Filter,
 	found func(laptop *pb.Laptop) error,
 ) error {
-	store.mutex.RLock()
-	defer store.mutex.RUnlock()
-
-	for _, laptop := range store.data {
+	for _, laptop := range store.candidates(filter) {
 		log.Print("checking laptop id:", laptop.GetId())
 		if ctx.Err() == context.Canceled || ctx.Err() == context.DeadlineExceeded {
 			log.Print("context is canceled")
 			return errors.New("Context canceled")
 		}
 		if isQualified(filter, laptop) {
-			// deep copy
-			other, err := deepCopy(laptop)
-			if err != nil {
-				return err
-			}
-
-			err = found(other)
+			err := found(proto.Clone(laptop).(*pb.Laptop))
 			if err != nil {
 				return err
 			}
@@ -90,21 +191,206 @@ func (store *InMemoryLaptopStore) Search(
 	return nil
 }
 
+// candidates returns a snapshot of the laptops that may match filter.
+// It narrows them down with the index that leaves the fewest laptops.
+// Only the pointers are copied under the read lock: reading the laptops after it is released
+// is safe because the stored laptops are never modified in place, Save and Update store copies.
+func (store *InMemoryLaptopStore) candidates(filter *pb.Filter) []*pb.Laptop {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var best *sortedIndex
+	bestLow, bestHigh := 0, len(store.data)
+	for _, index := range store.indexes {
+		low, high := index.bounds(index.keyRange(filter))
+		if high-low < bestHigh-bestLow {
+			best, bestLow, bestHigh = index, low, high
+		}
+	}
+
+	laptops := make([]*pb.Laptop, 0, bestHigh-bestLow)
+	if best == nil {
+		for _, laptop := range store.data {
+			laptops = append(laptops, laptop)
+		}
+		return laptops
+	}
+
+	for _, entry := range best.entries[bestLow:bestHigh] {
+		laptops = append(laptops, store.data[entry.id])
+	}
+	return laptops
+}
+
+func (store *InMemoryLaptopStore) addToIndexes(laptop *pb.Laptop) {
+	for _, index := range store.indexes {
+		index.add(laptop)
+	}
+}
+
+func (store *InMemoryLaptopStore) removeFromIndexes(laptop *pb.Laptop) {
+	for _, index := range store.indexes {
+		index.remove(laptop)
+	}
+}
+
 func isQualified(filter *pb.Filter, laptop *pb.Laptop) bool {
-	if laptop.GetPriceUsd() > filter.GetMaxPriceUsd() {
+	if filter.GetMaxPriceUsd() > 0 && laptop.GetPriceUsd() > filter.GetMaxPriceUsd() {
+		return false
+	}
+	if laptop.GetPriceUsd() < filter.GetMinPriceUsd() {
+		return false
+	}
+	if !matchesAny(filter.GetBrands(), laptop.GetBrand()) {
+		return false
+	}
+	if !matchesAny(filter.GetNames(), laptop.GetName()) {
 		return false
 	}
+
 	if laptop.GetCpu().GetMinGhz() < filter.GetMinCpuGhz() {
 		return false
 	}
+	if laptop.GetCpu().GetNumberCores() < filter.GetMinCpuCores() {
+		return false
+	}
+	if laptop.GetCpu().GetNumberThreads() < filter.GetMinCpuThreads() {
+		return false
+	}
 
 	if toBit(laptop.GetRam()) < toBit(filter.GetMinRam()) {
 		return false
 	}
 
+	if !hasQualifiedGPU(filter, laptop) {
+		return false
+	}
+	if !hasQualifiedStorage(filter, laptop) {
+		return false
+	}
+	if !isQualifiedScreen(filter, laptop.GetScreen()) {
+		return false
+	}
+	if !isQualifiedKeyboard(filter, laptop.GetKeyboard()) {
+		return false
+	}
+
+	if filter.GetMinWeightKg() > 0 || filter.GetMaxWeightKg() > 0 {
+		weight, ok := weightKg(laptop)
+		if !ok || weight < filter.GetMinWeightKg() {
+			return false
+		}
+		if filter.GetMaxWeightKg() > 0 && weight > filter.GetMaxWeightKg() {
+			return false
+		}
+	}
+
+	if laptop.GetReleaseYear() < filter.GetMinReleaseYear() {
+		return false
+	}
+	if filter.GetMaxReleaseYear() > 0 && laptop.GetReleaseYear() > filter.GetMaxReleaseYear() {
+		return false
+	}
+
+	return true
+}
+
+func hasQualifiedGPU(filter *pb.Filter, laptop *pb.Laptop) bool {
+	if len(filter.GetGpuBrands()) == 0 && filter.GetMinGpuMemory() == nil {
+		return true
+	}
+
+	for _, gpu := range laptop.GetGpus() {
+		if matchesAny(filter.GetGpuBrands(), gpu.GetBrand()) &&
+			toBit(gpu.GetMemory()) >= toBit(filter.GetMinGpuMemory()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasQualifiedStorage(filter *pb.Filter, laptop *pb.Laptop) bool {
+	if filter.GetStorageDriver() == pb.Storage_UNKNOWN && filter.GetMinStorage() == nil {
+		return true
+	}
+
+	for _, storage := range laptop.GetStorages() {
+		if filter.GetStorageDriver() != pb.Storage_UNKNOWN && storage.GetDriver() != filter.GetStorageDriver() {
+			continue
+		}
+		if toBit(storage.GetMemory()) >= toBit(filter.GetMinStorage()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isQualifiedScreen(filter *pb.Filter, screen *pb.Screen) bool {
+	if screen.GetSizeInch() < filter.GetMinScreenSizeInch() {
+		return false
+	}
+	if filter.GetMaxScreenSizeInch() > 0 && screen.GetSizeInch() > filter.GetMaxScreenSizeInch() {
+		return false
+	}
+
+	resolution := filter.GetMinScreenResolution()
+	if screen.GetResolution().GetWidth() < resolution.GetWidth() ||
+		screen.GetResolution().GetHeight() < resolution.GetHeight() {
+		return false
+	}
+
+	if filter.GetScreenPanel() != pb.Screen_UNKNOWN && screen.GetPanel() != filter.GetScreenPanel() {
+		return false
+	}
+	if filter.GetMultitouch() != nil && screen.GetMultitouch() != filter.GetMultitouch().GetValue() {
+		return false
+	}
+
 	return true
 }
 
+func isQualifiedKeyboard(filter *pb.Filter, keyboard *pb.Keyboard) bool {
+	if filter.GetKeyboardLayout() != pb.Keyboard_UNKNOWN && keyboard.GetLayout() != filter.GetKeyboardLayout() {
+		return false
+	}
+	if filter.GetKeyboardBacklit() != nil && keyboard.GetBacklit() != filter.GetKeyboardBacklit().GetValue() {
+		return false
+	}
+
+	return true
+}
+
+// matchesAny reports whether value equals one of values ignoring case, an empty list matches everything
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const kgPerLb = 0.45359237
+
+// weightKg returns the laptop weight in kilograms
+func weightKg(laptop *pb.Laptop) (float64, bool) {
+	switch weight := laptop.GetWeight().(type) {
+	case *pb.Laptop_WeightKg:
+		return weight.WeightKg, true
+	case *pb.Laptop_WeightLb:
+		return weight.WeightLb * kgPerLb, true
+	default:
+		return 0, false
+	}
+}
+
 func toBit(memory *pb.Memory) uint64 {
 	value := memory.GetValue()
 	switch memory.GetUnit() {
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"gitlab.techschool.pcbook/pb"
+)
+
+// keyRange is an inclusive range of sort keys
+type keyRange struct {
+	min float64
+	max float64
+}
+
+// sortedIndex keeps the ids of laptops sorted by a numeric key, so a range of keys can be found by binary search
+type sortedIndex struct {
+	// key returns the sort key of a laptop
+	key func(laptop *pb.Laptop) float64
+	// keyRange returns the keys a laptop must have to match a filter
+	keyRange func(filter *pb.Filter) keyRange
+	entries  []sortedIndexEntry
+}
+
+type sortedIndexEntry struct {
+	key float64
+	id  string
+}
+
+func (a sortedIndexEntry) less(b sortedIndexEntry) bool {
+	if a.key != b.key {
+		return a.key < b.key
+	}
+	return a.id < b.id
+}
+
+// newLaptopIndexes returns the sorted indexes on price, CPU GHz, RAM and release year
+func newLaptopIndexes() []*sortedIndex {
+	return []*sortedIndex{
+		{
+			key: func(laptop *pb.Laptop) float64 { return laptop.GetPriceUsd() },
+			keyRange: func(filter *pb.Filter) keyRange {
+				return newKeyRange(filter.GetMinPriceUsd(), filter.GetMaxPriceUsd())
+			},
+		},
+		{
+			key: func(laptop *pb.Laptop) float64 { return laptop.GetCpu().GetMinGhz() },
+			keyRange: func(filter *pb.Filter) keyRange {
+				return newKeyRange(filter.GetMinCpuGhz(), 0)
+			},
+		},
+		{
+			key: func(laptop *pb.Laptop) float64 { return float64(toBit(laptop.GetRam())) },
+			keyRange: func(filter *pb.Filter) keyRange {
+				return newKeyRange(float64(toBit(filter.GetMinRam())), 0)
+			},
+		},
+		{
+			key: func(laptop *pb.Laptop) float64 { return float64(laptop.GetReleaseYear()) },
+			keyRange: func(filter *pb.Filter) keyRange {
+				return newKeyRange(float64(filter.GetMinReleaseYear()), float64(filter.GetMaxReleaseYear()))
+			},
+		},
+	}
+}
+
+// newKeyRange returns the range from min to max, where a zero max means unbounded like in filters
+func newKeyRange(min, max float64) keyRange {
+	if max <= 0 {
+		max = math.Inf(1)
+	}
+
+	return keyRange{min: min, max: max}
+}
+
+func (index *sortedIndex) add(laptop *pb.Laptop) {
+	entry := sortedIndexEntry{index.key(laptop), laptop.GetId()}
+	i := sort.Search(len(index.entries), func(i int) bool {
+		return !index.entries[i].less(entry)
+	})
+
+	index.entries = append(index.entries, sortedIndexEntry{})
+	copy(index.entries[i+1:], index.entries[i:])
+	index.entries[i] = entry
+}
+
+func (index *sortedIndex) remove(laptop *pb.Laptop) {
+	entry := sortedIndexEntry{index.key(laptop), laptop.GetId()}
+	i := sort.Search(len(index.entries), func(i int) bool {
+		return !index.entries[i].less(entry)
+	})
+
+	if i < len(index.entries) && index.entries[i] == entry {
+		index.entries = append(index.entries[:i], index.entries[i+1:]...)
+	}
+}
+
+// bounds returns the positions of the first entry in r and of the first entry after it
+func (index *sortedIndex) bounds(r keyRange) (int, int) {
+	low := sort.Search(len(index.entries), func(i int) bool {
+		return index.entries[i].key >= r.min
+	})
+	high := sort.Search(len(index.entries), func(i int) bool {
+		return index.entries[i].key > r.max
+	})
+
+	if high < low {
+		high = low
+	}
+	return low, high
+}
@@ -0,0 +1,358 @@
+package service_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInMemoryLaptopStore(t *testing.T) {
+	t.Parallel()
+	testLaptopStore(t, service.NewInMemoryLaptopStore())
+}
+
+func TestBoltLaptopStore(t *testing.T) {
+	t.Parallel()
+	store, err := service.NewBoltLaptopStore(newTestDBPath(t))
+	require.NoError(t, err)
+	defer store.Close()
+
+	testLaptopStore(t, store)
+}
+
+func TestBoltLaptopStoreReopen(t *testing.T) {
+	t.Parallel()
+	dbPath := newTestDBPath(t)
+
+	store, err := service.NewBoltLaptopStore(dbPath)
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	err = store.Save(laptop)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = service.NewBoltLaptopStore(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	other, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	laptop.Version = 1
+	require.True(t, proto.Equal(laptop, other))
+}
+
+func TestBoltLaptopStoreSearchDoesNotBlockWrites(t *testing.T) {
+	t.Parallel()
+	// not closed if the search is stuck, since closing would wait for it
+	store, err := service.NewBoltLaptopStore(newTestDBPath(t))
+	require.NoError(t, err)
+
+	// more laptops than a search reads in one transaction
+	for i := 0; i < 250; i++ {
+		require.NoError(t, store.Save(sample.NewLaptop()))
+	}
+
+	done := make(chan error)
+	count := 0
+	go func() {
+		done <- store.Search(context.Background(), &pb.Filter{}, func(laptop *pb.Laptop) error {
+			count++
+			if count > 1 {
+				return nil
+			}
+
+			// a laptop large enough for the database to grow its file, which waits for
+			// the read transactions to be closed
+			large := sample.NewLaptop()
+			large.Name = strings.Repeat("x", 4<<20)
+			return store.Save(large)
+		})
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "search blocks the writes to the database")
+	}
+	require.GreaterOrEqual(t, count, 250)
+	require.NoError(t, store.Close())
+}
+
+func TestSQLLaptopStore(t *testing.T) {
+	t.Parallel()
+	db, err := service.OpenSQLDatabase(newTestDBPath(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := service.NewSQLLaptopStore(db)
+	testLaptopStore(t, store)
+
+	laptop := sample.NewLaptop()
+	laptop.Weight = &pb.Laptop_WeightLb{WeightLb: 4.4}
+	err = store.Save(laptop)
+	require.NoError(t, err)
+
+	other, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	laptop.Version = 1
+	require.True(t, proto.Equal(laptop, other))
+}
+
+func TestInMemoryLaptopStoreSearchFilter(t *testing.T) {
+	t.Parallel()
+	testLaptopStoreSearchFilter(t, service.NewInMemoryLaptopStore())
+}
+
+func TestSQLLaptopStoreSearchFilter(t *testing.T) {
+	t.Parallel()
+	db, err := service.OpenSQLDatabase(newTestDBPath(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	testLaptopStoreSearchFilter(t, service.NewSQLLaptopStore(db))
+}
+
+func testLaptopStoreSearchFilter(t *testing.T, store service.LaptopStore) {
+	laptop := sample.NewLaptop()
+	laptop.Brand = "Lenovo"
+	laptop.Name = "Thinkpad X1"
+	laptop.PriceUsd = 1800
+	laptop.Cpu.NumberCores = 8
+	laptop.Cpu.NumberThreads = 16
+	laptop.Gpus = []*pb.GPU{
+		{Brand: "AMD", Memory: &pb.Memory{Value: 2, Unit: pb.Memory_GIGABYTE}},
+		{Brand: "NVIDIA", Memory: &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE}},
+	}
+	laptop.Storages = []*pb.Storage{
+		{Driver: pb.Storage_HDD, Memory: &pb.Memory{Value: 2, Unit: pb.Memory_TERABYTE}},
+		{Driver: pb.Storage_SSD, Memory: &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}},
+	}
+	laptop.Screen = &pb.Screen{
+		SizeInch:   15.6,
+		Resolution: &pb.Screen_Resolution{Width: 3840, Height: 2160},
+		Panel:      pb.Screen_OLED,
+		Multitouch: true,
+	}
+	laptop.Keyboard = &pb.Keyboard{Layout: pb.Keyboard_QWERTY, Backlit: true}
+	laptop.Weight = &pb.Laptop_WeightLb{WeightLb: 4.4}
+	laptop.ReleaseYear = 2019
+
+	// a laptop without a cpu, screen, keyboard or weight matches as if they were zero values
+	bare := &pb.Laptop{Id: "bare", Brand: "Lenovo", PriceUsd: 500}
+
+	require.NoError(t, store.Save(laptop))
+	require.NoError(t, store.Save(bare))
+
+	testCases := []struct {
+		name      string
+		filter    *pb.Filter
+		found     bool
+		foundBare bool
+	}{
+		{"brand", &pb.Filter{Brands: []string{"apple", "lenovo"}}, true, true},
+		{"other brand", &pb.Filter{Brands: []string{"Dell"}}, false, false},
+		{"name", &pb.Filter{Names: []string{"thinkpad x1"}}, true, false},
+		{"min price", &pb.Filter{MinPriceUsd: 1900}, false, false},
+		{"cpu cores", &pb.Filter{MinCpuCores: 8, MinCpuGhz: 1}, true, false},
+		{"cpu threads", &pb.Filter{MinCpuThreads: 16}, true, false},
+		{"too many cpu threads", &pb.Filter{MinCpuThreads: 32}, false, false},
+		{"gpu", &pb.Filter{GpuBrands: []string{"NVIDIA"}, MinGpuMemory: &pb.Memory{Value: 8, Unit: pb.Memory_GIGABYTE}}, true, false},
+		{"gpu memory of other brand", &pb.Filter{GpuBrands: []string{"AMD"}, MinGpuMemory: &pb.Memory{Value: 4, Unit: pb.Memory_GIGABYTE}}, false, false},
+		{"ssd", &pb.Filter{StorageDriver: pb.Storage_SSD, MinStorage: &pb.Memory{Value: 512, Unit: pb.Memory_GIGABYTE}}, true, false},
+		{"1TB ssd", &pb.Filter{StorageDriver: pb.Storage_SSD, MinStorage: &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}}, false, false},
+		{"1TB any storage", &pb.Filter{MinStorage: &pb.Memory{Value: 1, Unit: pb.Memory_TERABYTE}}, true, false},
+		{"screen", &pb.Filter{MinScreenSizeInch: 15, MaxScreenSizeInch: 16, ScreenPanel: pb.Screen_OLED}, true, false},
+		{"small screen", &pb.Filter{MaxScreenSizeInch: 14}, false, true},
+		{"resolution", &pb.Filter{MinScreenResolution: &pb.Screen_Resolution{Width: 3840, Height: 2160}}, true, false},
+		{"higher resolution", &pb.Filter{MinScreenResolution: &pb.Screen_Resolution{Width: 5120, Height: 2880}}, false, false},
+		{"multitouch", &pb.Filter{Multitouch: &wrappers.BoolValue{Value: true}}, true, false},
+		{"no multitouch", &pb.Filter{Multitouch: &wrappers.BoolValue{Value: false}}, false, true},
+		{"keyboard", &pb.Filter{KeyboardLayout: pb.Keyboard_QWERTY, KeyboardBacklit: &wrappers.BoolValue{Value: true}}, true, false},
+		{"other keyboard layout", &pb.Filter{KeyboardLayout: pb.Keyboard_AZERTY}, false, false},
+		{"no backlight", &pb.Filter{KeyboardBacklit: &wrappers.BoolValue{Value: false}}, false, true},
+		{"weight in pounds", &pb.Filter{MinWeightKg: 1.9, MaxWeightKg: 2.1}, true, false},
+		{"lighter weight", &pb.Filter{MaxWeightKg: 1.5}, false, false},
+		{"release year", &pb.Filter{MinReleaseYear: 2018, MaxReleaseYear: 2019}, true, false},
+		{"older release year", &pb.Filter{MaxReleaseYear: 2018}, false, true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			tc.filter.MaxPriceUsd = 2000
+
+			found, foundBare := false, false
+			err := store.Search(context.Background(), tc.filter, func(other *pb.Laptop) error {
+				found = found || other.Id == laptop.Id
+				foundBare = foundBare || other.Id == bare.Id
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.foundBare, foundBare)
+		})
+	}
+}
+
+func TestInMemoryLaptopStoreSearchIndexes(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryLaptopStore()
+	var laptops []*pb.Laptop
+	for i := 0; i < 50; i++ {
+		laptop := sample.NewLaptop()
+		require.NoError(t, store.Save(laptop))
+		laptops = append(laptops, laptop)
+	}
+
+	// updated and deleted laptops must move or leave the indexes
+	for _, laptop := range laptops[:10] {
+		laptop.Version = 1
+		laptop.PriceUsd += 500
+		require.NoError(t, store.Update(laptop))
+	}
+	for _, laptop := range laptops[10:15] {
+		require.NoError(t, store.Delete(laptop.Id, 0))
+	}
+	laptops = append(laptops[:10], laptops[15:]...)
+
+	filters := []*pb.Filter{
+		{},
+		{MinPriceUsd: 2000, MaxPriceUsd: 2500},
+		{MaxPriceUsd: 1800},
+		{MinCpuGhz: 3},
+		{MinRam: &pb.Memory{Value: 32, Unit: pb.Memory_GIGABYTE}},
+		{MinReleaseYear: 2017, MaxReleaseYear: 2018},
+		{MinPriceUsd: 1800, MinCpuGhz: 2.8, MinReleaseYear: 2019},
+	}
+
+	for _, filter := range filters {
+		var expected []string
+		for _, laptop := range laptops {
+			if laptop.PriceUsd >= filter.MinPriceUsd &&
+				(filter.MaxPriceUsd == 0 || laptop.PriceUsd <= filter.MaxPriceUsd) &&
+				laptop.Cpu.MinGhz >= filter.MinCpuGhz &&
+				(filter.MinRam == nil || laptop.Ram.Unit == pb.Memory_GIGABYTE && laptop.Ram.Value >= filter.MinRam.Value) &&
+				laptop.ReleaseYear >= filter.MinReleaseYear &&
+				(filter.MaxReleaseYear == 0 || laptop.ReleaseYear <= filter.MaxReleaseYear) {
+				expected = append(expected, laptop.Id)
+			}
+		}
+
+		var ids []string
+		err := store.Search(context.Background(), filter, func(laptop *pb.Laptop) error {
+			ids = append(ids, laptop.Id)
+			return nil
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, ids, filter.String())
+	}
+}
+
+func TestInMemoryLaptopStoreSearchDoesNotBlockWrites(t *testing.T) {
+	t.Parallel()
+
+	store := service.NewInMemoryLaptopStore()
+	laptop := sample.NewLaptop()
+	require.NoError(t, store.Save(laptop))
+
+	count := 0
+	err := store.Search(context.Background(), &pb.Filter{}, func(other *pb.Laptop) error {
+		count++
+		other.PriceUsd = 1
+		require.NoError(t, store.Save(sample.NewLaptop()))
+		return store.Delete(other.Id, 0)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	found, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Nil(t, found)
+}
+
+func testLaptopStore(t *testing.T, store service.LaptopStore) {
+	laptop := sample.NewLaptop()
+	err := store.Save(laptop)
+	require.NoError(t, err)
+	require.ErrorIs(t, store.Save(laptop), service.ErrAlreadyExists)
+
+	other, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, other.Version)
+
+	other.PriceUsd = 999
+	err = store.Update(other)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, other.Version)
+
+	other.Version = 1
+	require.ErrorIs(t, store.Update(other), service.ErrVersionMismatch)
+	require.ErrorIs(t, store.Update(sample.NewLaptop()), service.ErrNotFound)
+
+	found, err := store.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Equal(t, 999.0, found.PriceUsd)
+	require.EqualValues(t, 2, found.Version)
+
+	filter := &pb.Filter{MaxPriceUsd: 1000}
+	var ids []string
+	err = store.Search(context.Background(), filter, func(laptop *pb.Laptop) error {
+		ids = append(ids, laptop.Id)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{laptop.Id}, ids)
+
+	require.ErrorIs(t, store.Delete(laptop.Id, 1), service.ErrVersionMismatch)
+	require.NoError(t, store.Delete(laptop.Id, 2))
+	require.ErrorIs(t, store.Delete(laptop.Id, 0), service.ErrNotFound)
+
+	found, err = store.Find(laptop.Id)
+	require.NoError(t, err)
+	require.Nil(t, found)
+
+	existing := sample.NewLaptop()
+	require.NoError(t, store.Save(existing))
+
+	batch := []*pb.Laptop{sample.NewLaptop(), existing}
+	err = store.SaveAll(batch)
+	var batchErr *service.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, 1, batchErr.Index)
+	require.ErrorIs(t, err, service.ErrAlreadyExists)
+
+	found, err = store.Find(batch[0].Id)
+	require.NoError(t, err)
+	require.Nil(t, found, "a failed batch must not save any laptop")
+
+	batch[1] = sample.NewLaptop()
+	require.NoError(t, store.SaveAll(batch))
+	for _, laptop := range batch {
+		found, err = store.Find(laptop.Id)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, found.Version)
+	}
+}
+
+func newTestDBPath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pcbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "pcbook.db")
+}
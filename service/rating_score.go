@@ -4,6 +4,8 @@ import "sync"
 
 type RatingStore interface {
 	Add(laptopID string, score float64) (*Rating, error)
+	Find(laptopID string) (*Rating, error)
+	Delete(laptopID string) error
 }
 
 type Rating struct {
@@ -40,3 +42,25 @@ func (store *InMemoryRatingStore) Add(laptopID string, score float64) (*Rating,
 	store.rating[laptopID] = rating
 	return rating, nil
 }
+
+// Find returns the rating of a laptop, or nil if it has not been rated
+func (store *InMemoryRatingStore) Find(laptopID string) (*Rating, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	rating := store.rating[laptopID]
+	if rating == nil {
+		return nil, nil
+	}
+
+	return &Rating{Count: rating.Count, Sum: rating.Sum}, nil
+}
+
+// Delete removes all ratings of a laptop
+func (store *InMemoryRatingStore) Delete(laptopID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.rating, laptopID)
+	return nil
+}
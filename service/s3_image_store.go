@@ -0,0 +1,518 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gitlab.techschool.pcbook/s3"
+)
+
+// s3Timeout is the time given to the requests to the object storage of an image store call
+const s3Timeout = 30 * time.Second
+
+// s3WriteTimeout is the time given to write the data of an image and to commit it
+const s3WriteTimeout = 5 * time.Minute
+
+// S3ImageStore stores images in a bucket of an S3 compatible object storage:
+// images/ID.json is the image, images/ID.png its data, images/ID-small.png its small thumbnail,
+// laptops/LAPTOP_ID/ID and uploaders/USERNAME/ID are empty objects to list the images
+// of a laptop and of an uploader, and usage/laptops/LAPTOP_ID and usage/uploaders/USERNAME
+// hold the total size of these images
+type S3ImageStore struct {
+	// mutex serializes the updates of the images of the server
+	mutex  sync.Mutex
+	client *s3.Client
+}
+
+func NewS3ImageStore(client *s3.Client) *S3ImageStore {
+	return &S3ImageStore{client: client}
+}
+
+func (store *S3ImageStore) Create(laptopID string, imageType string, uploader string) (ImageWriter, error) {
+	imageID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate image id %w", err)
+	}
+
+	info := &ImageInfo{
+		ID:       imageID.String(),
+		LaptopID: laptopID,
+		Type:     imageType,
+		Path:     s3ImageKey(imageID.String(), imageType),
+		Uploader: uploader,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3WriteTimeout)
+	writer := &s3ImageWriter{
+		store:  store,
+		info:   info,
+		ctx:    ctx,
+		cancel: cancel,
+		object: store.client.NewObjectWriter(ctx, info.Path, info.ContentType()),
+	}
+
+	return writer, nil
+}
+
+// s3ImageWriter writes the data of an image in the parts of a multipart upload,
+// then the image once committed
+type s3ImageWriter struct {
+	store  *S3ImageStore
+	info   *ImageInfo
+	ctx    context.Context
+	cancel context.CancelFunc
+	object *s3.ObjectWriter
+	done   bool
+}
+
+func (writer *s3ImageWriter) Write(p []byte) (int, error) {
+	if writer.done {
+		return 0, errors.New("image is already committed or aborted")
+	}
+
+	n, err := writer.object.Write(p)
+	writer.info.Size += n
+	return n, err
+}
+
+func (writer *s3ImageWriter) Commit() (*ImageInfo, error) {
+	if writer.done {
+		return nil, errors.New("image is already committed or aborted")
+	}
+	writer.done = true
+	defer writer.cancel()
+
+	err := writer.object.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cannot put image data %w", err)
+	}
+
+	info := writer.info
+	info.CreatedAt = time.Now()
+
+	keys := []string{s3LaptopImageKey(info.LaptopID, info.ID)}
+	if info.Uploader != "" {
+		keys = append(keys, s3UploaderImageKey(info.Uploader, info.ID))
+	}
+
+	writer.store.mutex.Lock()
+	defer writer.store.mutex.Unlock()
+
+	err = writer.store.addUsage(writer.ctx, info, info.Size)
+	if err != nil {
+		writer.store.client.DeleteObject(writer.ctx, info.Path)
+		return nil, err
+	}
+
+	err = writer.store.putImage(writer.ctx, info)
+	for _, key := range keys {
+		if err == nil {
+			err = writer.store.client.PutObject(writer.ctx, key, "application/octet-stream", nil)
+		}
+	}
+	if err != nil {
+		writer.store.deleteImage(writer.ctx, info)
+		return nil, fmt.Errorf("cannot put image %w", err)
+	}
+
+	other := *info
+	return &other, nil
+}
+
+func (writer *s3ImageWriter) Abort() error {
+	if writer.done {
+		return nil
+	}
+	writer.done = true
+	defer writer.cancel()
+
+	writer.object.Abort()
+	return nil
+}
+
+func (store *S3ImageStore) Find(imageID string) (*ImageInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	return store.getImage(ctx, imageID)
+}
+
+func (store *S3ImageStore) List(laptopID string) ([]*ImageInfo, error) {
+	return store.listImages(s3LaptopImageKey(laptopID, ""))
+}
+
+func (store *S3ImageStore) ListByUploader(uploader string) ([]*ImageInfo, error) {
+	return store.listImages(s3UploaderImageKey(uploader, ""))
+}
+
+func (store *S3ImageStore) Usage(laptopID string, uploader string) (ImageUsage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	var usage ImageUsage
+	var err error
+	usage.LaptopSize, err = store.getUsage(ctx, s3LaptopUsageKey(laptopID), s3LaptopImageKey(laptopID, ""))
+	if err == nil && uploader != "" {
+		usage.UploaderSize, err = store.getUsage(ctx, s3UploaderUsageKey(uploader), s3UploaderImageKey(uploader, ""))
+	}
+
+	return usage, err
+}
+
+// listImages returns the images whose IDs are the names of the objects starting with prefix,
+// from the oldest to the newest
+func (store *S3ImageStore) listImages(prefix string) ([]*ImageInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	keys, err := store.client.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list images %w", err)
+	}
+
+	var images []*ImageInfo
+	for _, key := range keys {
+		info, err := store.getImage(ctx, strings.TrimPrefix(key, prefix))
+		if err != nil {
+			return nil, err
+		}
+		if info != nil {
+			images = append(images, info)
+		}
+	}
+
+	sortImages(images)
+	return images, nil
+}
+
+func (store *S3ImageStore) Open(imageID string) (io.ReadCloser, error) {
+	info, err := store.Find(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, ErrNotFound
+	}
+
+	return store.getObject(info.Path)
+}
+
+func (store *S3ImageStore) Delete(imageID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	info, err := store.getImage(ctx, imageID)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return ErrNotFound
+	}
+
+	return store.deleteImage(ctx, info)
+}
+
+// DeleteByLaptop removes all images of a laptop from the bucket
+func (store *S3ImageStore) DeleteByLaptop(laptopID string) error {
+	images, err := store.List(laptopID)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	for _, info := range images {
+		err := store.deleteImage(ctx, info)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (store *S3ImageStore) SaveThumbnail(
+	imageID string,
+	thumbnail ThumbnailInfo,
+	thumbnailData io.Reader,
+) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	info, err := store.getImage(ctx, imageID)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return ErrNotFound
+	}
+
+	thumbnail.Path = s3ImageKey(imageID+"-"+thumbnail.Name, thumbnail.Type)
+	object := store.client.NewObjectWriter(ctx, thumbnail.Path, thumbnail.ContentType())
+	size, err := io.Copy(object, thumbnailData)
+	if err != nil {
+		object.Abort()
+		return fmt.Errorf("cannot write thumbnail data %w", err)
+	}
+
+	err = object.Close()
+	if err != nil {
+		return fmt.Errorf("cannot put thumbnail data %w", err)
+	}
+	thumbnail.Size = int(size)
+
+	if other := info.Thumbnail(thumbnail.Name); other != nil {
+		*other = thumbnail
+	} else {
+		info.Thumbnails = append(info.Thumbnails, thumbnail)
+	}
+
+	return store.putImage(ctx, info)
+}
+
+func (store *S3ImageStore) OpenThumbnail(imageID string, name string) (io.ReadCloser, error) {
+	info, err := store.Find(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil || info.Thumbnail(name) == nil {
+		return nil, ErrNotFound
+	}
+
+	return store.getObject(info.Thumbnail(name).Path)
+}
+
+// ImageURL returns a presigned URL of the object of the image or of its thumbnail
+func (store *S3ImageStore) ImageURL(imageID string, thumbnail string, expires time.Duration) (string, error) {
+	info, err := store.Find(imageID)
+	if err != nil {
+		return "", err
+	}
+	if info == nil {
+		return "", ErrNotFound
+	}
+
+	key := info.Path
+	if thumbnail != "" {
+		if info.Thumbnail(thumbnail) == nil {
+			return "", ErrNotFound
+		}
+		key = info.Thumbnail(thumbnail).Path
+	}
+
+	return store.client.PresignGetObject(key, expires)
+}
+
+// getImage returns nil if there is no image with this ID
+func (store *S3ImageStore) getImage(ctx context.Context, imageID string) (*ImageInfo, error) {
+	if _, err := uuid.Parse(imageID); err != nil {
+		return nil, nil
+	}
+
+	object, err := store.client.GetObject(ctx, s3ImageKey(imageID, ".json"))
+	if s3.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get image %w", err)
+	}
+	defer object.Close()
+
+	data, err := ioutil.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read image %w", err)
+	}
+
+	info := &ImageInfo{}
+	err = json.Unmarshal(data, info)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal image %s: %w", imageID, err)
+	}
+
+	info.Path = s3ImageKey(info.ID, info.Type)
+	for i := range info.Thumbnails {
+		thumbnail := &info.Thumbnails[i]
+		thumbnail.Path = s3ImageKey(info.ID+"-"+thumbnail.Name, thumbnail.Type)
+	}
+
+	return info, nil
+}
+
+func (store *S3ImageStore) putImage(ctx context.Context, info *ImageInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("cannot marshal image %w", err)
+	}
+
+	err = store.client.PutObject(ctx, s3ImageKey(info.ID, ".json"), "application/json", data)
+	if err != nil {
+		return fmt.Errorf("cannot put image %w", err)
+	}
+
+	return nil
+}
+
+// deleteImage removes the image before its data, so that the data of an image found can be read
+func (store *S3ImageStore) deleteImage(ctx context.Context, info *ImageInfo) error {
+	err := store.addUsage(ctx, info, -info.Size)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{s3LaptopImageKey(info.LaptopID, info.ID)}
+	if info.Uploader != "" {
+		keys = append(keys, s3UploaderImageKey(info.Uploader, info.ID))
+	}
+	keys = append(keys, s3ImageKey(info.ID, ".json"), info.Path)
+	for _, thumbnail := range info.Thumbnails {
+		keys = append(keys, thumbnail.Path)
+	}
+
+	for _, key := range keys {
+		err := store.client.DeleteObject(ctx, key)
+		if err != nil {
+			return fmt.Errorf("cannot delete %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// addUsage adds delta to the total size of the images of the laptop and of the uploader of an image.
+// It is called with the mutex locked and before the objects listing the image are put or deleted,
+// so that a total missing from the bucket is the one of the images listed before the change.
+func (store *S3ImageStore) addUsage(ctx context.Context, info *ImageInfo, delta int) error {
+	keys := map[string]string{s3LaptopUsageKey(info.LaptopID): s3LaptopImageKey(info.LaptopID, "")}
+	if info.Uploader != "" {
+		keys[s3UploaderUsageKey(info.Uploader)] = s3UploaderImageKey(info.Uploader, "")
+	}
+
+	for key, prefix := range keys {
+		size, err := store.getUsage(ctx, key, prefix)
+		if err != nil {
+			return err
+		}
+
+		size += delta
+		if size <= 0 {
+			err = store.client.DeleteObject(ctx, key)
+		} else {
+			err = store.client.PutObject(ctx, key, "text/plain", []byte(strconv.Itoa(size)))
+		}
+		if err != nil {
+			return fmt.Errorf("cannot put image usage %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getUsage returns the total size of images saved in the object key. A total that is missing,
+// because it is zero or because the images were saved before totals were kept, is summed up
+// from the images whose IDs are the names of the objects starting with prefix.
+func (store *S3ImageStore) getUsage(ctx context.Context, key string, prefix string) (int, error) {
+	object, err := store.client.GetObject(ctx, key)
+	if s3.IsNotFound(err) {
+		images, err := store.listImages(prefix)
+		if err != nil {
+			return 0, err
+		}
+		return imagesSize(images), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot get image usage %w", err)
+	}
+	defer object.Close()
+
+	data, err := ioutil.ReadAll(object)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read image usage %w", err)
+	}
+
+	size, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0, fmt.Errorf("invalid image usage %s: %w", key, err)
+	}
+
+	return size, nil
+}
+
+// getObject returns the data of an object, the context of the request is cancelled when it is closed.
+// The timeout only covers the request until the headers of the response, since a large object
+// can take longer than that to be read.
+func (store *S3ImageStore) getObject(key string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	timeout := time.AfterFunc(s3Timeout, cancel)
+
+	object, err := store.client.GetObject(ctx, key)
+	timeout.Stop()
+	if s3.IsNotFound(err) {
+		cancel()
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("cannot get object %w", err)
+	}
+
+	return &cancelReadCloser{object, cancel}, nil
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (reader *cancelReadCloser) Close() error {
+	defer reader.cancel()
+	return reader.ReadCloser.Close()
+}
+
+// imagesSize returns the total size of images, without their thumbnails
+func imagesSize(images []*ImageInfo) int {
+	size := 0
+	for _, image := range images {
+		size += image.Size
+	}
+
+	return size
+}
+
+func s3ImageKey(name string, imageType string) string {
+	return "images/" + name + imageType
+}
+
+func s3LaptopImageKey(laptopID, imageID string) string {
+	return "laptops/" + laptopID + "/" + imageID
+}
+
+func s3UploaderImageKey(uploader, imageID string) string {
+	return "uploaders/" + uploader + "/" + imageID
+}
+
+func s3LaptopUsageKey(laptopID string) string {
+	return "usage/laptops/" + laptopID
+}
+
+func s3UploaderUsageKey(uploader string) string {
+	return "usage/uploaders/" + uploader
+}
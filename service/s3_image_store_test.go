@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/s3"
+	"gitlab.techschool.pcbook/s3/s3test"
+	"gitlab.techschool.pcbook/service"
+)
+
+func TestS3ImageStore(t *testing.T) {
+	t.Parallel()
+
+	server := s3test.NewServer("pcbook", "access-key")
+	t.Cleanup(server.Close)
+
+	client, err := s3.NewClient(s3.Config{
+		Endpoint:  server.URL,
+		Bucket:    "pcbook",
+		AccessKey: "access-key",
+		SecretKey: "secret-key",
+		PartSize:  1024,
+	})
+	require.NoError(t, err)
+
+	store := service.NewS3ImageStore(client)
+
+	imageData := bytes.Repeat([]byte("laptop"), 500)
+	imageID := saveTestImage(t, store, "laptop1", ".png", "admin1", imageData)
+	saveTestImage(t, store, "laptop2", ".jpg", "admin1", []byte("other laptop"))
+
+	thumbnail := service.ThumbnailInfo{Name: "small", Type: ".png", Width: 1, Height: 1}
+	require.NoError(t, store.SaveThumbnail(imageID, thumbnail, strings.NewReader("thumbnail")))
+
+	images, err := store.List("laptop1")
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	require.Equal(t, imageID, images[0].ID)
+	require.Equal(t, len(imageData), images[0].Size)
+	require.Len(t, images[0].Thumbnails, 1)
+	require.Equal(t, "image/png", server.Object(images[0].Path).ContentType)
+	require.Equal(t, 3, server.Object(images[0].Path).Parts)
+
+	uploaded, err := store.ListByUploader("admin1")
+	require.NoError(t, err)
+	require.Len(t, uploaded, 2)
+
+	usage, err := store.Usage("laptop1", "admin1")
+	require.NoError(t, err)
+	require.Equal(t, service.ImageUsage{LaptopSize: len(imageData), UploaderSize: len(imageData) + 12}, usage)
+
+	// the usage of the images saved before it was kept is summed up
+	require.NoError(t, client.DeleteObject(context.Background(), "usage/uploaders/admin1"))
+	usage, err = store.Usage("laptop2", "admin1")
+	require.NoError(t, err)
+	require.Equal(t, service.ImageUsage{LaptopSize: 12, UploaderSize: len(imageData) + 12}, usage)
+
+	image, err := store.Open(imageID)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(image)
+	require.NoError(t, err)
+	require.NoError(t, image.Close())
+	require.Equal(t, imageData, data)
+
+	url, err := store.ImageURL(imageID, "small", time.Minute)
+	require.NoError(t, err)
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	data, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "thumbnail", string(data))
+
+	_, err = store.ImageURL(imageID, "large", time.Minute)
+	require.Equal(t, service.ErrNotFound, err)
+	_, err = store.OpenThumbnail("unknown", "small")
+	require.Equal(t, service.ErrNotFound, err)
+
+	require.NoError(t, store.DeleteByLaptop("laptop1"))
+	require.Equal(t, service.ErrNotFound, store.Delete(imageID))
+	for _, key := range server.Keys() {
+		require.NotContains(t, key, imageID)
+	}
+	require.Len(t, server.Keys(), 6)
+
+	usage, err = store.Usage("laptop1", "admin1")
+	require.NoError(t, err)
+	require.Equal(t, service.ImageUsage{UploaderSize: 12}, usage)
+}
+
+func TestS3ImageStoreMultipart(t *testing.T) {
+	t.Parallel()
+
+	server := s3test.NewServer("pcbook", "access-key")
+	t.Cleanup(server.Close)
+
+	// the default part size, which is smaller than the largest image of an admin
+	client, err := s3.NewClient(s3.Config{
+		Endpoint:  server.URL,
+		Bucket:    "pcbook",
+		AccessKey: "access-key",
+		SecretKey: "secret-key",
+	})
+	require.NoError(t, err)
+
+	store := service.NewS3ImageStore(client)
+
+	imageData := bytes.Repeat([]byte("laptop"), s3.MinPartSize/6+1000)
+	imageID := saveTestImage(t, store, "laptop1", ".png", "admin1", imageData)
+
+	info, err := store.Find(imageID)
+	require.NoError(t, err)
+	require.Equal(t, len(imageData), info.Size)
+	require.Equal(t, 2, server.Object(info.Path).Parts)
+	require.Zero(t, server.Uploads())
+
+	image, err := store.Open(imageID)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(image)
+	require.NoError(t, err)
+	require.NoError(t, image.Close())
+	require.Equal(t, imageData, data)
+
+	// an aborted image leaves neither its parts nor its object
+	keys := len(server.Keys())
+	writer, err := store.Create("laptop1", ".png", "admin1")
+	require.NoError(t, err)
+	_, err = writer.Write(imageData)
+	require.NoError(t, err)
+	require.Equal(t, 1, server.Uploads())
+	require.NoError(t, writer.Abort())
+	require.Zero(t, server.Uploads())
+	require.Len(t, server.Keys(), keys)
+}
@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientSavedSearches(t *testing.T) {
+	t.Parallel()
+
+	laptopEvents := service.NewLaptopEventBus(10)
+	laptopStore := service.NewEventLaptopStore(service.NewInMemoryLaptopStore(), laptopEvents)
+	jwtManager := service.NewJWTManager("secret", time.Minute)
+	serverAddress := startTestSavedSearchServer(t, service.NewInMemorySavedSearchStore(), laptopEvents, jwtManager)
+	savedSearchClient := newTestSavedSearchClient(t, serverAddress)
+
+	_, err := savedSearchClient.ListSavedSearches(context.Background(), &pb.ListSavedSearchesRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	alice := newTestUserContext(t, jwtManager, "alice")
+	bob := newTestUserContext(t, jwtManager, "bob")
+
+	req := &pb.CreateSavedSearchRequest{
+		SavedSearch: &pb.SavedSearch{Name: "cheap", Filter: &pb.Filter{MaxPriceUsd: 1000}},
+	}
+	res, err := savedSearchClient.CreateSavedSearch(alice, req)
+	require.NoError(t, err)
+	cheap := res.GetSavedSearch()
+	require.NotEmpty(t, cheap.GetId())
+	require.NotNil(t, cheap.GetCreatedAt())
+
+	req = &pb.CreateSavedSearchRequest{
+		SavedSearch: &pb.SavedSearch{Name: "dell", Filter: &pb.Filter{Brands: []string{"Dell"}}},
+	}
+	_, err = savedSearchClient.CreateSavedSearch(bob, req)
+	require.NoError(t, err)
+
+	list, err := savedSearchClient.ListSavedSearches(alice, &pb.ListSavedSearchesRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetSavedSearches(), 1)
+	require.Equal(t, cheap.GetId(), list.GetSavedSearches()[0].GetId())
+
+	_, err = savedSearchClient.GetSavedSearch(bob, &pb.GetSavedSearchRequest{Id: cheap.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	cheap.Name = "under 1000"
+	cheap.CreatedAt = nil
+	_, err = savedSearchClient.UpdateSavedSearch(alice, &pb.UpdateSavedSearchRequest{SavedSearch: cheap})
+	require.NoError(t, err)
+
+	found, err := savedSearchClient.GetSavedSearch(alice, &pb.GetSavedSearchRequest{Id: cheap.GetId()})
+	require.NoError(t, err)
+	require.Equal(t, "under 1000", found.GetSavedSearch().GetName())
+	require.NotNil(t, found.GetSavedSearch().GetCreatedAt())
+
+	ctx, cancel := context.WithCancel(alice)
+	defer cancel()
+	stream, err := savedSearchClient.SubscribeSavedSearches(ctx, &pb.SubscribeSavedSearchesRequest{})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	expensive := sample.NewLaptop()
+	expensive.PriceUsd = 1500
+	require.NoError(t, laptopStore.Save(expensive))
+
+	// dropping into the price range is reported once
+	expensive.Version = 1
+	expensive.PriceUsd = 900
+	require.NoError(t, laptopStore.Update(expensive))
+	expensive.PriceUsd = 800
+	require.NoError(t, laptopStore.Update(expensive))
+
+	cheapLaptop := sample.NewLaptop()
+	cheapLaptop.PriceUsd = 500
+	require.NoError(t, laptopStore.Save(cheapLaptop))
+	require.NoError(t, laptopStore.Delete(cheapLaptop.Id, 0))
+
+	expected := []struct {
+		eventType pb.LaptopEvent_Type
+		laptopID  string
+	}{
+		{pb.LaptopEvent_UPDATED, expensive.Id},
+		{pb.LaptopEvent_CREATED, cheapLaptop.Id},
+	}
+
+	for _, event := range expected {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, []string{cheap.GetId()}, res.GetSavedSearchIds())
+		require.Equal(t, event.eventType, res.GetEvent().GetType())
+		require.Equal(t, event.laptopID, res.GetEvent().GetLaptop().GetId())
+	}
+
+	_, err = savedSearchClient.DeleteSavedSearch(alice, &pb.DeleteSavedSearchRequest{Id: cheap.GetId()})
+	require.NoError(t, err)
+	_, err = savedSearchClient.DeleteSavedSearch(alice, &pb.DeleteSavedSearchRequest{Id: cheap.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func startTestSavedSearchServer(
+	t *testing.T,
+	searchStore service.SavedSearchStore,
+	laptopEvents *service.LaptopEventBus,
+	jwtManager *service.JWTManager,
+) string {
+	const savedSearchServicePath = "/techschool.pcbook.SavedSearchService/"
+	accessibleRoles := map[string][]string{}
+	for _, method := range []string{
+		"CreateSavedSearch",
+		"GetSavedSearch",
+		"ListSavedSearches",
+		"UpdateSavedSearch",
+		"DeleteSavedSearch",
+		"SubscribeSavedSearches",
+	} {
+		accessibleRoles[savedSearchServicePath+method] = []string{"user"}
+	}
+
+	interceptor := service.NewAuthInterceptor(jwtManager, accessibleRoles)
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptor.Unary()),
+		grpc.StreamInterceptor(interceptor.Stream()),
+	)
+	pb.RegisterSavedSearchServiceServer(grpcServer, service.NewSavedSearchServer(searchStore, laptopEvents))
+
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	return listener.Addr().String()
+}
+
+func newTestSavedSearchClient(t *testing.T, serverAddress string) pb.SavedSearchServiceClient {
+	conn, err := grpc.Dial(serverAddress, grpc.WithInsecure())
+	require.NoError(t, err)
+	return pb.NewSavedSearchServiceClient(conn)
+}
+
+// newTestUserContext returns a context with the access token of a new user
+func newTestUserContext(t *testing.T, jwtManager *service.JWTManager, username string) context.Context {
+	user, err := service.NewUser(username, "secret", "user")
+	require.NoError(t, err)
+
+	accessToken, err := jwtManager.Generate(user)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", accessToken)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SavedSearchServer manages the saved searches of the authenticated user
+type SavedSearchServer struct {
+	searchStore  SavedSearchStore
+	laptopEvents *LaptopEventBus
+}
+
+// NewSavedSearchServer returns a saved search server.
+// SubscribeSavedSearches needs laptopEvents, which the laptop store must publish to.
+func NewSavedSearchServer(searchStore SavedSearchStore, laptopEvents *LaptopEventBus) *SavedSearchServer {
+	return &SavedSearchServer{searchStore, laptopEvents}
+}
+
+func (server *SavedSearchServer) CreateSavedSearch(
+	ctx context.Context,
+	req *pb.CreateSavedSearchRequest,
+) (*pb.CreateSavedSearchResponse, error) {
+	username, err := currentUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search := req.GetSavedSearch()
+	if search == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "saved search is required")
+	}
+	log.Printf("receive a create saved search request from %s with filter: %v", username, search.GetFilter())
+
+	if len(search.Id) > 0 {
+		_, err := uuid.Parse(search.Id)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "saved search ID is not a valid UUID: %v", err)
+		}
+	} else {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot generate a new saved search ID %v", err)
+		}
+
+		search.Id = id.String()
+	}
+
+	search.CreatedAt = ptypes.TimestampNow()
+	err = server.searchStore.Save(username, search)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrAlreadyExists) {
+			code = codes.AlreadyExists
+		}
+
+		return nil, status.Errorf(code, "cannot save saved search %v", err)
+	}
+
+	return &pb.CreateSavedSearchResponse{SavedSearch: search}, nil
+}
+
+func (server *SavedSearchServer) GetSavedSearch(
+	ctx context.Context,
+	req *pb.GetSavedSearchRequest,
+) (*pb.GetSavedSearchResponse, error) {
+	search, err := server.findSavedSearch(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetSavedSearchResponse{SavedSearch: search}, nil
+}
+
+func (server *SavedSearchServer) ListSavedSearches(
+	ctx context.Context,
+	req *pb.ListSavedSearchesRequest,
+) (*pb.ListSavedSearchesResponse, error) {
+	username, err := currentUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	searches, err := server.searchStore.List(username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list saved searches %v", err)
+	}
+
+	return &pb.ListSavedSearchesResponse{SavedSearches: searches}, nil
+}
+
+func (server *SavedSearchServer) UpdateSavedSearch(
+	ctx context.Context,
+	req *pb.UpdateSavedSearchRequest,
+) (*pb.UpdateSavedSearchResponse, error) {
+	search := req.GetSavedSearch()
+	found, err := server.findSavedSearch(ctx, search.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	username, _ := currentUsername(ctx)
+	search.CreatedAt = found.GetCreatedAt()
+	err = server.searchStore.Update(username, search)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrNotFound) {
+			code = codes.NotFound
+		}
+
+		return nil, status.Errorf(code, "cannot update saved search %v", err)
+	}
+
+	return &pb.UpdateSavedSearchResponse{SavedSearch: search}, nil
+}
+
+func (server *SavedSearchServer) DeleteSavedSearch(
+	ctx context.Context,
+	req *pb.DeleteSavedSearchRequest,
+) (*pb.DeleteSavedSearchResponse, error) {
+	username, err := currentUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.searchStore.Delete(username, req.GetId())
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrNotFound) {
+			code = codes.NotFound
+		}
+
+		return nil, status.Errorf(code, "cannot delete saved search %v", err)
+	}
+
+	return &pb.DeleteSavedSearchResponse{}, nil
+}
+
+// SubscribeSavedSearches streams the laptops that are created in or updated into
+// one of the saved searches of the user, as they are at the time of the event
+func (server *SavedSearchServer) SubscribeSavedSearches(
+	req *pb.SubscribeSavedSearchesRequest,
+	stream pb.SavedSearchService_SubscribeSavedSearchesServer,
+) error {
+	username, err := currentUsername(stream.Context())
+	if err != nil {
+		return err
+	}
+	log.Printf("receive a subscribe saved searches request from %s", username)
+
+	return watchLaptopEvents(stream, server.laptopEvents, req.GetResumeToken(), func(event *pb.LaptopEvent) error {
+		if event.GetType() == pb.LaptopEvent_DELETED {
+			return nil
+		}
+
+		searches, err := server.searchStore.List(username)
+		if err != nil {
+			return status.Errorf(codes.Internal, "cannot list saved searches %v", err)
+		}
+
+		var ids []string
+		for _, search := range searches {
+			if matchesSavedSearch(search.GetFilter(), event) {
+				ids = append(ids, search.GetId())
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		err = stream.Send(&pb.SubscribeSavedSearchesResponse{SavedSearchIds: ids, Event: event})
+		if err != nil {
+			return logError(status.Errorf(codes.Unknown, "cannot send event: %v", err))
+		}
+
+		log.Printf("sent %v event of laptop with id:%s to %s", event.GetType(), event.GetLaptop().GetId(), username)
+		return nil
+	})
+}
+
+// matchesSavedSearch reports whether a laptop was created matching filter,
+// or updated from not matching it to matching it
+func matchesSavedSearch(filter *pb.Filter, event *pb.LaptopEvent) bool {
+	if !isQualified(filter, event.GetLaptop()) {
+		return false
+	}
+
+	if event.GetType() == pb.LaptopEvent_UPDATED {
+		return event.GetPrevious() == nil || !isQualified(filter, event.GetPrevious())
+	}
+
+	return event.GetType() == pb.LaptopEvent_CREATED
+}
+
+func (server *SavedSearchServer) findSavedSearch(ctx context.Context, id string) (*pb.SavedSearch, error) {
+	username, err := currentUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := server.searchStore.Find(username, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot find saved search %v", err)
+	}
+	if search == nil {
+		return nil, status.Errorf(codes.NotFound, "saved search %s doesnt exist", id)
+	}
+
+	return search, nil
+}
+
+// currentUsername returns the name of the user authenticated by the AuthInterceptor
+func currentUsername(ctx context.Context) (string, error) {
+	claims, ok := UserClaimsFromContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "user is not authenticated")
+	}
+
+	return claims.Username, nil
+}
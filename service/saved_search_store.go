@@ -0,0 +1,109 @@
+package service
+
+import (
+	"sort"
+	"sync"
+
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// SavedSearchStore is an interface to store the saved searches of each user
+type SavedSearchStore interface {
+	Save(username string, search *pb.SavedSearch) error
+	// Find returns nil if the user has no saved search with the id
+	Find(username, id string) (*pb.SavedSearch, error)
+	// List returns the saved searches of a user in the order they were created
+	List(username string) ([]*pb.SavedSearch, error)
+	Update(username string, search *pb.SavedSearch) error
+	Delete(username, id string) error
+}
+
+type InMemorySavedSearchStore struct {
+	mutex sync.RWMutex
+	data  map[string]map[string]*pb.SavedSearch
+}
+
+func NewInMemorySavedSearchStore() *InMemorySavedSearchStore {
+	return &InMemorySavedSearchStore{
+		data: make(map[string]map[string]*pb.SavedSearch),
+	}
+}
+
+func (store *InMemorySavedSearchStore) Save(username string, search *pb.SavedSearch) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.data[username][search.GetId()] != nil {
+		return ErrAlreadyExists
+	}
+
+	if store.data[username] == nil {
+		store.data[username] = make(map[string]*pb.SavedSearch)
+	}
+	store.data[username][search.GetId()] = proto.Clone(search).(*pb.SavedSearch)
+	return nil
+}
+
+func (store *InMemorySavedSearchStore) Find(username, id string) (*pb.SavedSearch, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	search := store.data[username][id]
+	if search == nil {
+		return nil, nil
+	}
+
+	return proto.Clone(search).(*pb.SavedSearch), nil
+}
+
+func (store *InMemorySavedSearchStore) List(username string) ([]*pb.SavedSearch, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	searches := make([]*pb.SavedSearch, 0, len(store.data[username]))
+	for _, search := range store.data[username] {
+		searches = append(searches, proto.Clone(search).(*pb.SavedSearch))
+	}
+
+	sortSavedSearches(searches)
+	return searches, nil
+}
+
+func (store *InMemorySavedSearchStore) Update(username string, search *pb.SavedSearch) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.data[username][search.GetId()] == nil {
+		return ErrNotFound
+	}
+
+	store.data[username][search.GetId()] = proto.Clone(search).(*pb.SavedSearch)
+	return nil
+}
+
+func (store *InMemorySavedSearchStore) Delete(username, id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.data[username][id] == nil {
+		return ErrNotFound
+	}
+
+	delete(store.data[username], id)
+	return nil
+}
+
+// sortSavedSearches orders saved searches by creation time, then by id
+func sortSavedSearches(searches []*pb.SavedSearch) {
+	sort.Slice(searches, func(i, j int) bool {
+		a, b := searches[i].GetCreatedAt(), searches[j].GetCreatedAt()
+		if a.GetSeconds() != b.GetSeconds() {
+			return a.GetSeconds() < b.GetSeconds()
+		}
+		if a.GetNanos() != b.GetNanos() {
+			return a.GetNanos() < b.GetNanos()
+		}
+		return searches[i].GetId() < searches[j].GetId()
+	})
+}
@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInMemorySavedSearchStore(t *testing.T) {
+	t.Parallel()
+	testSavedSearchStore(t, service.NewInMemorySavedSearchStore())
+}
+
+func TestSQLSavedSearchStore(t *testing.T) {
+	t.Parallel()
+	db, err := service.OpenSQLDatabase(newTestDBPath(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	testSavedSearchStore(t, service.NewSQLSavedSearchStore(db))
+}
+
+func TestBoltSavedSearchStore(t *testing.T) {
+	t.Parallel()
+	laptopStore, err := service.NewBoltLaptopStore(newTestDBPath(t))
+	require.NoError(t, err)
+	defer laptopStore.Close()
+
+	store, err := service.NewBoltSavedSearchStore(laptopStore.DB())
+	require.NoError(t, err)
+	testSavedSearchStore(t, store)
+}
+
+func testSavedSearchStore(t *testing.T, store service.SavedSearchStore) {
+	first := &pb.SavedSearch{
+		Id:        uuid.New().String(),
+		Name:      "cheap",
+		Filter:    &pb.Filter{MaxPriceUsd: 1000},
+		CreatedAt: ptypes.TimestampNow(),
+	}
+	second := &pb.SavedSearch{
+		Id:        uuid.New().String(),
+		Name:      "32GB",
+		Filter:    &pb.Filter{MinRam: &pb.Memory{Value: 32, Unit: pb.Memory_GIGABYTE}},
+		CreatedAt: ptypes.TimestampNow(),
+	}
+
+	require.NoError(t, store.Save("alice", first))
+	require.NoError(t, store.Save("alice", second))
+	require.ErrorIs(t, store.Save("alice", first), service.ErrAlreadyExists)
+	require.NoError(t, store.Save("bob", first))
+
+	found, err := store.Find("alice", first.Id)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(first, found))
+
+	found, err = store.Find("carol", first.Id)
+	require.NoError(t, err)
+	require.Nil(t, found)
+
+	searches, err := store.List("alice")
+	require.NoError(t, err)
+	require.Len(t, searches, 2)
+	require.Equal(t, first.Id, searches[0].Id)
+	require.Equal(t, second.Id, searches[1].Id)
+
+	first.Name = "very cheap"
+	first.Filter.MaxPriceUsd = 500
+	require.NoError(t, store.Update("alice", first))
+	require.ErrorIs(t, store.Update("carol", first), service.ErrNotFound)
+
+	found, err = store.Find("alice", first.Id)
+	require.NoError(t, err)
+	require.Equal(t, "very cheap", found.Name)
+	require.Equal(t, 500.0, found.Filter.MaxPriceUsd)
+
+	found, err = store.Find("bob", first.Id)
+	require.NoError(t, err)
+	require.Equal(t, "cheap", found.Name)
+
+	require.NoError(t, store.Delete("alice", first.Id))
+	require.ErrorIs(t, store.Delete("alice", first.Id), service.ErrNotFound)
+
+	searches, err = store.List("alice")
+	require.NoError(t, err)
+	require.Len(t, searches, 1)
+
+	searches, err = store.List("carol")
+	require.NoError(t, err)
+	require.Empty(t, searches)
+}
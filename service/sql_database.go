@@ -0,0 +1,185 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	// registers the pure Go "sqlite" driver
+	_ "modernc.org/sqlite"
+)
+
+// sqlMigrations are applied in order, each one exactly once.
+// Never edit a released migration, append a new one instead.
+var sqlMigrations = []string{
+	`
+	CREATE TABLE laptops (
+		id TEXT PRIMARY KEY,
+		brand TEXT NOT NULL,
+		name TEXT NOT NULL,
+		ram_value INTEGER,
+		ram_unit INTEGER,
+		ram_bits INTEGER NOT NULL,
+		keyboard_layout INTEGER,
+		keyboard_backlit INTEGER,
+		weight_kg REAL,
+		weight_lb REAL,
+		price_usd REAL NOT NULL,
+		release_year INTEGER NOT NULL,
+		updated_at_seconds INTEGER,
+		updated_at_nanos INTEGER,
+		version INTEGER NOT NULL
+	);
+	CREATE INDEX laptops_price_usd ON laptops (price_usd);
+	CREATE INDEX laptops_ram_bits ON laptops (ram_bits);
+	CREATE INDEX laptops_release_year ON laptops (release_year);
+
+	CREATE TABLE cpus (
+		laptop_id TEXT PRIMARY KEY REFERENCES laptops (id),
+		brand TEXT NOT NULL,
+		name TEXT NOT NULL,
+		number_cores INTEGER NOT NULL,
+		number_threads INTEGER NOT NULL,
+		min_ghz REAL NOT NULL,
+		max_ghz REAL NOT NULL
+	);
+	CREATE INDEX cpus_min_ghz ON cpus (min_ghz);
+
+	CREATE TABLE gpus (
+		laptop_id TEXT NOT NULL REFERENCES laptops (id),
+		position INTEGER NOT NULL,
+		brand TEXT NOT NULL,
+		name TEXT NOT NULL,
+		min_ghz REAL NOT NULL,
+		max_ghz REAL NOT NULL,
+		memory_value INTEGER,
+		memory_unit INTEGER,
+		PRIMARY KEY (laptop_id, position)
+	);
+
+	CREATE TABLE storages (
+		laptop_id TEXT NOT NULL REFERENCES laptops (id),
+		position INTEGER NOT NULL,
+		driver INTEGER NOT NULL,
+		memory_value INTEGER,
+		memory_unit INTEGER,
+		PRIMARY KEY (laptop_id, position)
+	);
+
+	CREATE TABLE screens (
+		laptop_id TEXT PRIMARY KEY REFERENCES laptops (id),
+		size_inch REAL NOT NULL,
+		resolution_width INTEGER,
+		resolution_height INTEGER,
+		panel INTEGER NOT NULL,
+		multitouch INTEGER NOT NULL
+	);
+	`,
+	`
+	CREATE TABLE ratings (
+		laptop_id TEXT PRIMARY KEY,
+		count INTEGER NOT NULL,
+		sum REAL NOT NULL
+	);
+
+	CREATE TABLE users (
+		username TEXT PRIMARY KEY,
+		hashed_password TEXT NOT NULL,
+		role TEXT NOT NULL
+	);
+	`,
+	`
+	CREATE TABLE saved_searches (
+		username TEXT NOT NULL,
+		id TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		PRIMARY KEY (username, id)
+	);
+	`,
+	`
+	CREATE TABLE webhooks (
+		id TEXT PRIMARY KEY,
+		created_at INTEGER NOT NULL,
+		data BLOB NOT NULL
+	);
+
+	CREATE TABLE webhook_dead_letters (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		id TEXT NOT NULL,
+		webhook_id TEXT NOT NULL,
+		data BLOB NOT NULL
+	);
+	`,
+	`
+	CREATE INDEX cpus_number_cores ON cpus (number_cores);
+	`,
+}
+
+// OpenSQLDatabase opens the SQLite database file at path and migrates it to the latest schema
+func OpenSQLDatabase(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database %w", err)
+	}
+
+	// SQLite allows a single writer, so share one connection instead of failing with "database is locked"
+	db.SetMaxOpenConns(1)
+
+	err = migrateSQLDatabase(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func migrateSQLDatabase(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return fmt.Errorf("cannot create migrations table %w", err)
+	}
+
+	var current int
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("cannot read schema version %w", err)
+	}
+
+	for i := current; i < len(sqlMigrations); i++ {
+		version := i + 1
+		err = withTx(db, func(tx *sql.Tx) error {
+			_, err := tx.Exec(sqlMigrations[i])
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("cannot apply migration %d %w", version, err)
+		}
+
+		log.Printf("applied database migration %d", version)
+	}
+
+	return nil
+}
+
+// withTx runs fn in a transaction and commits it if fn succeeds
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,74 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/service"
+)
+
+func TestSQLDatabaseReopen(t *testing.T) {
+	t.Parallel()
+	dbPath := newTestDBPath(t)
+
+	db, err := service.OpenSQLDatabase(dbPath)
+	require.NoError(t, err)
+
+	laptop := sample.NewLaptop()
+	err = service.NewSQLLaptopStore(db).Save(laptop)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// migrations already applied must not run again
+	db, err = service.OpenSQLDatabase(dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	other, err := service.NewSQLLaptopStore(db).Find(laptop.Id)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+}
+
+func TestSQLRatingStore(t *testing.T) {
+	t.Parallel()
+	db, err := service.OpenSQLDatabase(newTestDBPath(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := service.NewSQLRatingStore(db)
+	rating, err := store.Add("laptop", 8)
+	require.NoError(t, err)
+	require.Equal(t, &service.Rating{Count: 1, Sum: 8}, rating)
+
+	rating, err = store.Add("laptop", 7.5)
+	require.NoError(t, err)
+	require.Equal(t, &service.Rating{Count: 2, Sum: 15.5}, rating)
+
+	require.NoError(t, store.Delete("laptop"))
+	rating, err = store.Add("laptop", 10)
+	require.NoError(t, err)
+	require.Equal(t, &service.Rating{Count: 1, Sum: 10}, rating)
+}
+
+func TestSQLUserStore(t *testing.T) {
+	t.Parallel()
+	db, err := service.OpenSQLDatabase(newTestDBPath(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := service.NewSQLUserStore(db)
+	user, err := service.NewUser("admin1", "secret", "admin")
+	require.NoError(t, err)
+	require.NoError(t, store.Save(user))
+	require.ErrorIs(t, store.Save(user), service.ErrAlreadyExists)
+
+	other, err := store.Find("admin1")
+	require.NoError(t, err)
+	require.Equal(t, user, other)
+	require.True(t, other.IsCorrectPassword("secret"))
+
+	other, err = store.Find("unknown")
+	require.NoError(t, err)
+	require.Nil(t, other)
+}
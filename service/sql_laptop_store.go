@@ -0,0 +1,642 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SQLLaptopStore stores laptops in normalized SQL tables
+type SQLLaptopStore struct {
+	db *sql.DB
+}
+
+// NewSQLLaptopStore returns a laptop store on top of a database opened with OpenSQLDatabase
+func NewSQLLaptopStore(db *sql.DB) *SQLLaptopStore {
+	return &SQLLaptopStore{db}
+}
+
+func (store *SQLLaptopStore) Save(laptop *pb.Laptop) error {
+	return withTx(store.db, func(tx *sql.Tx) error {
+		version, err := laptopVersion(tx, laptop.Id)
+		if err != nil {
+			return err
+		}
+		if version != 0 {
+			return ErrAlreadyExists
+		}
+
+		return insertLaptop(tx, laptop, 1)
+	})
+}
+
+func (store *SQLLaptopStore) SaveAll(laptops []*pb.Laptop) error {
+	return withTx(store.db, func(tx *sql.Tx) error {
+		for i, laptop := range laptops {
+			version, err := laptopVersion(tx, laptop.Id)
+			if err != nil {
+				return &BatchError{i, err}
+			}
+			if version != 0 {
+				return &BatchError{i, ErrAlreadyExists}
+			}
+
+			err = insertLaptop(tx, laptop, 1)
+			if err != nil {
+				return &BatchError{i, err}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (store *SQLLaptopStore) Find(id string) (*pb.Laptop, error) {
+	var laptop *pb.Laptop
+	err := withTx(store.db, func(tx *sql.Tx) error {
+		var err error
+		laptop, err = selectLaptop(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return laptop, nil
+}
+
+func (store *SQLLaptopStore) Update(laptop *pb.Laptop) error {
+	err := withTx(store.db, func(tx *sql.Tx) error {
+		version, err := laptopVersion(tx, laptop.Id)
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			return ErrNotFound
+		}
+		if version != laptop.Version {
+			return ErrVersionMismatch
+		}
+
+		err = deleteLaptop(tx, laptop.Id)
+		if err != nil {
+			return err
+		}
+
+		return insertLaptop(tx, laptop, version+1)
+	})
+	if err != nil {
+		return err
+	}
+
+	laptop.Version++
+	return nil
+}
+
+func (store *SQLLaptopStore) Delete(id string, version uint64) error {
+	return withTx(store.db, func(tx *sql.Tx) error {
+		current, err := laptopVersion(tx, id)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			return ErrNotFound
+		}
+		if version != 0 && current != version {
+			return ErrVersionMismatch
+		}
+
+		return deleteLaptop(tx, id)
+	})
+}
+
+// sqlSearchBatchSize is the number of matching laptops Search loads at once
+const sqlSearchBatchSize = 100
+
+// Search narrows the candidates with indexed SQL conditions,
+// then isQualified makes the final decision so every store returns the same laptops.
+func (store *SQLLaptopStore) Search(
+	ctx context.Context,
+	filter *pb.Filter,
+	found func(laptop *pb.Laptop) error,
+) error {
+	query, args := laptopFilterSQL(filter)
+
+	// read all ids first, the single database connection is needed to load the laptops
+	rows, err := store.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("cannot search laptops %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > sqlSearchBatchSize {
+			batch = batch[:sqlSearchBatchSize]
+		}
+		ids = ids[len(batch):]
+
+		var laptops map[string]*pb.Laptop
+		err = withTx(store.db, func(tx *sql.Tx) error {
+			var err error
+			laptops, err = selectLaptops(tx, batch)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range batch {
+			if ctx.Err() == context.Canceled || ctx.Err() == context.DeadlineExceeded {
+				log.Print("context is canceled")
+				return errors.New("Context canceled")
+			}
+
+			// deleted since the ids were read
+			laptop := laptops[id]
+			if laptop == nil || !isQualified(filter, laptop) {
+				continue
+			}
+
+			err = found(laptop)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// laptopFilterSQL translates a filter to a query selecting the ids of the matching laptops.
+// A laptop without a CPU, screen or keyboard matches as if they were zero values, like isQualified does.
+func laptopFilterSQL(filter *pb.Filter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, values ...interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, values...)
+	}
+
+	if filter.GetMinPriceUsd() > 0 {
+		add("l.price_usd >= ?", filter.GetMinPriceUsd())
+	}
+	if filter.GetMaxPriceUsd() > 0 {
+		add("l.price_usd <= ?", filter.GetMaxPriceUsd())
+	}
+	if len(filter.GetBrands()) > 0 {
+		add("l.brand COLLATE NOCASE IN ("+placeholders(len(filter.GetBrands()))+")", stringArgs(filter.GetBrands())...)
+	}
+	if len(filter.GetNames()) > 0 {
+		add("l.name COLLATE NOCASE IN ("+placeholders(len(filter.GetNames()))+")", stringArgs(filter.GetNames())...)
+	}
+
+	// the cpu conditions can't be met without a cpu, so they select from the join
+	join := ""
+	if filter.GetMinCpuGhz() > 0 || filter.GetMinCpuCores() > 0 || filter.GetMinCpuThreads() > 0 {
+		join = " JOIN cpus c ON c.laptop_id = l.id"
+	}
+	if filter.GetMinCpuGhz() > 0 {
+		add("c.min_ghz >= ?", filter.GetMinCpuGhz())
+	}
+	if filter.GetMinCpuCores() > 0 {
+		add("c.number_cores >= ?", filter.GetMinCpuCores())
+	}
+	if filter.GetMinCpuThreads() > 0 {
+		add("c.number_threads >= ?", filter.GetMinCpuThreads())
+	}
+
+	if minRam := toBit(filter.GetMinRam()); minRam > 0 {
+		add("l.ram_bits >= ?", int64(minRam))
+	}
+
+	if len(filter.GetGpuBrands()) > 0 || filter.GetMinGpuMemory() != nil {
+		gpu := []string{"g.laptop_id = l.id", memoryBitsSQL("g") + " >= ?"}
+		gpuArgs := []interface{}{int64(toBit(filter.GetMinGpuMemory()))}
+		if len(filter.GetGpuBrands()) > 0 {
+			gpu = append(gpu, "g.brand COLLATE NOCASE IN ("+placeholders(len(filter.GetGpuBrands()))+")")
+			gpuArgs = append(gpuArgs, stringArgs(filter.GetGpuBrands())...)
+		}
+		add("EXISTS (SELECT 1 FROM gpus g WHERE "+strings.Join(gpu, " AND ")+")", gpuArgs...)
+	}
+
+	if filter.GetStorageDriver() != pb.Storage_UNKNOWN || filter.GetMinStorage() != nil {
+		storage := []string{"s.laptop_id = l.id", memoryBitsSQL("s") + " >= ?"}
+		storageArgs := []interface{}{int64(toBit(filter.GetMinStorage()))}
+		if filter.GetStorageDriver() != pb.Storage_UNKNOWN {
+			storage = append(storage, "s.driver = ?")
+			storageArgs = append(storageArgs, filter.GetStorageDriver())
+		}
+		add("EXISTS (SELECT 1 FROM storages s WHERE "+strings.Join(storage, " AND ")+")", storageArgs...)
+	}
+
+	if screen, screenArgs := screenFilterSQL(filter); len(screen) > 0 {
+		condition := "EXISTS (SELECT 1 FROM screens s WHERE " + strings.Join(screen, " AND ") + ")"
+		if isQualifiedScreen(filter, nil) {
+			condition = "(" + condition + " OR NOT EXISTS (SELECT 1 FROM screens s WHERE s.laptop_id = l.id))"
+		}
+		add(condition, screenArgs...)
+	}
+
+	if filter.GetKeyboardLayout() != pb.Keyboard_UNKNOWN {
+		add("l.keyboard_layout = ?", filter.GetKeyboardLayout())
+	}
+	if filter.GetKeyboardBacklit() != nil {
+		add("COALESCE(l.keyboard_backlit, 0) = ?", filter.GetKeyboardBacklit().GetValue())
+	}
+
+	if filter.GetMinWeightKg() > 0 || filter.GetMaxWeightKg() > 0 {
+		add("COALESCE(l.weight_kg, l.weight_lb * ?) >= ?", kgPerLb, filter.GetMinWeightKg())
+	}
+	if filter.GetMaxWeightKg() > 0 {
+		add("COALESCE(l.weight_kg, l.weight_lb * ?) <= ?", kgPerLb, filter.GetMaxWeightKg())
+	}
+
+	if filter.GetMinReleaseYear() > 0 {
+		add("l.release_year >= ?", filter.GetMinReleaseYear())
+	}
+	if filter.GetMaxReleaseYear() > 0 {
+		add("l.release_year <= ?", filter.GetMaxReleaseYear())
+	}
+
+	query := "SELECT l.id FROM laptops l" + join
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return query, args
+}
+
+// screenFilterSQL returns the conditions of filter on the screens s of laptop l, if it has any
+func screenFilterSQL(filter *pb.Filter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if filter.GetMinScreenSizeInch() > 0 {
+		conditions = append(conditions, "s.size_inch >= ?")
+		args = append(args, filter.GetMinScreenSizeInch())
+	}
+	if filter.GetMaxScreenSizeInch() > 0 {
+		conditions = append(conditions, "s.size_inch <= ?")
+		args = append(args, filter.GetMaxScreenSizeInch())
+	}
+	if resolution := filter.GetMinScreenResolution(); resolution.GetWidth() > 0 || resolution.GetHeight() > 0 {
+		conditions = append(conditions, "COALESCE(s.resolution_width, 0) >= ? AND COALESCE(s.resolution_height, 0) >= ?")
+		args = append(args, resolution.GetWidth(), resolution.GetHeight())
+	}
+	if filter.GetScreenPanel() != pb.Screen_UNKNOWN {
+		conditions = append(conditions, "s.panel = ?")
+		args = append(args, filter.GetScreenPanel())
+	}
+	if filter.GetMultitouch() != nil {
+		conditions = append(conditions, "s.multitouch = ?")
+		args = append(args, filter.GetMultitouch().GetValue())
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	return append([]string{"s.laptop_id = l.id"}, conditions...), args
+}
+
+// memoryBitsSQL is the SQL counterpart of toBit for the memory columns of table t
+func memoryBitsSQL(t string) string {
+	return fmt.Sprintf(
+		"(CASE %[1]s.memory_unit WHEN %[2]d THEN %[1]s.memory_value WHEN %[3]d THEN %[1]s.memory_value << 3 "+
+			"WHEN %[4]d THEN %[1]s.memory_value << 13 WHEN %[5]d THEN %[1]s.memory_value << 23 "+
+			"WHEN %[6]d THEN %[1]s.memory_value << 33 WHEN %[7]d THEN %[1]s.memory_value << 43 ELSE 0 END)",
+		t, pb.Memory_BIT, pb.Memory_BYTE, pb.Memory_KILOBYTE, pb.Memory_MEGABYTE, pb.Memory_GIGABYTE, pb.Memory_TERABYTE,
+	)
+}
+
+// placeholders returns n comma separated query parameters
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+// laptopVersion returns the stored version of a laptop, or 0 if it doesn't exist
+func laptopVersion(tx *sql.Tx, id string) (uint64, error) {
+	var version uint64
+	err := tx.QueryRow(`SELECT version FROM laptops WHERE id = ?`, id).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot read laptop version %w", err)
+	}
+
+	return version, nil
+}
+
+func insertLaptop(tx *sql.Tx, laptop *pb.Laptop, version uint64) error {
+	ramValue, ramUnit := memoryColumns(laptop.GetRam())
+
+	var layout, backlit interface{}
+	if keyboard := laptop.GetKeyboard(); keyboard != nil {
+		layout, backlit = keyboard.GetLayout(), keyboard.GetBacklit()
+	}
+
+	var weightKg, weightLb interface{}
+	switch weight := laptop.GetWeight().(type) {
+	case *pb.Laptop_WeightKg:
+		weightKg = weight.WeightKg
+	case *pb.Laptop_WeightLb:
+		weightLb = weight.WeightLb
+	}
+
+	var seconds, nanos interface{}
+	if updatedAt := laptop.GetUpdatedAt(); updatedAt != nil {
+		seconds, nanos = updatedAt.GetSeconds(), updatedAt.GetNanos()
+	}
+
+	_, err := tx.Exec(
+		`INSERT INTO laptops (
+			id, brand, name, ram_value, ram_unit, ram_bits, keyboard_layout, keyboard_backlit,
+			weight_kg, weight_lb, price_usd, release_year, updated_at_seconds, updated_at_nanos, version
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		laptop.GetId(), laptop.GetBrand(), laptop.GetName(), ramValue, ramUnit, int64(toBit(laptop.GetRam())), layout, backlit,
+		weightKg, weightLb, laptop.GetPriceUsd(), laptop.GetReleaseYear(), seconds, nanos, version,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot insert laptop %w", err)
+	}
+
+	if cpu := laptop.GetCpu(); cpu != nil {
+		_, err = tx.Exec(
+			`INSERT INTO cpus (laptop_id, brand, name, number_cores, number_threads, min_ghz, max_ghz)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			laptop.GetId(), cpu.GetBrand(), cpu.GetName(), cpu.GetNumberCores(), cpu.GetNumberThreads(), cpu.GetMinGhz(), cpu.GetMaxGhz(),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot insert cpu %w", err)
+		}
+	}
+
+	for i, gpu := range laptop.GetGpus() {
+		memoryValue, memoryUnit := memoryColumns(gpu.GetMemory())
+		_, err = tx.Exec(
+			`INSERT INTO gpus (laptop_id, position, brand, name, min_ghz, max_ghz, memory_value, memory_unit)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			laptop.GetId(), i, gpu.GetBrand(), gpu.GetName(), gpu.GetMinGhz(), gpu.GetMaxGhz(), memoryValue, memoryUnit,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot insert gpu %w", err)
+		}
+	}
+
+	for i, storage := range laptop.GetStorages() {
+		memoryValue, memoryUnit := memoryColumns(storage.GetMemory())
+		_, err = tx.Exec(
+			`INSERT INTO storages (laptop_id, position, driver, memory_value, memory_unit) VALUES (?, ?, ?, ?, ?)`,
+			laptop.GetId(), i, storage.GetDriver(), memoryValue, memoryUnit,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot insert storage %w", err)
+		}
+	}
+
+	if screen := laptop.GetScreen(); screen != nil {
+		var width, height interface{}
+		if resolution := screen.GetResolution(); resolution != nil {
+			width, height = resolution.GetWidth(), resolution.GetHeight()
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO screens (laptop_id, size_inch, resolution_width, resolution_height, panel, multitouch)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			laptop.GetId(), screen.GetSizeInch(), width, height, screen.GetPanel(), screen.GetMultitouch(),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot insert screen %w", err)
+		}
+	}
+
+	return nil
+}
+
+func deleteLaptop(tx *sql.Tx, id string) error {
+	for _, table := range []string{"cpus", "gpus", "storages", "screens"} {
+		_, err := tx.Exec(`DELETE FROM `+table+` WHERE laptop_id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("cannot delete from %s %w", table, err)
+		}
+	}
+
+	_, err := tx.Exec(`DELETE FROM laptops WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("cannot delete laptop %w", err)
+	}
+
+	return nil
+}
+
+// selectLaptop loads a laptop with all its parts, or returns nil if it doesn't exist
+func selectLaptop(tx *sql.Tx, id string) (*pb.Laptop, error) {
+	laptops, err := selectLaptops(tx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	return laptops[id], nil
+}
+
+// selectLaptops loads the laptops with the given ids by their id, leaving out the ones that don't exist.
+// The parts stored one per laptop are joined to the laptops, so it takes three queries whatever the number of ids.
+func selectLaptops(tx *sql.Tx, ids []string) (map[string]*pb.Laptop, error) {
+	in, args := placeholders(len(ids)), stringArgs(ids)
+	rows, err := tx.Query(
+		`SELECT l.id, l.brand, l.name, l.ram_value, l.ram_unit, l.keyboard_layout, l.keyboard_backlit,
+			l.weight_kg, l.weight_lb, l.price_usd, l.release_year, l.updated_at_seconds, l.updated_at_nanos, l.version,
+			c.brand, c.name, c.number_cores, c.number_threads, c.min_ghz, c.max_ghz,
+			s.size_inch, s.resolution_width, s.resolution_height, s.panel, s.multitouch
+		FROM laptops l
+		LEFT JOIN cpus c ON c.laptop_id = l.id
+		LEFT JOIN screens s ON s.laptop_id = l.id
+		WHERE l.id IN (`+in+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot select laptops %w", err)
+	}
+	defer rows.Close()
+
+	laptops := make(map[string]*pb.Laptop, len(ids))
+	for rows.Next() {
+		laptop := &pb.Laptop{}
+		var ramValue, ramUnit, layout, seconds, nanos sql.NullInt64
+		var backlit sql.NullBool
+		var weightKg, weightLb sql.NullFloat64
+		var cpuBrand, cpuName sql.NullString
+		var cpuCores, cpuThreads sql.NullInt64
+		var cpuMinGhz, cpuMaxGhz, screenSize sql.NullFloat64
+		var width, height, panel sql.NullInt64
+		var multitouch sql.NullBool
+
+		err = rows.Scan(
+			&laptop.Id, &laptop.Brand, &laptop.Name, &ramValue, &ramUnit, &layout, &backlit,
+			&weightKg, &weightLb, &laptop.PriceUsd, &laptop.ReleaseYear, &seconds, &nanos, &laptop.Version,
+			&cpuBrand, &cpuName, &cpuCores, &cpuThreads, &cpuMinGhz, &cpuMaxGhz,
+			&screenSize, &width, &height, &panel, &multitouch,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan laptop %w", err)
+		}
+
+		laptop.Ram = memoryFromColumns(ramValue, ramUnit)
+		if layout.Valid {
+			laptop.Keyboard = &pb.Keyboard{
+				Layout:  pb.Keyboard_Layout(layout.Int64),
+				Backlit: backlit.Bool,
+			}
+		}
+		if weightKg.Valid {
+			laptop.Weight = &pb.Laptop_WeightKg{WeightKg: weightKg.Float64}
+		} else if weightLb.Valid {
+			laptop.Weight = &pb.Laptop_WeightLb{WeightLb: weightLb.Float64}
+		}
+		if seconds.Valid {
+			laptop.UpdatedAt = &timestamppb.Timestamp{Seconds: seconds.Int64, Nanos: int32(nanos.Int64)}
+		}
+
+		if cpuBrand.Valid {
+			laptop.Cpu = &pb.CPU{
+				Brand:         cpuBrand.String,
+				Name:          cpuName.String,
+				NumberCores:   uint32(cpuCores.Int64),
+				NumberThreads: uint32(cpuThreads.Int64),
+				MinGhz:        cpuMinGhz.Float64,
+				MaxGhz:        cpuMaxGhz.Float64,
+			}
+		}
+
+		if screenSize.Valid {
+			laptop.Screen = &pb.Screen{
+				SizeInch:   float32(screenSize.Float64),
+				Panel:      pb.Screen_Panel(panel.Int64),
+				Multitouch: multitouch.Bool,
+			}
+			if width.Valid {
+				laptop.Screen.Resolution = &pb.Screen_Resolution{Width: uint32(width.Int64), Height: uint32(height.Int64)}
+			}
+		}
+
+		laptops[laptop.Id] = laptop
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = selectGPUs(tx, in, args, laptops)
+	if err != nil {
+		return nil, err
+	}
+
+	err = selectStorages(tx, in, args, laptops)
+	if err != nil {
+		return nil, err
+	}
+
+	return laptops, nil
+}
+
+// selectGPUs adds the gpus of the laptops with the ids in args to laptops
+func selectGPUs(tx *sql.Tx, in string, args []interface{}, laptops map[string]*pb.Laptop) error {
+	rows, err := tx.Query(
+		`SELECT laptop_id, brand, name, min_ghz, max_ghz, memory_value, memory_unit FROM gpus
+		WHERE laptop_id IN (`+in+`) ORDER BY laptop_id, position`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot select gpus %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		gpu := &pb.GPU{}
+		var laptopID string
+		var memoryValue, memoryUnit sql.NullInt64
+		err = rows.Scan(&laptopID, &gpu.Brand, &gpu.Name, &gpu.MinGhz, &gpu.MaxGhz, &memoryValue, &memoryUnit)
+		if err != nil {
+			return fmt.Errorf("cannot scan gpu %w", err)
+		}
+
+		gpu.Memory = memoryFromColumns(memoryValue, memoryUnit)
+		if laptop := laptops[laptopID]; laptop != nil {
+			laptop.Gpus = append(laptop.Gpus, gpu)
+		}
+	}
+
+	return rows.Err()
+}
+
+// selectStorages adds the storages of the laptops with the ids in args to laptops
+func selectStorages(tx *sql.Tx, in string, args []interface{}, laptops map[string]*pb.Laptop) error {
+	rows, err := tx.Query(
+		`SELECT laptop_id, driver, memory_value, memory_unit FROM storages
+		WHERE laptop_id IN (`+in+`) ORDER BY laptop_id, position`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot select storages %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		storage := &pb.Storage{}
+		var laptopID string
+		var memoryValue, memoryUnit sql.NullInt64
+		err = rows.Scan(&laptopID, &storage.Driver, &memoryValue, &memoryUnit)
+		if err != nil {
+			return fmt.Errorf("cannot scan storage %w", err)
+		}
+
+		storage.Memory = memoryFromColumns(memoryValue, memoryUnit)
+		if laptop := laptops[laptopID]; laptop != nil {
+			laptop.Storages = append(laptop.Storages, storage)
+		}
+	}
+
+	return rows.Err()
+}
+
+func memoryColumns(memory *pb.Memory) (interface{}, interface{}) {
+	if memory == nil {
+		return nil, nil
+	}
+
+	return int64(memory.GetValue()), memory.GetUnit()
+}
+
+func memoryFromColumns(value, unit sql.NullInt64) *pb.Memory {
+	if !value.Valid {
+		return nil
+	}
+
+	return &pb.Memory{Value: uint64(value.Int64), Unit: pb.Memory_Unit(unit.Int64)}
+}
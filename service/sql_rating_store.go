@@ -0,0 +1,61 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SQLRatingStore stores laptop ratings in a SQL table
+type SQLRatingStore struct {
+	db *sql.DB
+}
+
+// NewSQLRatingStore returns a rating store on top of a database opened with OpenSQLDatabase
+func NewSQLRatingStore(db *sql.DB) *SQLRatingStore {
+	return &SQLRatingStore{db}
+}
+
+func (store *SQLRatingStore) Add(laptopID string, score float64) (*Rating, error) {
+	rating := &Rating{}
+	err := withTx(store.db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO ratings (laptop_id, count, sum) VALUES (?, 1, ?)
+			ON CONFLICT (laptop_id) DO UPDATE SET count = count + 1, sum = sum + excluded.sum`,
+			laptopID, score,
+		)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(`SELECT count, sum FROM ratings WHERE laptop_id = ?`, laptopID).Scan(&rating.Count, &rating.Sum)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot add rating %w", err)
+	}
+
+	return rating, nil
+}
+
+// Find returns the rating of a laptop, or nil if it has not been rated
+func (store *SQLRatingStore) Find(laptopID string) (*Rating, error) {
+	rating := &Rating{}
+	err := store.db.QueryRow(`SELECT count, sum FROM ratings WHERE laptop_id = ?`, laptopID).Scan(&rating.Count, &rating.Sum)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot find rating %w", err)
+	}
+
+	return rating, nil
+}
+
+// Delete removes all ratings of a laptop
+func (store *SQLRatingStore) Delete(laptopID string) error {
+	_, err := store.db.Exec(`DELETE FROM ratings WHERE laptop_id = ?`, laptopID)
+	if err != nil {
+		return fmt.Errorf("cannot delete ratings %w", err)
+	}
+
+	return nil
+}
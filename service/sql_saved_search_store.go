@@ -0,0 +1,129 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// SQLSavedSearchStore stores saved searches as protobuf in a SQL table
+type SQLSavedSearchStore struct {
+	db *sql.DB
+}
+
+// NewSQLSavedSearchStore returns a saved search store on top of a database opened with OpenSQLDatabase
+func NewSQLSavedSearchStore(db *sql.DB) *SQLSavedSearchStore {
+	return &SQLSavedSearchStore{db}
+}
+
+func (store *SQLSavedSearchStore) Save(username string, search *pb.SavedSearch) error {
+	data, err := proto.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("cannot marshal saved search %w", err)
+	}
+
+	result, err := store.db.Exec(
+		`INSERT INTO saved_searches (username, id, created_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (username, id) DO NOTHING`,
+		username, search.GetId(), savedSearchCreatedAt(search), data,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot save saved search %w", err)
+	}
+
+	return expectOneRow(result, ErrAlreadyExists)
+}
+
+func (store *SQLSavedSearchStore) Find(username, id string) (*pb.SavedSearch, error) {
+	var data []byte
+	err := store.db.QueryRow(`SELECT data FROM saved_searches WHERE username = ? AND id = ?`, username, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot find saved search %w", err)
+	}
+
+	return unmarshalSavedSearch(data)
+}
+
+func (store *SQLSavedSearchStore) List(username string) ([]*pb.SavedSearch, error) {
+	rows, err := store.db.Query(`SELECT data FROM saved_searches WHERE username = ? ORDER BY created_at, id`, username)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list saved searches %w", err)
+	}
+	defer rows.Close()
+
+	searches := []*pb.SavedSearch{}
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list saved searches %w", err)
+		}
+
+		search, err := unmarshalSavedSearch(data)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, rows.Err()
+}
+
+func (store *SQLSavedSearchStore) Update(username string, search *pb.SavedSearch) error {
+	data, err := proto.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("cannot marshal saved search %w", err)
+	}
+
+	result, err := store.db.Exec(
+		`UPDATE saved_searches SET created_at = ?, data = ? WHERE username = ? AND id = ?`,
+		savedSearchCreatedAt(search), data, username, search.GetId(),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot update saved search %w", err)
+	}
+
+	return expectOneRow(result, ErrNotFound)
+}
+
+func (store *SQLSavedSearchStore) Delete(username, id string) error {
+	result, err := store.db.Exec(`DELETE FROM saved_searches WHERE username = ? AND id = ?`, username, id)
+	if err != nil {
+		return fmt.Errorf("cannot delete saved search %w", err)
+	}
+
+	return expectOneRow(result, ErrNotFound)
+}
+
+func savedSearchCreatedAt(search *pb.SavedSearch) int64 {
+	createdAt := search.GetCreatedAt()
+	return createdAt.GetSeconds()*1e9 + int64(createdAt.GetNanos())
+}
+
+func unmarshalSavedSearch(data []byte) (*pb.SavedSearch, error) {
+	search := &pb.SavedSearch{}
+	err := proto.Unmarshal(data, search)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal saved search %w", err)
+	}
+
+	return search, nil
+}
+
+// expectOneRow returns errNone if a statement changed no row
+func expectOneRow(result sql.Result, errNone error) error {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errNone
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SQLUserStore stores users in a SQL table
+type SQLUserStore struct {
+	db *sql.DB
+}
+
+// NewSQLUserStore returns a user store on top of a database opened with OpenSQLDatabase
+func NewSQLUserStore(db *sql.DB) *SQLUserStore {
+	return &SQLUserStore{db}
+}
+
+func (store *SQLUserStore) Save(user *User) error {
+	return withTx(store.db, func(tx *sql.Tx) error {
+		var count int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM users WHERE username = ?`, user.Username).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("cannot find user %w", err)
+		}
+		if count > 0 {
+			return ErrAlreadyExists
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO users (username, hashed_password, role) VALUES (?, ?, ?)`,
+			user.Username, user.HashedPassword, user.Role,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot insert user %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (store *SQLUserStore) Find(username string) (*User, error) {
+	user := &User{Username: username}
+	err := store.db.QueryRow(
+		`SELECT hashed_password, role FROM users WHERE username = ?`, username,
+	).Scan(&user.HashedPassword, &user.Role)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot find user %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// SQLWebhookStore stores webhooks and their dead letters as protobuf in SQL tables
+type SQLWebhookStore struct {
+	db *sql.DB
+}
+
+// NewSQLWebhookStore returns a webhook store on top of a database opened with OpenSQLDatabase
+func NewSQLWebhookStore(db *sql.DB) *SQLWebhookStore {
+	return &SQLWebhookStore{db}
+}
+
+func (store *SQLWebhookStore) Save(webhook *pb.Webhook) error {
+	data, err := proto.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("cannot marshal webhook %w", err)
+	}
+
+	createdAt := webhook.GetCreatedAt()
+	result, err := store.db.Exec(
+		`INSERT INTO webhooks (id, created_at, data) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		webhook.GetId(), createdAt.GetSeconds()*1e9+int64(createdAt.GetNanos()), data,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot save webhook %w", err)
+	}
+
+	return expectOneRow(result, ErrAlreadyExists)
+}
+
+func (store *SQLWebhookStore) Find(id string) (*pb.Webhook, error) {
+	var data []byte
+	err := store.db.QueryRow(`SELECT data FROM webhooks WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot find webhook %w", err)
+	}
+
+	return unmarshalWebhook(data)
+}
+
+func (store *SQLWebhookStore) List() ([]*pb.Webhook, error) {
+	rows, err := store.db.Query(`SELECT data FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list webhooks %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*pb.Webhook{}
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list webhooks %w", err)
+		}
+
+		webhook, err := unmarshalWebhook(data)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (store *SQLWebhookStore) Delete(id string) error {
+	result, err := store.db.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("cannot delete webhook %w", err)
+	}
+
+	return expectOneRow(result, ErrNotFound)
+}
+
+func (store *SQLWebhookStore) SaveDeadLetter(delivery *pb.WebhookDelivery) error {
+	data, err := proto.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("cannot marshal webhook delivery %w", err)
+	}
+
+	_, err = store.db.Exec(
+		`INSERT INTO webhook_dead_letters (id, webhook_id, data) VALUES (?, ?, ?)`,
+		delivery.GetId(), delivery.GetWebhookId(), data,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot save dead letter %w", err)
+	}
+
+	return nil
+}
+
+func (store *SQLWebhookStore) ListDeadLetters(webhookID string) ([]*pb.WebhookDelivery, error) {
+	rows, err := store.db.Query(
+		`SELECT data FROM webhook_dead_letters WHERE ? = '' OR webhook_id = ? ORDER BY seq`,
+		webhookID, webhookID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list dead letters %w", err)
+	}
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (store *SQLWebhookStore) TakeDeadLetters(ids []string) ([]*pb.WebhookDelivery, error) {
+	where := "1 = 1"
+	args := make([]interface{}, len(ids))
+	if len(ids) > 0 {
+		where = "id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		for i, id := range ids {
+			args[i] = id
+		}
+	}
+
+	var deliveries []*pb.WebhookDelivery
+	err := withTx(store.db, func(tx *sql.Tx) error {
+		rows, err := tx.Query(`SELECT data FROM webhook_dead_letters WHERE `+where+` ORDER BY seq`, args...)
+		if err != nil {
+			return err
+		}
+
+		deliveries, err = scanWebhookDeliveries(rows)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`DELETE FROM webhook_dead_letters WHERE `+where, args...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot take dead letters %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// scanWebhookDeliveries reads the data column of every row and closes rows
+func scanWebhookDeliveries(rows *sql.Rows) ([]*pb.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := []*pb.WebhookDelivery{}
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan dead letter %w", err)
+		}
+
+		delivery, err := unmarshalWebhookDelivery(data)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/sample"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// webhookReceiver records the events posted to it, failing the first failures requests
+type webhookReceiver struct {
+	t        *testing.T
+	secret   string
+	mutex    sync.Mutex
+	failures int
+	requests int
+	events   []*pb.WebhookEvent
+}
+
+func (receiver *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(receiver.t, err)
+	require.Equal(receiver.t, service.SignWebhookPayload(receiver.secret, body), r.Header.Get(service.WebhookSignatureHeader))
+
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	receiver.requests++
+	if receiver.failures > 0 {
+		receiver.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	event := &pb.WebhookEvent{}
+	require.NoError(receiver.t, jsonpb.Unmarshal(bytes.NewReader(body), event))
+	require.Equal(receiver.t, event.GetType().String(), r.Header.Get(service.WebhookEventHeader))
+	receiver.events = append(receiver.events, event)
+}
+
+func (receiver *webhookReceiver) setFailures(failures int) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	receiver.failures = failures
+}
+
+func TestClientWebhooks(t *testing.T) {
+	t.Parallel()
+
+	webhookStore := service.NewInMemoryWebhookStore()
+	dispatcher := service.NewWebhookDispatcher(webhookStore, http.DefaultClient, 3, time.Millisecond)
+	serverAddress := startTestWebhookServer(t, service.NewWebhookServer(webhookStore, dispatcher))
+	webhookClient := newTestWebhookClient(t, serverAddress)
+
+	flaky := &webhookReceiver{t: t, secret: "flaky secret", failures: 1}
+	flakyServer := httptest.NewServer(flaky)
+	defer flakyServer.Close()
+
+	down := &webhookReceiver{t: t, failures: 1000}
+	downServer := httptest.NewServer(down)
+	defer downServer.Close()
+
+	res, err := webhookClient.CreateWebhook(context.Background(), &pb.CreateWebhookRequest{Webhook: &pb.Webhook{
+		Url:        flakyServer.URL,
+		EventTypes: []pb.WebhookEvent_Type{pb.WebhookEvent_LAPTOP_CREATED, pb.WebhookEvent_LAPTOP_RATED},
+		Secret:     flaky.secret,
+	}})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetWebhook().GetId())
+
+	res, err = webhookClient.CreateWebhook(context.Background(), &pb.CreateWebhookRequest{Webhook: &pb.Webhook{
+		Url: downServer.URL,
+	}})
+	require.NoError(t, err)
+	downWebhook := res.GetWebhook()
+	require.NotEmpty(t, downWebhook.GetSecret())
+	down.secret = downWebhook.GetSecret()
+
+	_, err = webhookClient.CreateWebhook(context.Background(), &pb.CreateWebhookRequest{Webhook: &pb.Webhook{
+		Url: "ftp://example.com",
+	}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := webhookClient.ListWebhooks(context.Background(), &pb.ListWebhooksRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetWebhooks(), 2)
+	for _, webhook := range list.GetWebhooks() {
+		require.Empty(t, webhook.GetSecret())
+	}
+
+	laptopStore := service.NewWebhookLaptopStore(service.NewInMemoryLaptopStore(), dispatcher)
+	ratingStore := service.NewWebhookRatingStore(service.NewInMemoryRatingStore(), dispatcher)
+
+	laptop := sample.NewLaptop()
+	require.NoError(t, laptopStore.Save(laptop))
+	dispatcher.Wait()
+	_, err = ratingStore.Add(laptop.Id, 8)
+	require.NoError(t, err)
+	require.NoError(t, laptopStore.Delete(laptop.Id, 0))
+	dispatcher.Wait()
+
+	require.Equal(t, 3, flaky.requests)
+	require.Len(t, flaky.events, 2)
+	require.Equal(t, pb.WebhookEvent_LAPTOP_CREATED, flaky.events[0].GetType())
+	require.Equal(t, laptop.Id, flaky.events[0].GetLaptop().GetId())
+	require.Equal(t, pb.WebhookEvent_LAPTOP_RATED, flaky.events[1].GetType())
+	require.Equal(t, 8.0, flaky.events[1].GetRating().GetAverageScore())
+
+	require.Equal(t, 9, down.requests)
+	deadLetters, err := webhookClient.ListDeadLetters(context.Background(), &pb.ListDeadLettersRequest{
+		WebhookId: downWebhook.GetId(),
+	})
+	require.NoError(t, err)
+	require.Len(t, deadLetters.GetDeliveries(), 3)
+	for _, delivery := range deadLetters.GetDeliveries() {
+		require.EqualValues(t, 3, delivery.GetAttempts())
+		require.Contains(t, delivery.GetLastError(), "503")
+	}
+
+	down.setFailures(0)
+	replay, err := webhookClient.ReplayDeadLetters(context.Background(), &pb.ReplayDeadLettersRequest{
+		DeliveryIds: []string{deadLetters.GetDeliveries()[0].GetId()},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, replay.GetReplayed())
+	dispatcher.Wait()
+	require.Len(t, down.events, 1)
+	require.Equal(t, pb.WebhookEvent_LAPTOP_CREATED, down.events[0].GetType())
+
+	replay, err = webhookClient.ReplayDeadLetters(context.Background(), &pb.ReplayDeadLettersRequest{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, replay.GetReplayed())
+	dispatcher.Wait()
+	require.Len(t, down.events, 3)
+
+	deadLetters, err = webhookClient.ListDeadLetters(context.Background(), &pb.ListDeadLettersRequest{})
+	require.NoError(t, err)
+	require.Empty(t, deadLetters.GetDeliveries())
+
+	_, err = webhookClient.DeleteWebhook(context.Background(), &pb.DeleteWebhookRequest{Id: downWebhook.GetId()})
+	require.NoError(t, err)
+	_, err = webhookClient.DeleteWebhook(context.Background(), &pb.DeleteWebhookRequest{Id: downWebhook.GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func startTestWebhookServer(t *testing.T, webhookServer *service.WebhookServer) string {
+	grpcServer := grpc.NewServer()
+	pb.RegisterWebhookServiceServer(grpcServer, webhookServer)
+
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	return listener.Addr().String()
+}
+
+func newTestWebhookClient(t *testing.T, serverAddress string) pb.WebhookServiceClient {
+	conn, err := grpc.Dial(serverAddress, grpc.WithInsecure())
+	require.NoError(t, err)
+	return pb.NewWebhookServiceClient(conn)
+}
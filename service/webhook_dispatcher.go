@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/serializer"
+)
+
+// headers of every webhook request
+const (
+	// WebhookSignatureHeader holds "sha256=" and the hex HMAC-SHA256 of the body keyed with the webhook secret
+	WebhookSignatureHeader = "X-Pcbook-Signature"
+	WebhookEventHeader     = "X-Pcbook-Event"
+	WebhookDeliveryHeader  = "X-Pcbook-Delivery"
+)
+
+// maxWebhookBackoff caps the wait between two attempts of a delivery
+const maxWebhookBackoff = time.Minute
+
+// WebhookDispatcher posts events to the webhooks that want them.
+// A delivery that still fails after maxAttempts is kept as a dead letter in the
+// webhook store until it is replayed.
+type WebhookDispatcher struct {
+	webhookStore WebhookStore
+	client       *http.Client
+	maxAttempts  int
+	backoff      time.Duration
+	deliveries   sync.WaitGroup
+}
+
+// NewWebhookDispatcher returns a dispatcher that waits backoff before the second attempt
+// of a delivery and twice as long before each further attempt
+func NewWebhookDispatcher(
+	webhookStore WebhookStore,
+	client *http.Client,
+	maxAttempts int,
+	backoff time.Duration,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookStore: webhookStore,
+		client:       client,
+		maxAttempts:  maxAttempts,
+		backoff:      backoff,
+	}
+}
+
+// Publish sets the id and time of an event and delivers it in the background
+func (dispatcher *WebhookDispatcher) Publish(event *pb.WebhookEvent) {
+	event.Id = uuid.New().String()
+	event.Time = ptypes.TimestampNow()
+
+	webhooks, err := dispatcher.webhookStore.List()
+	if err != nil {
+		log.Printf("cannot list webhooks for %v event: %v", event.GetType(), err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !wantsWebhookEvent(webhook, event.GetType()) {
+			continue
+		}
+
+		dispatcher.deliver(&pb.WebhookDelivery{
+			Id:        uuid.New().String(),
+			WebhookId: webhook.GetId(),
+			Event:     event,
+		})
+	}
+}
+
+// Wait blocks until every delivery succeeded or became a dead letter
+func (dispatcher *WebhookDispatcher) Wait() {
+	dispatcher.deliveries.Wait()
+}
+
+// DeadLetters returns the failed deliveries of a webhook, or of all webhooks if webhookID is empty
+func (dispatcher *WebhookDispatcher) DeadLetters(webhookID string) ([]*pb.WebhookDelivery, error) {
+	return dispatcher.webhookStore.ListDeadLetters(webhookID)
+}
+
+// Replay delivers the dead letters with the given ids again, or all dead letters if ids is empty.
+// It returns the number of deliveries sent again.
+func (dispatcher *WebhookDispatcher) Replay(ids []string) (int, error) {
+	deliveries, err := dispatcher.webhookStore.TakeDeadLetters(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		dispatcher.deliver(delivery)
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver posts a delivery in the background until it succeeds or runs out of attempts
+func (dispatcher *WebhookDispatcher) deliver(delivery *pb.WebhookDelivery) {
+	dispatcher.deliveries.Add(1)
+	go func() {
+		defer dispatcher.deliveries.Done()
+
+		backoff := dispatcher.backoff
+		for attempt := 1; ; attempt++ {
+			webhook, err := dispatcher.webhookStore.Find(delivery.GetWebhookId())
+			if err == nil && webhook == nil {
+				log.Printf("drop delivery %s of deleted webhook %s", delivery.GetId(), delivery.GetWebhookId())
+				return
+			}
+			if err == nil {
+				err = dispatcher.post(webhook, delivery)
+			}
+
+			delivery.Attempts++
+			delivery.LastAttemptAt = ptypes.TimestampNow()
+			if err == nil {
+				return
+			}
+
+			delivery.LastError = err.Error()
+			log.Printf("attempt %d of delivery %s failed: %v", attempt, delivery.GetId(), err)
+			if attempt >= dispatcher.maxAttempts {
+				err = dispatcher.webhookStore.SaveDeadLetter(delivery)
+				if err != nil {
+					log.Printf("cannot save dead letter %s: %v", delivery.GetId(), err)
+				}
+				return
+			}
+
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > maxWebhookBackoff {
+				backoff = maxWebhookBackoff
+			}
+		}
+	}()
+}
+
+func (dispatcher *WebhookDispatcher) post(webhook *pb.Webhook, delivery *pb.WebhookDelivery) error {
+	payload, err := serializer.ProtobufToJSON(delivery.GetEvent())
+	if err != nil {
+		return fmt.Errorf("cannot serialize event %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook.GetUrl(), bytes.NewBufferString(payload))
+	if err != nil {
+		return fmt.Errorf("cannot create request %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.GetSecret(), []byte(payload)))
+	req.Header.Set(WebhookEventHeader, delivery.GetEvent().GetType().String())
+	req.Header.Set(WebhookDeliveryHeader, delivery.GetId())
+
+	res, err := dispatcher.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return nil
+}
+
+// SignWebhookPayload returns the value of the WebhookSignatureHeader of a payload
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func wantsWebhookEvent(webhook *pb.Webhook, eventType pb.WebhookEvent_Type) bool {
+	if len(webhook.GetEventTypes()) == 0 {
+		return true
+	}
+
+	for _, wanted := range webhook.GetEventTypes() {
+		if wanted == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"gitlab.techschool.pcbook/pb"
+)
+
+// WebhookLaptopStore publishes a webhook event for every write to a laptop store
+type WebhookLaptopStore struct {
+	LaptopStore
+	webhooks *WebhookDispatcher
+}
+
+func NewWebhookLaptopStore(store LaptopStore, webhooks *WebhookDispatcher) *WebhookLaptopStore {
+	return &WebhookLaptopStore{store, webhooks}
+}
+
+func (store *WebhookLaptopStore) Save(laptop *pb.Laptop) error {
+	err := store.LaptopStore.Save(laptop)
+	if err != nil {
+		return err
+	}
+
+	return store.publishCreated(laptop)
+}
+
+func (store *WebhookLaptopStore) SaveAll(laptops []*pb.Laptop) error {
+	err := store.LaptopStore.SaveAll(laptops)
+	if err != nil {
+		return err
+	}
+
+	for _, laptop := range laptops {
+		err := store.publishCreated(laptop)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *WebhookLaptopStore) publishCreated(laptop *pb.Laptop) error {
+	// read it back to publish the stored version
+	saved, err := store.LaptopStore.Find(laptop.GetId())
+	if err != nil || saved == nil {
+		return err
+	}
+
+	store.webhooks.Publish(&pb.WebhookEvent{
+		Type:    pb.WebhookEvent_LAPTOP_CREATED,
+		Payload: &pb.WebhookEvent_Laptop{Laptop: saved},
+	})
+	return nil
+}
+
+func (store *WebhookLaptopStore) Update(laptop *pb.Laptop) error {
+	err := store.LaptopStore.Update(laptop)
+	if err != nil {
+		return err
+	}
+
+	updated, err := deepCopy(laptop)
+	if err != nil {
+		return err
+	}
+
+	store.webhooks.Publish(&pb.WebhookEvent{
+		Type:    pb.WebhookEvent_LAPTOP_UPDATED,
+		Payload: &pb.WebhookEvent_Laptop{Laptop: updated},
+	})
+	return nil
+}
+
+func (store *WebhookLaptopStore) Delete(id string, version uint64) error {
+	err := store.LaptopStore.Delete(id, version)
+	if err != nil {
+		return err
+	}
+
+	store.webhooks.Publish(&pb.WebhookEvent{
+		Type:    pb.WebhookEvent_LAPTOP_DELETED,
+		Payload: &pb.WebhookEvent_Laptop{Laptop: &pb.Laptop{Id: id}},
+	})
+	return nil
+}
+
+// WebhookImageStore publishes a webhook event for every image saved to an image store
+type WebhookImageStore struct {
+	ImageStore
+	webhooks *WebhookDispatcher
+}
+
+func NewWebhookImageStore(store ImageStore, webhooks *WebhookDispatcher) *WebhookImageStore {
+	return &WebhookImageStore{store, webhooks}
+}
+
+func (store *WebhookImageStore) Create(laptopID string, imageType string, uploader string) (ImageWriter, error) {
+	writer, err := store.ImageStore.Create(laptopID, imageType, uploader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookImageWriter{writer, store.webhooks}, nil
+}
+
+// webhookImageWriter publishes a webhook event once the image is committed
+type webhookImageWriter struct {
+	ImageWriter
+	webhooks *WebhookDispatcher
+}
+
+func (writer *webhookImageWriter) Commit() (*ImageInfo, error) {
+	info, err := writer.ImageWriter.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	writer.webhooks.Publish(&pb.WebhookEvent{
+		Type: pb.WebhookEvent_IMAGE_UPLOADED,
+		Payload: &pb.WebhookEvent_Image{Image: &pb.ImageUploaded{
+			LaptopId:  info.LaptopID,
+			ImageId:   info.ID,
+			ImageType: info.Type,
+			Size:      uint32(info.Size),
+		}},
+	})
+	return info, nil
+}
+
+// WebhookRatingStore publishes a webhook event for every rating added to a rating store
+type WebhookRatingStore struct {
+	RatingStore
+	webhooks *WebhookDispatcher
+}
+
+func NewWebhookRatingStore(store RatingStore, webhooks *WebhookDispatcher) *WebhookRatingStore {
+	return &WebhookRatingStore{store, webhooks}
+}
+
+func (store *WebhookRatingStore) Add(laptopID string, score float64) (*Rating, error) {
+	rating, err := store.RatingStore.Add(laptopID, score)
+	if err != nil {
+		return nil, err
+	}
+
+	store.webhooks.Publish(&pb.WebhookEvent{
+		Type: pb.WebhookEvent_LAPTOP_RATED,
+		Payload: &pb.WebhookEvent_Rating{Rating: &pb.LaptopRated{
+			LaptopId:     laptopID,
+			Score:        score,
+			RatedCount:   rating.Count,
+			AverageScore: rating.Sum / float64(rating.Count),
+		}},
+	})
+	return rating, nil
+}
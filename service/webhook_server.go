@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WebhookServer manages webhooks and their failed deliveries
+type WebhookServer struct {
+	webhookStore WebhookStore
+	dispatcher   *WebhookDispatcher
+}
+
+func NewWebhookServer(webhookStore WebhookStore, dispatcher *WebhookDispatcher) *WebhookServer {
+	return &WebhookServer{webhookStore, dispatcher}
+}
+
+func (server *WebhookServer) CreateWebhook(
+	ctx context.Context,
+	req *pb.CreateWebhookRequest,
+) (*pb.CreateWebhookResponse, error) {
+	webhook := req.GetWebhook()
+	log.Printf("receive a create webhook request with url: %s", webhook.GetUrl())
+
+	err := validateWebhook(webhook)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid webhook: %v", err)
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate a new webhook ID %v", err)
+	}
+	webhook.Id = id.String()
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		_, err := rand.Read(secret)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot generate a webhook secret %v", err)
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	webhook.CreatedAt = ptypes.TimestampNow()
+	err = server.webhookStore.Save(webhook)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot save webhook %v", err)
+	}
+
+	return &pb.CreateWebhookResponse{Webhook: webhook}, nil
+}
+
+// ListWebhooks returns the webhooks without their secrets, which are only returned on creation
+func (server *WebhookServer) ListWebhooks(
+	ctx context.Context,
+	req *pb.ListWebhooksRequest,
+) (*pb.ListWebhooksResponse, error) {
+	webhooks, err := server.webhookStore.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list webhooks %v", err)
+	}
+
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+
+	return &pb.ListWebhooksResponse{Webhooks: webhooks}, nil
+}
+
+func (server *WebhookServer) DeleteWebhook(
+	ctx context.Context,
+	req *pb.DeleteWebhookRequest,
+) (*pb.DeleteWebhookResponse, error) {
+	log.Printf("receive a delete webhook request with id: %s", req.GetId())
+
+	err := server.webhookStore.Delete(req.GetId())
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, ErrNotFound) {
+			code = codes.NotFound
+		}
+
+		return nil, status.Errorf(code, "cannot delete webhook %v", err)
+	}
+
+	return &pb.DeleteWebhookResponse{}, nil
+}
+
+func (server *WebhookServer) ListDeadLetters(
+	ctx context.Context,
+	req *pb.ListDeadLettersRequest,
+) (*pb.ListDeadLettersResponse, error) {
+	deliveries, err := server.dispatcher.DeadLetters(req.GetWebhookId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot list dead letters %v", err)
+	}
+
+	return &pb.ListDeadLettersResponse{Deliveries: deliveries}, nil
+}
+
+func (server *WebhookServer) ReplayDeadLetters(
+	ctx context.Context,
+	req *pb.ReplayDeadLettersRequest,
+) (*pb.ReplayDeadLettersResponse, error) {
+	log.Printf("receive a replay dead letters request with ids: %v", req.GetDeliveryIds())
+
+	replayed, err := server.dispatcher.Replay(req.GetDeliveryIds())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot replay dead letters %v", err)
+	}
+
+	return &pb.ReplayDeadLettersResponse{Replayed: uint32(replayed)}, nil
+}
+
+func validateWebhook(webhook *pb.Webhook) error {
+	if webhook == nil {
+		return errors.New("webhook is required")
+	}
+
+	target, err := url.Parse(webhook.GetUrl())
+	if err != nil {
+		return err
+	}
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("url must be an absolute http or https url")
+	}
+
+	for _, eventType := range webhook.GetEventTypes() {
+		if _, ok := pb.WebhookEvent_Type_name[int32(eventType)]; !ok || eventType == pb.WebhookEvent_UNKNOWN {
+			return errors.New("unknown event type " + eventType.String())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"sort"
+	"sync"
+
+	"gitlab.techschool.pcbook/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// WebhookStore is an interface to store webhooks and their dead letters
+type WebhookStore interface {
+	Save(webhook *pb.Webhook) error
+	// Find returns nil if there is no webhook with the id
+	Find(id string) (*pb.Webhook, error)
+	// List returns all webhooks in the order they were created
+	List() ([]*pb.Webhook, error)
+	Delete(id string) error
+
+	// SaveDeadLetter keeps a delivery that ran out of attempts
+	SaveDeadLetter(delivery *pb.WebhookDelivery) error
+	// ListDeadLetters returns the dead letters of a webhook, or of all webhooks if webhookID is empty,
+	// in the order they were saved
+	ListDeadLetters(webhookID string) ([]*pb.WebhookDelivery, error)
+	// TakeDeadLetters removes and returns the dead letters with the given ids,
+	// or all dead letters if ids is empty
+	TakeDeadLetters(ids []string) ([]*pb.WebhookDelivery, error)
+}
+
+type InMemoryWebhookStore struct {
+	mutex       sync.RWMutex
+	data        map[string]*pb.Webhook
+	deadLetters []*pb.WebhookDelivery
+}
+
+func NewInMemoryWebhookStore() *InMemoryWebhookStore {
+	return &InMemoryWebhookStore{
+		data: make(map[string]*pb.Webhook),
+	}
+}
+
+func (store *InMemoryWebhookStore) Save(webhook *pb.Webhook) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.data[webhook.GetId()] != nil {
+		return ErrAlreadyExists
+	}
+
+	store.data[webhook.GetId()] = proto.Clone(webhook).(*pb.Webhook)
+	return nil
+}
+
+func (store *InMemoryWebhookStore) Find(id string) (*pb.Webhook, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	webhook := store.data[id]
+	if webhook == nil {
+		return nil, nil
+	}
+
+	return proto.Clone(webhook).(*pb.Webhook), nil
+}
+
+func (store *InMemoryWebhookStore) List() ([]*pb.Webhook, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	webhooks := make([]*pb.Webhook, 0, len(store.data))
+	for _, webhook := range store.data {
+		webhooks = append(webhooks, proto.Clone(webhook).(*pb.Webhook))
+	}
+
+	sortWebhooks(webhooks)
+	return webhooks, nil
+}
+
+func (store *InMemoryWebhookStore) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.data[id] == nil {
+		return ErrNotFound
+	}
+
+	delete(store.data, id)
+	return nil
+}
+
+func (store *InMemoryWebhookStore) SaveDeadLetter(delivery *pb.WebhookDelivery) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.deadLetters = append(store.deadLetters, proto.Clone(delivery).(*pb.WebhookDelivery))
+	return nil
+}
+
+func (store *InMemoryWebhookStore) ListDeadLetters(webhookID string) ([]*pb.WebhookDelivery, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	deliveries := []*pb.WebhookDelivery{}
+	for _, delivery := range store.deadLetters {
+		if webhookID == "" || delivery.GetWebhookId() == webhookID {
+			deliveries = append(deliveries, proto.Clone(delivery).(*pb.WebhookDelivery))
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (store *InMemoryWebhookStore) TakeDeadLetters(ids []string) ([]*pb.WebhookDelivery, error) {
+	take := make(map[string]bool)
+	for _, id := range ids {
+		take[id] = true
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var deliveries []*pb.WebhookDelivery
+	kept := store.deadLetters[:0]
+	for _, delivery := range store.deadLetters {
+		if len(ids) == 0 || take[delivery.GetId()] {
+			deliveries = append(deliveries, delivery)
+		} else {
+			kept = append(kept, delivery)
+		}
+	}
+	store.deadLetters = kept
+
+	return deliveries, nil
+}
+
+// sortWebhooks orders webhooks by creation time, then by id
+func sortWebhooks(webhooks []*pb.Webhook) {
+	sort.Slice(webhooks, func(i, j int) bool {
+		a, b := webhooks[i].GetCreatedAt(), webhooks[j].GetCreatedAt()
+		if a.GetSeconds() != b.GetSeconds() {
+			return a.GetSeconds() < b.GetSeconds()
+		}
+		if a.GetNanos() != b.GetNanos() {
+			return a.GetNanos() < b.GetNanos()
+		}
+		return webhooks[i].GetId() < webhooks[j].GetId()
+	})
+}
@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gitlab.techschool.pcbook/pb"
+	"gitlab.techschool.pcbook/service"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInMemoryWebhookStore(t *testing.T) {
+	t.Parallel()
+	testWebhookStore(t, service.NewInMemoryWebhookStore())
+}
+
+func TestBoltWebhookStore(t *testing.T) {
+	t.Parallel()
+	laptopStore, err := service.NewBoltLaptopStore(newTestDBPath(t))
+	require.NoError(t, err)
+	defer laptopStore.Close()
+
+	store, err := service.NewBoltWebhookStore(laptopStore.DB())
+	require.NoError(t, err)
+	testWebhookStore(t, store)
+}
+
+func TestSQLWebhookStore(t *testing.T) {
+	t.Parallel()
+	db, err := service.OpenSQLDatabase(newTestDBPath(t))
+	require.NoError(t, err)
+	defer db.Close()
+
+	testWebhookStore(t, service.NewSQLWebhookStore(db))
+}
+
+func testWebhookStore(t *testing.T, store service.WebhookStore) {
+	first := &pb.Webhook{
+		Id:         uuid.New().String(),
+		Url:        "https://example.com/first",
+		EventTypes: []pb.WebhookEvent_Type{pb.WebhookEvent_LAPTOP_CREATED},
+		Secret:     "first secret",
+		CreatedAt:  ptypes.TimestampNow(),
+	}
+	second := &pb.Webhook{
+		Id:        uuid.New().String(),
+		Url:       "https://example.com/second",
+		CreatedAt: ptypes.TimestampNow(),
+	}
+
+	require.NoError(t, store.Save(first))
+	require.NoError(t, store.Save(second))
+	require.ErrorIs(t, store.Save(first), service.ErrAlreadyExists)
+
+	found, err := store.Find(first.Id)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(first, found))
+
+	found, err = store.Find(uuid.New().String())
+	require.NoError(t, err)
+	require.Nil(t, found)
+
+	webhooks, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	require.Equal(t, first.Id, webhooks[0].Id)
+	require.Equal(t, second.Id, webhooks[1].Id)
+
+	require.NoError(t, store.Delete(second.Id))
+	require.ErrorIs(t, store.Delete(second.Id), service.ErrNotFound)
+
+	deadLetters, err := store.ListDeadLetters("")
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+
+	var ids []string
+	for _, webhookID := range []string{first.Id, second.Id, first.Id} {
+		delivery := &pb.WebhookDelivery{
+			Id:        uuid.New().String(),
+			WebhookId: webhookID,
+			Event:     &pb.WebhookEvent{Type: pb.WebhookEvent_LAPTOP_CREATED},
+			Attempts:  3,
+			LastError: "unexpected status 503 Service Unavailable",
+		}
+		require.NoError(t, store.SaveDeadLetter(delivery))
+		ids = append(ids, delivery.Id)
+	}
+
+	deadLetters, err = store.ListDeadLetters(first.Id)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+	require.Equal(t, ids[0], deadLetters[0].Id)
+	require.Equal(t, ids[2], deadLetters[1].Id)
+	require.EqualValues(t, 3, deadLetters[0].Attempts)
+
+	taken, err := store.TakeDeadLetters([]string{ids[2], uuid.New().String()})
+	require.NoError(t, err)
+	require.Len(t, taken, 1)
+	require.Equal(t, ids[2], taken[0].Id)
+
+	taken, err = store.TakeDeadLetters(nil)
+	require.NoError(t, err)
+	require.Len(t, taken, 2)
+	require.Equal(t, ids[0], taken[0].Id)
+	require.Equal(t, ids[1], taken[1].Id)
+
+	deadLetters, err = store.ListDeadLetters("")
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+}